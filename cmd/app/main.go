@@ -10,11 +10,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Krokozabra213/effective_mobile/internal/auth"
 	"github.com/Krokozabra213/effective_mobile/internal/business"
 	"github.com/Krokozabra213/effective_mobile/internal/config"
 	handler "github.com/Krokozabra213/effective_mobile/internal/delivery/http"
+	"github.com/Krokozabra213/effective_mobile/internal/metrics"
 	"github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
 	httpserver "github.com/Krokozabra213/effective_mobile/internal/server/http"
+	"github.com/Krokozabra213/effective_mobile/internal/worker"
 	"github.com/Krokozabra213/effective_mobile/pkg/logger"
 	pgxclient "github.com/Krokozabra213/effective_mobile/pkg/pgx-client"
 )
@@ -31,6 +34,16 @@ const (
 
 // @host      localhost:8080
 // @BasePath  /
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Токен доступа в формате "Bearer <token>"
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Ключ API в формате "ApiKey <key>"
 func main() {
 	if err := run(); err != nil {
 		slog.Error("application failed", "error", err)
@@ -53,6 +66,9 @@ func run() error {
 	// Database
 	pgxConf := pgxclient.NewPGXConfig(cfg.PG.Host, cfg.PG.Port, cfg.PG.User, cfg.PG.Password, cfg.PG.DBName, cfg.PG.SSLMode,
 		cfg.PG.ConnectTimeout, cfg.PG.MaxConnLifeTime, cfg.PG.MaxConnIdleTime, cfg.PG.MaxConns, cfg.PG.MinConns)
+	// Организация запроса ограничивает строки политиками RLS, которые действуют на роль PG_ROLE
+	pgxConf.PrepareConn = postgres.PrepareConn
+	pgxConf.Role = cfg.PG.Role
 
 	dbClient, err := pgxclient.New(context.Background(), pgxConf)
 	if err != nil {
@@ -61,28 +77,76 @@ func run() error {
 	defer dbClient.Close()
 	log.Info("connected to postgres")
 
+	// Metrics
+	appMetrics := metrics.New()
+	appMetrics.MustRegister(metrics.NewPoolCollector(dbClient))
+
 	// Dependencies
 	repo := postgres.NewRepository(dbClient)
-	biz := business.New(log, repo)
+	biz := business.New(log, repo, appMetrics)
+	appMetrics.MustRegister(metrics.NewSubscriptionCollector(biz))
+
+	serviceAccounts, err := auth.ServiceAccounts(cfg.Auth.ServiceAccounts, cfg.Auth.ServiceAccountRoles,
+		cfg.Auth.ServiceAccountOrganizations)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := auth.NewTokenManager(cfg.App.AppSecretKey, cfg.Auth.TokenTTL, serviceAccounts)
+	if err != nil {
+		return err
+	}
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.Retention.DeletedSubscriptionsDays > 0 && cfg.Retention.PurgeInterval > 0 {
+		purger := worker.NewPurger(log, biz, cfg.Retention.DeletedSubscriptions(), cfg.Retention.PurgeInterval)
+		go purger.Run(jobsCtx)
+	}
+
+	if cfg.Idempotency.SweepInterval > 0 {
+		sweeper := worker.NewIdempotencySweeper(log, biz, cfg.Idempotency.SweepInterval)
+		go sweeper.Run(jobsCtx)
+	}
 
 	// Router
 	mux := http.NewServeMux()
 
 	// Handler
-	handler.New(mux, biz)
+	handler.New(mux, biz, tokens, handler.Config{
+		IdempotencyTTL:         cfg.Idempotency.TTL,
+		MaxIdempotentBodyBytes: cfg.Idempotency.MaxBodyBytes,
+	})
 
 	// Server
-	srv := httpserver.NewServer(cfg, mux)
+	srv := httpserver.NewServer(cfg, handler.WithRequestMeta(handler.WithMetrics(mux, appMetrics)))
+
+	// Admin server serves metrics apart from the API
+	var adminSrv *httpserver.Server
+	if cfg.HTTP.AdminPort != "" {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("GET /metrics", appMetrics.Handler())
+		adminSrv = httpserver.NewAdminServer(cfg, adminMux)
+	}
 
-	// Start server in goroutine
-	errCh := make(chan error, 1)
+	// Start servers in goroutines
+	errCh := make(chan error, 2)
 	go func() {
 		log.Info("server started", "address", srv.Addr())
 		if err := srv.Run(); !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
-		close(errCh)
 	}()
+	if adminSrv != nil {
+		go func() {
+			log.Info("admin server started", "address", adminSrv.Addr())
+			if err := adminSrv.Run(); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+	}
 
 	// Wait for shutdown signal or server error
 	quit := make(chan os.Signal, 1)
@@ -97,6 +161,11 @@ func run() error {
 	}
 
 	// Graceful shutdown
+	if adminSrv != nil {
+		if err := adminSrv.ShutDown(shutdownTimeout); err != nil {
+			log.Error("admin server shutdown error", "error", err)
+		}
+	}
 	if err := srv.ShutDown(shutdownTimeout); err != nil {
 		log.Error("server shutdown error", "error", err)
 		return err
@@ -0,0 +1,200 @@
+// Package auth issues and verifies JWT access tokens.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// minSecretLen минимальная длина секрета подписи HS256 (256 бит)
+const minSecretLen = 32
+
+var (
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrWeakSecret          = errors.New("secret must be at least 32 bytes")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrInvalidOrganization = errors.New("invalid organization")
+)
+
+// defaultServiceRole роль сервисного аккаунта, для которого она не задана: права
+// шире минимальных выдаются только явно
+const defaultServiceRole = domain.RoleUser
+
+// ServiceAccount учётные данные, роль и организация сервисного аккаунта
+type ServiceAccount struct {
+	Secret         string
+	Role           domain.Role
+	OrganizationID uuid.UUID
+}
+
+// ServiceAccounts собирает сервисные аккаунты из секретов, ролей и организаций по client_id.
+// Аккаунт без роли получает роль user, без организации — организацию по умолчанию.
+func ServiceAccounts(secrets, roles, organizations map[string]string) (map[string]ServiceAccount, error) {
+	accounts := make(map[string]ServiceAccount, len(secrets))
+	for clientID, secret := range secrets {
+		accounts[clientID] = ServiceAccount{Secret: secret, Role: defaultServiceRole, OrganizationID: domain.DefaultOrganizationID}
+	}
+
+	for clientID, role := range roles {
+		account, ok := accounts[clientID]
+		if !ok {
+			return nil, fmt.Errorf("role for unknown service account %q", clientID)
+		}
+		if !domain.Role(role).Valid() {
+			return nil, fmt.Errorf("%w %q for service account %q", ErrInvalidRole, role, clientID)
+		}
+		account.Role = domain.Role(role)
+		accounts[clientID] = account
+	}
+
+	for clientID, organization := range organizations {
+		account, ok := accounts[clientID]
+		if !ok {
+			return nil, fmt.Errorf("organization for unknown service account %q", clientID)
+		}
+		organizationID, err := uuid.Parse(organization)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q for service account %q", ErrInvalidOrganization, organization, clientID)
+		}
+		account.OrganizationID = organizationID
+		accounts[clientID] = account
+	}
+
+	return accounts, nil
+}
+
+// Token выданный токен доступа
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// claims содержимое токена: sub — субъект, kind — пользователь или сервисный аккаунт,
+// role — роль субъекта, org — UUID его организации
+type claims struct {
+	Kind         domain.PrincipalKind `json:"kind,omitempty"`
+	Role         domain.Role          `json:"role,omitempty"`
+	Organization string               `json:"org,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager подписывает и проверяет токены HS256 и выдаёт токены сервисным аккаунтам
+type TokenManager struct {
+	secret          []byte
+	ttl             time.Duration
+	serviceAccounts map[string]ServiceAccount
+	parser          *jwt.Parser
+}
+
+// NewTokenManager создаёт TokenManager. serviceAccounts — сервисные аккаунты по client_id.
+func NewTokenManager(secret string, ttl time.Duration, serviceAccounts map[string]ServiceAccount) (*TokenManager, error) {
+	if len(secret) < minSecretLen {
+		return nil, ErrWeakSecret
+	}
+
+	return &TokenManager{
+		secret:          []byte(secret),
+		ttl:             ttl,
+		serviceAccounts: serviceAccounts,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}, nil
+}
+
+// Issue выдаёт токен для субъекта
+func (m *TokenManager) Issue(principal domain.Principal) (Token, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+
+	var organization string
+	if principal.OrganizationID != uuid.Nil {
+		organization = principal.OrganizationID.String()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Kind:         principal.Kind,
+		Role:         principal.Role,
+		Organization: organization,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   principal.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return Token{}, err
+	}
+
+	return Token{AccessToken: signed, ExpiresAt: expiresAt}, nil
+}
+
+// IssueServiceToken выдаёт токен сервисному аккаунту по client_id и секрету
+func (m *TokenManager) IssueServiceToken(clientID, clientSecret string) (Token, error) {
+	account, ok := m.serviceAccounts[clientID]
+	if !ok || clientSecret == "" || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(account.Secret)) != 1 {
+		return Token{}, ErrInvalidCredentials
+	}
+
+	return m.Issue(domain.Principal{
+		Subject:        clientID,
+		Kind:           domain.PrincipalService,
+		Role:           account.Role,
+		OrganizationID: account.OrganizationID,
+	})
+}
+
+// Authenticate проверяет подпись и срок действия токена и возвращает его субъекта с правами роли.
+// Токен без kind считается пользовательским, без role — с ролью user, без org — выданным
+// в организации по умолчанию. Субъект с ролью user должен быть UUID пользователя подписок.
+func (m *TokenManager) Authenticate(token string) (domain.Principal, error) {
+	var c claims
+	_, err := m.parser.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return m.secret, nil
+	})
+	if err != nil {
+		return domain.Principal{}, ErrInvalidToken
+	}
+
+	if c.Subject == "" {
+		return domain.Principal{}, ErrInvalidToken
+	}
+
+	principal := domain.Principal{Subject: c.Subject, Kind: c.Kind, Role: c.Role}
+	if principal.Kind == "" {
+		principal.Kind = domain.PrincipalUser
+	}
+	if principal.Role == "" {
+		principal.Role = domain.RoleUser
+	}
+	if !principal.Kind.Valid() || !principal.Role.Valid() {
+		return domain.Principal{}, ErrInvalidToken
+	}
+
+	principal.OrganizationID = domain.DefaultOrganizationID
+	if c.Organization != "" {
+		if principal.OrganizationID, err = uuid.Parse(c.Organization); err != nil {
+			return domain.Principal{}, ErrInvalidToken
+		}
+	}
+
+	if userID, err := uuid.Parse(c.Subject); err == nil {
+		principal.UserID = &userID
+	} else if principal.Role == domain.RoleUser {
+		return domain.Principal{}, ErrInvalidToken
+	}
+	principal.Scopes = principal.Role.Scopes()
+
+	return principal, nil
+}
@@ -0,0 +1,190 @@
+package business
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+)
+
+const (
+	// apiKeyPrefix отличает ключи API сервиса от других секретов
+	apiKeyPrefix = "em_"
+	// apiKeyBytes длина случайной части ключа
+	apiKeyBytes = 32
+	// apiKeyVisibleLen длина начала ключа, которое хранится и показывается в списке ключей
+	apiKeyVisibleLen = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval время последнего использования ключа обновляется не чаще
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey выпускает ключ API и возвращает его вместе с самим ключом, который
+// больше нигде не хранится. Ключу нельзя дать права, которых нет у выпускающего.
+func (b *Business) CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, string, error) {
+	const op = "business.CreateAPIKey"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("name", input.Name))
+	log.Info("process started")
+
+	principal, err := authorize(ctx, domain.ScopeAPIKeysManage)
+	if err != nil {
+		log.Warn("access denied")
+		return nil, "", err
+	}
+
+	input.Scopes = slices.Compact(slices.Sorted(slices.Values(input.Scopes)))
+	if err := validateAPIKeyInput(input); err != nil {
+		log.Warn("invalid api key input", slog.String("error", err.Error()))
+		return nil, "", err
+	}
+	for _, scope := range input.Scopes {
+		if !principal.HasScope(scope) {
+			log.Warn("access denied", slog.String("scope", string(scope)))
+			return nil, "", ErrForbidden
+		}
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		log.Error("failed to generate api key", slog.String("error", err.Error()))
+		return nil, "", ErrInternal
+	}
+	input.Prefix = key[:apiKeyVisibleLen]
+	input.KeyHash = hashAPIKey(key)
+	input.CreatedBy = principal.Subject
+
+	created, err := b.repo.CreateAPIKey(ctx, input)
+	if err != nil {
+		log.Error("failed to create api key", slog.String("error", err.Error()))
+		return nil, "", b.mapAPIKeyError(err)
+	}
+
+	log.Info("api key created", slog.Int64("id", created.ID))
+	return created, key, nil
+}
+
+// validateAPIKeyInput проверяет данные нового ключа
+func validateAPIKeyInput(input domain.CreateAPIKeyInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAPIKeyInput)
+	}
+	if len(input.Scopes) == 0 {
+		return fmt.Errorf("%w: scopes are required", ErrInvalidAPIKeyInput)
+	}
+	for _, scope := range input.Scopes {
+		if !scope.Valid() {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyInput, scope)
+		}
+	}
+
+	// Без users:all ключ видит только подписки своего пользователя
+	needsUser := slices.Contains(input.Scopes, domain.ScopeSubscriptionsRead) ||
+		slices.Contains(input.Scopes, domain.ScopeSubscriptionsWrite)
+	if needsUser && !slices.Contains(input.Scopes, domain.ScopeAllUsers) && input.UserID == nil {
+		return fmt.Errorf("%w: user_id is required for subscription scopes without %s", ErrInvalidAPIKeyInput, domain.ScopeAllUsers)
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at should be in the future", ErrInvalidAPIKeyInput)
+	}
+	return nil
+}
+
+// ListAPIKeys возвращает ключи API, новые первыми, включая отозванные
+func (b *Business) ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error) {
+	const op = "business.ListAPIKeys"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int("limit", int(params.Limit)), slog.Int("offset", int(params.Offset)))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeAPIKeysManage); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	keys, err := b.repo.ListAPIKeys(ctx, params)
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		return nil, b.mapAPIKeyError(err)
+	}
+
+	log.Info("success", slog.Int("count", len(keys)))
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ API
+func (b *Business) RevokeAPIKey(ctx context.Context, id int64) error {
+	const op = "business.RevokeAPIKey"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("api_key_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeAPIKeysManage); err != nil {
+		log.Warn("access denied")
+		return err
+	}
+
+	if _, err := b.repo.RevokeAPIKey(ctx, id); err != nil {
+		log.Error("failed to revoke api key", slog.String("error", err.Error()))
+		return b.mapAPIKeyError(err)
+	}
+
+	log.Info("success")
+	return nil
+}
+
+// AuthenticateAPIKey находит действующий ключ API и возвращает субъекта с правами ключа
+func (b *Business) AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error) {
+	const op = "business.AuthenticateAPIKey"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op))
+
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return domain.Principal{}, ErrInvalidAPIKey
+	}
+
+	found, err := b.repo.GetActiveAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Warn("unknown, revoked or expired api key")
+			return domain.Principal{}, ErrInvalidAPIKey
+		}
+		log.Error("failed to get api key", slog.String("error", err.Error()))
+		return domain.Principal{}, ErrInternal
+	}
+
+	// Ошибка учёта использования не мешает запросу
+	now := time.Now()
+	if found.LastUsedAt == nil || now.Sub(*found.LastUsedAt) >= apiKeyTouchInterval {
+		if err := b.repo.TouchAPIKey(domain.WithOrganization(ctx, found.OrganizationID), found.ID, now); err != nil {
+			log.Error("failed to touch api key", slog.Int64("api_key_id", found.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	return found.Principal(), nil
+}
+
+// generateAPIKey создаёт ключ из префикса и случайной части
+func generateAPIKey() (string, error) {
+	random := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashAPIKey хэш ключа для хранения; у случайного ключа достаточно энтропии для SHA-256 без соли
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
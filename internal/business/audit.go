@@ -0,0 +1,83 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// ListSubscriptionHistory возвращает журнал изменений подписки, новые записи первыми.
+// История доступна и после удаления подписки.
+func (b *Business) ListSubscriptionHistory(ctx context.Context, id int64, params domain.ListParams) ([]domain.SubscriptionEvent, error) {
+	const op = "business.ListSubscriptionHistory"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
+	log.Info("process started")
+
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsRead, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	events, err := b.repo.ListSubscriptionEvents(ctx, domain.AuditFilter{SubscriptionID: &id}, params)
+	if err != nil {
+		log.Error("failed to list subscription events", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	// Пустая история отличается от несуществующей подписки
+	if len(events) == 0 && params.Offset == 0 {
+		if _, err := b.repo.GetSubscriptionByID(ctx, id); err != nil {
+			log.Warn("subscription not found", slog.String("error", err.Error()))
+			return nil, b.mapError(err)
+		}
+	}
+
+	log.Info("success", slog.Int("count", len(events)))
+	return events, nil
+}
+
+// ListAuditEvents возвращает журнал изменений всех подписок, подходящих под фильтр
+func (b *Business) ListAuditEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error) {
+	const op = "business.ListAuditEvents"
+	defer b.observe(op, time.Now())
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("limit", int(params.Limit)),
+		slog.Int("offset", int(params.Offset)),
+	)
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeAuditRead); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	if err := validateAuditFilter(filter); err != nil {
+		log.Warn("invalid audit filter", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	events, err := b.repo.ListSubscriptionEvents(ctx, filter, params)
+	if err != nil {
+		log.Error("failed to list subscription events", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Info("success", slog.Int("count", len(events)))
+	return events, nil
+}
+
+// validateAuditFilter проверяет фильтр журнала изменений
+func validateAuditFilter(filter domain.AuditFilter) error {
+	switch {
+	case filter.Action != nil && !filter.Action.Valid():
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidFilter, *filter.Action)
+	case filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To):
+		return fmt.Errorf("%w: from should be before to", ErrInvalidFilter)
+	}
+	return nil
+}
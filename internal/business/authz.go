@@ -0,0 +1,66 @@
+package business
+
+import (
+	"context"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
+)
+
+// authorize возвращает субъекта запроса, если у него есть право scope
+func authorize(ctx context.Context, scope domain.Scope) (domain.Principal, error) {
+	principal, ok := domain.PrincipalFrom(ctx)
+	if !ok || !principal.HasScope(scope) {
+		return principal, ErrForbidden
+	}
+	return principal, nil
+}
+
+// authorizeSubscription проверяет право scope и доступ к подписке id, в том числе удалённой.
+// Чужая подписка неотличима от несуществующей.
+func (b *Business) authorizeSubscription(ctx context.Context, scope domain.Scope, id int64) error {
+	principal, err := authorize(ctx, scope)
+	if err != nil {
+		return err
+	}
+	if principal.HasScope(domain.ScopeAllUsers) {
+		return nil
+	}
+
+	owner, err := b.repo.GetSubscriptionOwner(ctx, id)
+	if err != nil {
+		return b.mapError(err)
+	}
+	if !principal.Owns(owner) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// authorizeUser проверяет право scope и доступ к подпискам пользователя userID
+func authorizeUser(ctx context.Context, scope domain.Scope, userID uuid.UUID) error {
+	principal, err := authorize(ctx, scope)
+	if err != nil {
+		return err
+	}
+	if !principal.Owns(userID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// scopeUserID ограничивает фильтр по пользователю подписками субъекта, если ему
+// недоступны подписки всех пользователей. Фильтр по чужому пользователю запрещён.
+func scopeUserID(ctx context.Context, scope domain.Scope, userID *uuid.UUID) (*uuid.UUID, error) {
+	principal, err := authorize(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if principal.HasScope(domain.ScopeAllUsers) {
+		return userID, nil
+	}
+	if principal.UserID == nil || (userID != nil && *userID != *principal.UserID) {
+		return nil, ErrForbidden
+	}
+	return principal.UserID, nil
+}
@@ -3,6 +3,7 @@ package business
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	"github.com/google/uuid"
@@ -10,35 +11,147 @@ import (
 
 type BusinessInterface interface {
 	CreateSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) (*domain.Subscription, error)
+	CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error)
-	ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error)
-	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error)
+	ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) (*domain.SubscriptionPage, error)
+	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) (*domain.SubscriptionPage, error)
+	ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error
 	UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error)
-	DeleteSubscription(ctx context.Context, id int64) error
+	PauseSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error
+	RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	PurgeDeletedSubscriptions(ctx context.Context, retention time.Duration) (int64, error)
+	CountSubscriptionsByStatus(ctx context.Context) (map[domain.SubscriptionStatus]int64, error)
 	CalculateTotalCost(ctx context.Context, filter domain.CostFilter) (domain.TotalCost, error)
+	CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error)
+	CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error)
+	CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error)
+	BuildCostReport(ctx context.Context, filter domain.CostFilter) (*domain.CostReport, error)
+	ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error)
+	ListSubscriptionHistory(ctx context.Context, id int64, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+	ListAuditEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+	BeginIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*domain.IdempotentResponse, error)
+	CompleteIdempotentRequest(ctx context.Context, key string, response domain.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error)
+
+	CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error)
+	GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id int64) error
+
+	CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error)
+	GetServiceByID(ctx context.Context, id int64) (*domain.Service, error)
+	ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error)
+	UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error)
+	DeleteService(ctx context.Context, id int64) error
 }
 
 type SubscriptionProvider interface {
 	CreateSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) (*domain.Subscription, error)
+	CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error)
-	ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error)
+	GetSubscriptionOwner(ctx context.Context, id int64) (uuid.UUID, error)
+	ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) ([]domain.Subscription, error)
 	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error)
+	CountSubscriptions(ctx context.Context, filter domain.ListFilter) (int64, error)
+	ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error
 	UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error)
-	DeleteSubscription(ctx context.Context, id int64) error
+	PauseSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error
+	RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	PurgeDeletedSubscriptions(ctx context.Context, deletedBefore time.Time) (int64, error)
+	CountSubscriptionsByStatus(ctx context.Context, now time.Time) (map[domain.SubscriptionStatus]int64, error)
 	CalculateTotalCost(ctx context.Context, filter domain.CostFilter) (domain.TotalCost, error)
+	CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error)
+	CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error)
+	CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error)
+	ListSubscriptionCosts(ctx context.Context, filter domain.CostFilter) ([]domain.SubscriptionCost, error)
+	ListMissingExchangeRates(ctx context.Context, filter domain.CostFilter) ([]domain.MissingExchangeRate, error)
+	ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error)
+}
+
+type ExchangeRateProvider interface {
+	CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error)
+	GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id int64) error
+}
+
+type ServiceProvider interface {
+	CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error)
+	GetServiceByID(ctx context.Context, id int64) (*domain.Service, error)
+	GetServiceByName(ctx context.Context, name string) (*domain.Service, error)
+	ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error)
+	UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error)
+	DeleteService(ctx context.Context, id int64) error
+}
+
+type AuditProvider interface {
+	ListSubscriptionEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+}
+
+type IdempotencyProvider interface {
+	ReserveIdempotencyKey(ctx context.Context, key, requestHash string, expiresAt time.Time) (*domain.IdempotencyKey, bool, error)
+	SaveIdempotentResponse(ctx context.Context, key string, response domain.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type APIKeyProvider interface {
+	CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+// Repository объединяет все хранилища, нужные бизнес-логике
+type Repository interface {
+	SubscriptionProvider
+	ExchangeRateProvider
+	ServiceProvider
+	AuditProvider
+	IdempotencyProvider
+	APIKeyProvider
+}
+
+// OperationMetrics учитывает длительность бизнес-операций
+type OperationMetrics interface {
+	ObserveOperation(op string, duration time.Duration)
 }
 
 // Business contains the core business logic and dependencies.
 type Business struct {
-	log  *slog.Logger
-	repo SubscriptionProvider
+	log     *slog.Logger
+	repo    Repository
+	metrics OperationMetrics
 }
 
 // New creates a new Business instance with the provided dependencies.
-func New(log *slog.Logger, repo SubscriptionProvider) *Business {
+// metrics may be nil if operation durations are not collected.
+func New(log *slog.Logger, repo Repository, metrics OperationMetrics) *Business {
 	return &Business{
-		log:  log,
-		repo: repo,
+		log:     log,
+		repo:    repo,
+		metrics: metrics,
+	}
+}
+
+// observe учитывает длительность операции op, начатой в start; вызывается через defer
+func (b *Business) observe(op string, start time.Time) {
+	if b.metrics != nil {
+		b.metrics.ObserveOperation(op, time.Since(start))
 	}
 }
 
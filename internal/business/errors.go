@@ -7,13 +7,63 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("subscription not found")
-	ErrInternal = errors.New("internal error")
+	ErrNotFound                 = errors.New("subscription not found")
+	ErrExchangeRateNotFound     = errors.New("exchange rate not found")
+	ErrExchangeRateExists       = errors.New("exchange rate for this currency and month already exists")
+	ErrMissingExchangeRate      = errors.New("missing exchange rate")
+	ErrServiceNotFound          = errors.New("service not found")
+	ErrServiceExists            = errors.New("service with this name or alias already exists")
+	ErrServiceInUse             = errors.New("service has subscriptions")
+	ErrUnknownService           = errors.New("unknown service: add it to the catalog first")
+	ErrPriceRequired            = errors.New("price is required: service has no default price")
+	ErrInvalidTransition        = errors.New("invalid status transition")
+	ErrInvalidFilter            = errors.New("invalid filter")
+	ErrNotDeleted               = errors.New("subscription is not deleted")
+	ErrVersionMismatch          = errors.New("subscription version mismatch")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrForbidden                = errors.New("access denied")
+	ErrAPIKeyNotFound           = errors.New("api key not found")
+	ErrInvalidAPIKeyInput       = errors.New("invalid api key")
+	ErrInvalidAPIKey            = errors.New("invalid or expired api key")
+	ErrInternal                 = errors.New("internal error")
 )
 
 func (b *Business) mapError(err error) error {
-	if errors.Is(err, repository.ErrNotFound) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
 		return ErrNotFound
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return ErrVersionMismatch
+	}
+	return ErrInternal
+}
+
+func (b *Business) mapExchangeRateError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrExchangeRateNotFound
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return ErrExchangeRateExists
+	}
+	return ErrInternal
+}
+
+func (b *Business) mapServiceError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrServiceNotFound
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return ErrServiceExists
+	case errors.Is(err, repository.ErrInUse):
+		return ErrServiceInUse
+	}
+	return ErrInternal
+}
+
+func (b *Business) mapAPIKeyError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrAPIKeyNotFound
 	}
 	return ErrInternal
 }
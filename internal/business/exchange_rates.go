@@ -0,0 +1,122 @@
+package business
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// CreateExchangeRate создаёт курс валюты на месяц
+func (b *Business) CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error) {
+	const op = "business.CreateExchangeRate"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("currency", input.Currency), slog.Time("month", input.Month))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	rate, err := b.repo.CreateExchangeRate(ctx, input)
+	if err != nil {
+		log.Error("failed to create exchange rate", slog.String("error", err.Error()))
+		return nil, b.mapExchangeRateError(err)
+	}
+
+	log.Info("exchange rate created", slog.Int64("id", rate.ID))
+	return rate, nil
+}
+
+// GetExchangeRateByID получает курс по ID
+func (b *Business) GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error) {
+	const op = "business.GetExchangeRateByID"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("exchange_rate_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeSubscriptionsRead); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	rate, err := b.repo.GetExchangeRateByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get exchange rate", slog.String("error", err.Error()))
+		return nil, b.mapExchangeRateError(err)
+	}
+
+	log.Info("success")
+	return rate, nil
+}
+
+// ListExchangeRates возвращает список курсов
+func (b *Business) ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error) {
+	const op = "business.ListExchangeRates"
+	defer b.observe(op, time.Now())
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("limit", int(params.Limit)),
+		slog.Int("offset", int(params.Offset)),
+	)
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeSubscriptionsRead); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	rates, err := b.repo.ListExchangeRates(ctx, filter, params)
+	if err != nil {
+		log.Error("failed to list exchange rates", slog.String("error", err.Error()))
+		return nil, b.mapExchangeRateError(err)
+	}
+
+	log.Info("success", slog.Int("count", len(rates)))
+	return rates, nil
+}
+
+// UpdateExchangeRate обновляет значение курса
+func (b *Business) UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error) {
+	const op = "business.UpdateExchangeRate"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("exchange_rate_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	result, err := b.repo.UpdateExchangeRate(ctx, id, rate)
+	if err != nil {
+		log.Error("failed to update exchange rate", slog.String("error", err.Error()))
+		return nil, b.mapExchangeRateError(err)
+	}
+
+	log.Info("success")
+	return result, nil
+}
+
+// DeleteExchangeRate удаляет курс
+func (b *Business) DeleteExchangeRate(ctx context.Context, id int64) error {
+	const op = "business.DeleteExchangeRate"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("exchange_rate_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return err
+	}
+
+	if err := b.repo.DeleteExchangeRate(ctx, id); err != nil {
+		log.Error("failed to delete exchange rate", slog.String("error", err.Error()))
+		return b.mapExchangeRateError(err)
+	}
+
+	log.Info("success")
+	return nil
+}
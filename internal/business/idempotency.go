@@ -0,0 +1,91 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+)
+
+// BeginIdempotentRequest занимает ключ идемпотентности на ttl. Если ключ свободен, возвращает nil:
+// запрос выполняется, а его ответ сохраняется CompleteIdempotentRequest. Для повтора уже
+// выполненного запроса возвращает сохранённый ответ. Пустой requestHash означает, что тело
+// запроса ещё не прочитано: повтор с ответом сверяет с ним вызывающий код.
+func (b *Business) BeginIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*domain.IdempotentResponse, error) {
+	const op = "business.BeginIdempotentRequest"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("idempotency_key", key))
+
+	record, reserved, err := b.repo.ReserveIdempotencyKey(ctx, key, requestHash, time.Now().Add(ttl))
+	if errors.Is(err, repository.ErrNotFound) {
+		// Ключ освобождён между попыткой занять его и чтением: запрос можно повторить
+		log.Warn("idempotency key released concurrently")
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		log.Error("failed to reserve idempotency key", slog.String("error", err.Error()))
+		return nil, ErrInternal
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	switch {
+	case requestHash != "" && record.RequestHash != requestHash:
+		log.Warn("idempotency key reused with a different request")
+		return nil, ErrIdempotencyKeyReused
+	case record.Response == nil:
+		log.Warn("idempotent request is in progress")
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	log.Info("replaying idempotent response", slog.Int("status", record.Response.StatusCode))
+	return record.Response, nil
+}
+
+// CompleteIdempotentRequest сохраняет ответ на запрос с ключом идемпотентности
+func (b *Business) CompleteIdempotentRequest(ctx context.Context, key string, response domain.IdempotentResponse) error {
+	const op = "business.CompleteIdempotentRequest"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("idempotency_key", key))
+
+	if err := b.repo.SaveIdempotentResponse(ctx, key, response); err != nil {
+		log.Error("failed to save idempotent response", slog.String("error", err.Error()))
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// ReleaseIdempotentRequest освобождает ключ запроса, ответ на который не сохраняется
+func (b *Business) ReleaseIdempotentRequest(ctx context.Context, key string) error {
+	const op = "business.ReleaseIdempotentRequest"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("idempotency_key", key))
+
+	if err := b.repo.ReleaseIdempotencyKey(ctx, key); err != nil {
+		log.Error("failed to release idempotency key", slog.String("error", err.Error()))
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys удаляет просроченные ключи идемпотентности
+func (b *Business) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	const op = "business.PurgeExpiredIdempotencyKeys"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op))
+
+	purged, err := b.repo.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to purge idempotency keys", slog.String("error", err.Error()))
+		return 0, ErrInternal
+	}
+
+	log.Info("success", slog.Int64("purged", purged))
+	return purged, nil
+}
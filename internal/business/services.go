@@ -0,0 +1,172 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+)
+
+// CreateService создаёт сервис каталога
+func (b *Business) CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error) {
+	const op = "business.CreateService"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.String("name", input.Name))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Aliases = trimNames(input.Aliases)
+
+	service, err := b.repo.CreateService(ctx, input)
+	if err != nil {
+		log.Error("failed to create service", slog.String("error", err.Error()))
+		return nil, b.mapServiceError(err)
+	}
+
+	log.Info("service created", slog.Int64("id", service.ID))
+	return service, nil
+}
+
+// GetServiceByID получает сервис по ID
+func (b *Business) GetServiceByID(ctx context.Context, id int64) (*domain.Service, error) {
+	const op = "business.GetServiceByID"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("service_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeSubscriptionsRead); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	service, err := b.repo.GetServiceByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get service", slog.String("error", err.Error()))
+		return nil, b.mapServiceError(err)
+	}
+
+	log.Info("success")
+	return service, nil
+}
+
+// ListServices возвращает список сервисов каталога
+func (b *Business) ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error) {
+	const op = "business.ListServices"
+	defer b.observe(op, time.Now())
+	log := b.log.With(
+		slog.String("op", op),
+		slog.Int("limit", int(params.Limit)),
+		slog.Int("offset", int(params.Offset)),
+	)
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeSubscriptionsRead); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	services, err := b.repo.ListServices(ctx, filter, params)
+	if err != nil {
+		log.Error("failed to list services", slog.String("error", err.Error()))
+		return nil, b.mapServiceError(err)
+	}
+
+	log.Info("success", slog.Int("count", len(services)))
+	return services, nil
+}
+
+// UpdateService обновляет сервис каталога
+func (b *Business) UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error) {
+	const op = "business.UpdateService"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("service_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		input.Name = &name
+	}
+	if input.Aliases != nil {
+		input.Aliases = trimNames(input.Aliases)
+	}
+
+	service, err := b.repo.UpdateService(ctx, id, input)
+	if err != nil {
+		log.Error("failed to update service", slog.String("error", err.Error()))
+		return nil, b.mapServiceError(err)
+	}
+
+	log.Info("success")
+	return service, nil
+}
+
+// DeleteService удаляет сервис каталога без подписок
+func (b *Business) DeleteService(ctx context.Context, id int64) error {
+	const op = "business.DeleteService"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("service_id", id))
+	log.Info("process started")
+
+	if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+		log.Warn("access denied")
+		return err
+	}
+
+	if err := b.repo.DeleteService(ctx, id); err != nil {
+		log.Error("failed to delete service", slog.String("error", err.Error()))
+		return b.mapServiceError(err)
+	}
+
+	log.Info("success")
+	return nil
+}
+
+// resolveService находит сервис подписки по ID, а если ID не задан — по названию
+// или синониму. Для неизвестного названия возвращает nil: такой сервис репозиторий
+// добавляет в каталог в транзакции, сохраняющей подписку, и откат подписки убирает его.
+// Неизвестное название принимается только от субъекта с правом изменять каталог.
+func (b *Business) resolveService(ctx context.Context, id int64, name string) (*domain.Service, error) {
+	if id != 0 {
+		service, err := b.repo.GetServiceByID(ctx, id)
+		if err != nil {
+			return nil, b.mapServiceError(err)
+		}
+		return service, nil
+	}
+
+	service, err := b.repo.GetServiceByName(ctx, strings.TrimSpace(name))
+	if errors.Is(err, repository.ErrNotFound) {
+		if _, err := authorize(ctx, domain.ScopeCatalogWrite); err != nil {
+			return nil, ErrUnknownService
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, b.mapServiceError(err)
+	}
+	return service, nil
+}
+
+func trimNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -2,7 +2,10 @@ package business
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
@@ -12,9 +15,20 @@ import (
 // CreateSubscription создаёт новую подписку
 func (b *Business) CreateSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) (*domain.Subscription, error) {
 	const op = "business.CreateSubscription"
+	defer b.observe(op, time.Now())
 	log := b.log.With(slog.String("op", op), slog.String("user_id", input.UserID.String()))
 	log.Info("process started")
 
+	if err := authorizeUser(ctx, domain.ScopeSubscriptionsWrite, input.UserID); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := b.prepareSubscription(ctx, input); err != nil {
+		log.Error("failed to prepare subscription", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	sub, err := b.repo.CreateSubscription(ctx, input)
 	if err != nil {
 		log.Error("failed to create subscription", slog.String("error", err.Error()))
@@ -25,12 +39,99 @@ func (b *Business) CreateSubscription(ctx context.Context, input *domain.CreateS
 	return sub, nil
 }
 
+// CreateSubscriptions создаёт подписки пакетом. В атомарном режиме ошибка любой
+// подписки отменяет весь пакет, иначе подписки создаются независимо. Неизвестные
+// сервисы добавляются в каталог вместе с подписками и при отмене пакета не остаются.
+// Результаты соответствуют inputs по индексу.
+func (b *Business) CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error) {
+	const op = "business.CreateSubscriptions"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Int("size", len(inputs)), slog.Bool("atomic", atomic))
+	log.Info("process started")
+
+	for _, input := range inputs {
+		if err := authorizeUser(ctx, domain.ScopeSubscriptionsWrite, input.UserID); err != nil {
+			log.Warn("access denied", slog.String("user_id", input.UserID.String()))
+			return nil, err
+		}
+	}
+
+	results := make([]domain.BatchResult, len(inputs))
+	prepared := make([]*domain.CreateSubscriptionInput, 0, len(inputs))
+	positions := make([]int, 0, len(inputs))
+	for i, input := range inputs {
+		if err := b.prepareSubscription(ctx, input); err != nil {
+			results[i].Err = err
+			continue
+		}
+		prepared = append(prepared, input)
+		positions = append(positions, i)
+	}
+
+	if atomic && len(prepared) < len(inputs) {
+		log.Warn("batch rejected", slog.Int("failed", len(inputs)-len(prepared)))
+		return results, nil
+	}
+
+	created, err := b.repo.CreateSubscriptions(ctx, prepared, atomic)
+	if err != nil {
+		log.Error("failed to create subscriptions", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	failed := len(inputs) - len(prepared)
+	for i, result := range created {
+		if result.Err != nil {
+			result.Err = b.mapError(result.Err)
+			failed++
+		}
+		results[positions[i]] = result
+	}
+
+	log.Info("success", slog.Int("failed", failed), slog.Duration("duration", time.Since(start)))
+	return results, nil
+}
+
+// prepareSubscription разрешает сервис подписки и подставляет цену сервиса по умолчанию
+func (b *Business) prepareSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) error {
+	service, err := b.resolveService(ctx, input.ServiceID, input.ServiceName)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		// Сервис добавится в каталог вместе с подпиской, цены по умолчанию у него нет
+		input.ServiceName = strings.TrimSpace(input.ServiceName)
+		if input.Price == 0 {
+			return ErrPriceRequired
+		}
+		return nil
+	}
+	input.ServiceID = service.ID
+	input.ServiceName = service.Name
+
+	if input.Price == 0 {
+		if service.DefaultPrice == nil {
+			return ErrPriceRequired
+		}
+		input.Price = *service.DefaultPrice
+	}
+
+	return nil
+}
+
 // GetSubscriptionByID получает подписку по ID
 func (b *Business) GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error) {
 	const op = "business.GetSubscriptionByID"
+	defer b.observe(op, time.Now())
 	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
 	log.Info("process started")
 
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsRead, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
 	sub, err := b.repo.GetSubscriptionByID(ctx, id)
 	if err != nil {
 		log.Error("failed to get subscription", slog.String("error", err.Error()))
@@ -41,57 +142,219 @@ func (b *Business) GetSubscriptionByID(ctx context.Context, id int64) (*domain.S
 	return sub, nil
 }
 
-// ListSubscriptions возвращает список подписок
-func (b *Business) ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error) {
+// ListSubscriptionPrices возвращает историю цен подписки
+func (b *Business) ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error) {
+	const op = "business.ListSubscriptionPrices"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
+	log.Info("process started")
+
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsRead, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// Пустая история и несуществующая подписка должны различаться
+	if _, err := b.repo.GetSubscriptionByID(ctx, id); err != nil {
+		log.Error("failed to get subscription", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	prices, err := b.repo.ListSubscriptionPrices(ctx, id)
+	if err != nil {
+		log.Error("failed to list subscription prices", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Info("success", slog.Int("count", len(prices)))
+	return prices, nil
+}
+
+// ListSubscriptions возвращает страницу подписок, подходящих под фильтр
+func (b *Business) ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) (*domain.SubscriptionPage, error) {
 	const op = "business.ListSubscriptions"
 	start := time.Now()
+	defer b.observe(op, start)
 
 	log := b.log.With(
 		slog.String("op", op),
 		slog.Int("limit", int(params.Limit)),
 		slog.Int("offset", int(params.Offset)),
+		slog.Bool("cursor", params.Cursor != nil),
+		slog.String("sort", string(filter.Sort)),
 	)
 	log.Info("process started")
 
-	subs, err := b.repo.ListSubscriptions(ctx, params)
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+	filter.UserID = userID
+
+	if err := validateListFilter(filter, params); err != nil {
+		log.Warn("invalid list filter", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	page, err := b.subscriptionPage(ctx, filter, params)
 	if err != nil {
 		log.Error("failed to list subscriptions", slog.String("error", err.Error()))
 		return nil, b.mapError(err)
 	}
 
-	log.Info("success", slog.Int("count", len(subs)), slog.Duration("duration", time.Since(start)))
-	return subs, nil
+	log.Info("success", slog.Int("count", len(page.Subscriptions)), slog.Int64("total", page.Total),
+		slog.Duration("duration", time.Since(start)))
+	return page, nil
+}
+
+// validateListFilter проверяет согласованность фильтров списка подписок
+func validateListFilter(filter domain.ListFilter, params domain.ListParams) error {
+	switch {
+	case !filter.Sort.Valid():
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidFilter, filter.Sort)
+	case params.Cursor != nil && !filter.Sort.SupportsCursor():
+		return fmt.Errorf("%w: cursor is supported only with default sort", ErrInvalidFilter)
+	case filter.PriceMin != nil && *filter.PriceMin < 0, filter.PriceMax != nil && *filter.PriceMax < 0:
+		return fmt.Errorf("%w: price_min and price_max should be >= 0", ErrInvalidFilter)
+	case filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax:
+		return fmt.Errorf("%w: price_min should be <= price_max", ErrInvalidFilter)
+	case filter.StartFrom != nil && filter.StartTo != nil && filter.StartFrom.After(*filter.StartTo):
+		return fmt.Errorf("%w: start_from should be <= start_to", ErrInvalidFilter)
+	case filter.ServiceNamePrefix != nil && strings.TrimSpace(*filter.ServiceNamePrefix) == "":
+		return fmt.Errorf("%w: service_name_prefix should not be empty", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// ExportSubscriptions передаёт в fn все подписки, подходящие под фильтр, без пагинации
+func (b *Business) ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error {
+	const op = "business.ExportSubscriptions"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.String("sort", string(filter.Sort)))
+	log.Info("process started")
+
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return err
+	}
+	filter.UserID = userID
+
+	if err := validateListFilter(filter, domain.ListParams{}); err != nil {
+		log.Warn("invalid list filter", slog.String("error", err.Error()))
+		return err
+	}
+
+	count := 0
+	err = b.repo.ExportSubscriptions(ctx, filter, func(sub *domain.Subscription) error {
+		count++
+		return fn(sub)
+	})
+	if err != nil {
+		log.Error("failed to export subscriptions", slog.Int("exported", count), slog.String("error", err.Error()))
+		return b.mapError(err)
+	}
+
+	log.Info("success", slog.Int("count", count), slog.Duration("duration", time.Since(start)))
+	return nil
 }
 
-// ListSubscriptionsByUserID возвращает список подписок для user
-func (b *Business) ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error) {
+// ListSubscriptionsByUserID возвращает страницу подписок пользователя
+func (b *Business) ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) (*domain.SubscriptionPage, error) {
 	const op = "business.ListSubscriptionsByUserID"
 	start := time.Now()
+	defer b.observe(op, start)
 
 	log := b.log.With(
 		slog.String("op", op),
 		slog.String("user_id", userID.String()),
 		slog.Int("limit", int(params.Limit)),
 		slog.Int("offset", int(params.Offset)),
+		slog.Bool("cursor", params.Cursor != nil),
 	)
 	log.Info("process started")
 
-	subs, err := b.repo.ListSubscriptionsByUserID(ctx, userID, params)
+	if err := authorizeUser(ctx, domain.ScopeSubscriptionsRead, userID); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	page, err := b.subscriptionPage(ctx, domain.ListFilter{UserID: &userID}, params)
 	if err != nil {
 		log.Error("failed to list subscriptions", slog.String("error", err.Error()))
 		return nil, b.mapError(err)
 	}
 
-	log.Info("success", slog.Int("count", len(subs)), slog.Duration("duration", time.Since(start)))
-	return subs, nil
+	log.Info("success", slog.Int("count", len(page.Subscriptions)), slog.Int64("total", page.Total),
+		slog.Duration("duration", time.Since(start)))
+	return page, nil
+}
+
+// subscriptionPage загружает страницу подписок с лишней записью, по которой
+// определяется наличие следующей страницы, и общее число подписок под фильтром
+func (b *Business) subscriptionPage(ctx context.Context, filter domain.ListFilter, params domain.ListParams) (*domain.SubscriptionPage, error) {
+	probe := params
+	probe.Limit++
+
+	subs, err := b.repo.ListSubscriptions(ctx, filter, probe)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := b.repo.CountSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.SubscriptionPage{Subscriptions: subs, Total: total}
+	if len(subs) > int(params.Limit) {
+		page.Subscriptions = subs[:params.Limit]
+		page.HasMore = true
+	}
+
+	return page, nil
 }
 
 // UpdateSubscription обновляет подписку
 func (b *Business) UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error) {
 	const op = "business.UpdateSubscription"
+	defer b.observe(op, time.Now())
 	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
 	log.Info("process started")
 
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsWrite, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if input.ServiceID != nil || input.ServiceName != nil {
+		var serviceID int64
+		var serviceName string
+		if input.ServiceID != nil {
+			serviceID = *input.ServiceID
+		}
+		if input.ServiceName != nil {
+			serviceName = *input.ServiceName
+		}
+
+		service, err := b.resolveService(ctx, serviceID, serviceName)
+		if err != nil {
+			log.Error("failed to resolve service", slog.String("error", err.Error()))
+			return nil, err
+		}
+		if service != nil {
+			input.ServiceID = &service.ID
+			input.ServiceName = &service.Name
+		} else {
+			// Сервис добавится в каталог в транзакции изменения подписки
+			name := strings.TrimSpace(serviceName)
+			input.ServiceID = nil
+			input.ServiceName = &name
+		}
+	}
+
 	sub, err := b.repo.UpdateSubscription(ctx, id, input)
 	if err != nil {
 		log.Error("failed to update subscription", slog.String("error", err.Error()))
@@ -102,13 +365,72 @@ func (b *Business) UpdateSubscription(ctx context.Context, id int64, input domai
 	return sub, nil
 }
 
-// DeleteSubscription удаляет подписку
-func (b *Business) DeleteSubscription(ctx context.Context, id int64) error {
+// PauseSubscription приостанавливает активную подписку с текущего месяца
+func (b *Business) PauseSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	return b.transitionSubscription(ctx, "business.PauseSubscription", id,
+		domain.SubscriptionStatusPaused, b.repo.PauseSubscription)
+}
+
+// ResumeSubscription возобновляет приостановленную подписку с текущего месяца
+func (b *Business) ResumeSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	return b.transitionSubscription(ctx, "business.ResumeSubscription", id,
+		domain.SubscriptionStatusActive, b.repo.ResumeSubscription)
+}
+
+// CancelSubscription отменяет подписку: текущий месяц становится последним
+func (b *Business) CancelSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	return b.transitionSubscription(ctx, "business.CancelSubscription", id,
+		domain.SubscriptionStatusCancelled, b.repo.CancelSubscription)
+}
+
+// transitionSubscription проверяет, что подписку можно перевести в статус next,
+// и выполняет переход с текущего месяца
+func (b *Business) transitionSubscription(ctx context.Context, op string, id int64, next domain.SubscriptionStatus,
+	apply func(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error),
+) (*domain.Subscription, error) {
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
+	log.Info("process started")
+
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsWrite, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	sub, err := b.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		log.Error("failed to get subscription", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	if !sub.Status.CanTransitionTo(next) {
+		log.Warn("invalid status transition", slog.String("from", string(sub.Status)), slog.String("to", string(next)))
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, sub.Status, next)
+	}
+
+	sub, err = apply(ctx, id, domain.MonthStart(time.Now()))
+	if err != nil {
+		log.Error("failed to change subscription status", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Info("success", slog.String("status", string(sub.Status)))
+	return sub, nil
+}
+
+// DeleteSubscription удаляет подписку; если задан ifVersion — только в этой версии
+func (b *Business) DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error {
 	const op = "business.DeleteSubscription"
+	defer b.observe(op, time.Now())
 	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
 	log.Info("process started")
 
-	err := b.repo.DeleteSubscription(ctx, id)
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsWrite, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return err
+	}
+
+	err := b.repo.DeleteSubscription(ctx, id, ifVersion)
 	if err != nil {
 		log.Error("failed to delete subscription", slog.String("error", err.Error()))
 		return b.mapError(err)
@@ -118,13 +440,89 @@ func (b *Business) DeleteSubscription(ctx context.Context, id int64) error {
 	return nil
 }
 
+// RestoreSubscription восстанавливает удалённую подписку
+func (b *Business) RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	const op = "business.RestoreSubscription"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Int64("subscription_id", id))
+	log.Info("process started")
+
+	if err := b.authorizeSubscription(ctx, domain.ScopeSubscriptionsWrite, id); err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	sub, err := b.repo.RestoreSubscription(ctx, id)
+	if err != nil {
+		err = b.mapError(err)
+		// Не удалённая подписка находится обычным чтением
+		if errors.Is(err, ErrNotFound) {
+			if _, getErr := b.repo.GetSubscriptionByID(ctx, id); getErr == nil {
+				log.Warn("subscription is not deleted")
+				return nil, ErrNotDeleted
+			}
+		}
+		log.Error("failed to restore subscription", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Info("success")
+	return sub, nil
+}
+
+// PurgeDeletedSubscriptions окончательно удаляет подписки, удалённые больше retention назад
+func (b *Business) PurgeDeletedSubscriptions(ctx context.Context, retention time.Duration) (int64, error) {
+	const op = "business.PurgeDeletedSubscriptions"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op), slog.Duration("retention", retention))
+
+	purged, err := b.repo.PurgeDeletedSubscriptions(ctx, time.Now().Add(-retention))
+	if err != nil {
+		log.Error("failed to purge subscriptions", slog.String("error", err.Error()))
+		return 0, b.mapError(err)
+	}
+
+	log.Info("success", slog.Int64("purged", purged))
+	return purged, nil
+}
+
+// CountSubscriptionsByStatus подсчитывает неудалённые подписки всех организаций по статусам.
+// Используется метриками и не проверяет права: вызывается вне запросов пользователей.
+func (b *Business) CountSubscriptionsByStatus(ctx context.Context) (map[domain.SubscriptionStatus]int64, error) {
+	const op = "business.CountSubscriptionsByStatus"
+	defer b.observe(op, time.Now())
+	log := b.log.With(slog.String("op", op))
+
+	counts, err := b.repo.CountSubscriptionsByStatus(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to count subscriptions", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	return counts, nil
+}
+
 // CalculateTotalCost подсчитывает суммарную стоимость подписок
 func (b *Business) CalculateTotalCost(ctx context.Context, filter domain.CostFilter) (domain.TotalCost, error) {
 	const op = "business.CalculateTotalCost"
 	start := time.Now()
-	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod))
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod),
+		slog.String("mode", string(filter.Mode)), slog.String("currency", filter.Currency))
 	log.Info("process started")
 
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return domain.TotalCost{}, err
+	}
+	filter.UserID = userID
+
+	if err := b.checkExchangeRates(ctx, filter); err != nil {
+		log.Error("cannot convert cost", slog.String("error", err.Error()))
+		return domain.TotalCost{}, err
+	}
+
 	result, err := b.repo.CalculateTotalCost(ctx, filter)
 	if err != nil {
 		log.Error("failed to calculate total cost", slog.String("error", err.Error()))
@@ -135,3 +533,170 @@ func (b *Business) CalculateTotalCost(ctx context.Context, filter domain.CostFil
 		slog.Duration("duration", time.Since(start)))
 	return result, nil
 }
+
+// CalculateMonthlyCost подсчитывает стоимость подписок по месяцам периода
+func (b *Business) CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error) {
+	const op = "business.CalculateMonthlyCost"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod))
+	log.Info("process started")
+
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+	filter.UserID = userID
+	// Разбивка по месяцам считается в режиме prorated: курсы проверяются за каждый месяц подписки
+	filter.Mode = domain.CostModeProrated
+
+	if err := b.checkExchangeRates(ctx, filter); err != nil {
+		log.Error("cannot convert cost", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	months, err := b.repo.CalculateMonthlyCost(ctx, filter)
+	if err != nil {
+		log.Error("failed to calculate monthly cost", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Debug("success", slog.Int("months", len(months)), slog.Duration("duration", time.Since(start)))
+	return months, nil
+}
+
+// CalculateGroupedCost подсчитывает стоимость подписок в разрезе сервисов или пользователей
+func (b *Business) CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error) {
+	const op = "business.CalculateGroupedCost"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod),
+		slog.String("group_by", string(params.GroupBy)), slog.Int("limit", int(params.Limit)))
+	log.Info("process started")
+
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+	filter.UserID = userID
+
+	if err := b.checkExchangeRates(ctx, filter); err != nil {
+		log.Error("cannot convert cost", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	groups, err := b.repo.CalculateGroupedCost(ctx, filter, params)
+	if err != nil {
+		log.Error("failed to calculate grouped cost", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Debug("success", slog.Int("groups", len(groups)), slog.Duration("duration", time.Since(start)))
+	return groups, nil
+}
+
+// CalculateOrganizationCost подсчитывает стоимость подписок всех пользователей организации
+// субъекта; доступна только субъектам с правом на подписки всех пользователей
+func (b *Business) CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error) {
+	const op = "business.CalculateOrganizationCost"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod),
+		slog.String("mode", string(filter.Mode)), slog.String("currency", filter.Currency))
+	log.Info("process started")
+
+	principal, err := authorize(ctx, domain.ScopeSubscriptionsRead)
+	if err == nil && !principal.HasScope(domain.ScopeAllUsers) {
+		err = ErrForbidden
+	}
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return domain.OrganizationCost{}, err
+	}
+	filter.UserID = nil
+
+	if err := b.checkExchangeRates(ctx, filter); err != nil {
+		log.Error("cannot convert cost", slog.String("error", err.Error()))
+		return domain.OrganizationCost{}, err
+	}
+
+	result, err := b.repo.CalculateOrganizationCost(ctx, filter)
+	if err != nil {
+		log.Error("failed to calculate organization cost", slog.String("error", err.Error()))
+		return domain.OrganizationCost{}, b.mapError(err)
+	}
+
+	log.Debug("success", slog.Int64("total_cost", result.TotalCost), slog.Int64("users", result.UserCount),
+		slog.Duration("duration", time.Since(start)))
+	return result, nil
+}
+
+// BuildCostReport собирает данные отчёта о стоимости за период: итог, разбивку
+// по сервисам и пользователям и стоимость каждой подписки
+func (b *Business) BuildCostReport(ctx context.Context, filter domain.CostFilter) (*domain.CostReport, error) {
+	const op = "business.BuildCostReport"
+	start := time.Now()
+	defer b.observe(op, start)
+	log := b.log.With(slog.String("op", op), slog.Time("start", filter.StartPeriod), slog.Time("end", filter.EndPeriod))
+	log.Info("process started")
+
+	userID, err := scopeUserID(ctx, domain.ScopeSubscriptionsRead, filter.UserID)
+	if err != nil {
+		log.Warn("access denied", slog.String("error", err.Error()))
+		return nil, err
+	}
+	filter.UserID = userID
+
+	if err := b.checkExchangeRates(ctx, filter); err != nil {
+		log.Error("cannot convert cost", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	var report domain.CostReport
+
+	report.Total, err = b.repo.CalculateTotalCost(ctx, filter)
+	if err != nil {
+		log.Error("failed to calculate total cost", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	report.Services, err = b.repo.CalculateGroupedCost(ctx, filter, domain.CostGroupParams{GroupBy: domain.CostGroupByServiceName})
+	if err != nil {
+		log.Error("failed to calculate cost by service", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	report.Users, err = b.repo.CalculateGroupedCost(ctx, filter, domain.CostGroupParams{GroupBy: domain.CostGroupByUserID})
+	if err != nil {
+		log.Error("failed to calculate cost by user", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	report.Subscriptions, err = b.repo.ListSubscriptionCosts(ctx, filter)
+	if err != nil {
+		log.Error("failed to list subscription costs", slog.String("error", err.Error()))
+		return nil, b.mapError(err)
+	}
+
+	log.Info("success", slog.Int("subscriptions", len(report.Subscriptions)), slog.Duration("duration", time.Since(start)))
+	return &report, nil
+}
+
+// checkExchangeRates проверяет, что для пересчёта стоимости в валюту фильтра хватает курсов
+func (b *Business) checkExchangeRates(ctx context.Context, filter domain.CostFilter) error {
+	missing, err := b.repo.ListMissingExchangeRates(ctx, filter)
+	if err != nil {
+		return b.mapError(err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rates := make([]string, len(missing))
+	for i, m := range missing {
+		rates[i] = m.Currency + " " + m.Month.Format("01-2006")
+	}
+	return fmt.Errorf("%w: %s", ErrMissingExchangeRate, strings.Join(rates, ", "))
+}
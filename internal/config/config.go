@@ -9,9 +9,12 @@ import (
 )
 
 type Config struct {
-	App  AppConfig      `yaml:"app"`
-	HTTP HTTPConfig     `yaml:"http"`
-	PG   PostgresConfig `yaml:"postgres"`
+	App         AppConfig         `yaml:"app"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	PG          PostgresConfig    `yaml:"postgres"`
+	Retention   RetentionConfig   `yaml:"retention"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Auth        AuthConfig        `yaml:"auth"`
 }
 
 // AppConfig — sensitive data only from ENV
@@ -36,6 +39,9 @@ type PostgresConfig struct {
 	MinConns        int           `yaml:"minConns" env:"PG_MIN_CONNS" env-default:"2"`
 	MaxConnLifeTime time.Duration `yaml:"maxConnLifeTime" env:"PG_MAX_CONN_LIFETIME" env-default:"1h"`
 	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" env:"PG_MAX_CONN_IDLE_TIME" env-default:"15m"`
+	// Role is assumed with SET ROLE after connecting so that row level security applies
+	// even to a superuser login; empty keeps the login role
+	Role string `yaml:"role" env:"PG_ROLE" env-default:"subscriptions_app"`
 }
 
 // HTTPConfig — from YAML (can override via ENV if needed)
@@ -45,6 +51,44 @@ type HTTPConfig struct {
 	ReadTimeout        time.Duration `yaml:"readTimeout" env:"HTTP_READ_TIMEOUT" env-default:"10s"`
 	WriteTimeout       time.Duration `yaml:"writeTimeout" env:"HTTP_WRITE_TIMEOUT" env-default:"10s"`
 	MaxHeaderMegabytes int           `yaml:"maxHeaderBytes" env:"HTTP_MAX_HEADER_BYTES" env-default:"1"`
+
+	// Admin listener serves /metrics apart from the API; empty HTTP_ADMIN_PORT disables it
+	AdminHost string `yaml:"adminHost" env:"HTTP_ADMIN_HOST" env-default:"0.0.0.0"`
+	AdminPort string `yaml:"adminPort" env:"HTTP_ADMIN_PORT" env-default:"9090"`
+}
+
+// RetentionConfig — from YAML (can override via ENV if needed)
+type RetentionConfig struct {
+	// Soft-deleted subscriptions are purged after this many days; 0 disables purging
+	DeletedSubscriptionsDays int           `yaml:"deletedSubscriptionsDays" env:"RETENTION_DELETED_SUBSCRIPTIONS_DAYS" env-default:"30"`
+	PurgeInterval            time.Duration `yaml:"purgeInterval" env:"RETENTION_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// DeletedSubscriptions returns how long soft-deleted subscriptions are kept
+func (c RetentionConfig) DeletedSubscriptions() time.Duration {
+	return time.Duration(c.DeletedSubscriptionsDays) * 24 * time.Hour
+}
+
+// IdempotencyConfig — from YAML (can override via ENV if needed)
+type IdempotencyConfig struct {
+	// Responses to requests with Idempotency-Key are replayed for this long
+	TTL           time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	SweepInterval time.Duration `yaml:"sweepInterval" env:"IDEMPOTENCY_SWEEP_INTERVAL" env-default:"1h"`
+	// Bodies of requests with Idempotency-Key are read into memory; larger ones are rejected with 413
+	MaxBodyBytes int64 `yaml:"maxBodyBytes" env:"IDEMPOTENCY_MAX_BODY_BYTES" env-default:"1048576"`
+}
+
+// AuthConfig — service account credentials from ENV, token settings from YAML
+type AuthConfig struct {
+	// Sensitive — from ENV only, format "client_id:secret,client_id:secret"
+	ServiceAccounts map[string]string `env:"AUTH_SERVICE_ACCOUNTS"`
+	// Roles of service accounts, format "client_id:role"; user if omitted
+	ServiceAccountRoles map[string]string `env:"AUTH_SERVICE_ACCOUNT_ROLES"`
+	// Organizations of service accounts, format "client_id:organization_uuid"; default organization if omitted
+	ServiceAccountOrganizations map[string]string `env:"AUTH_SERVICE_ACCOUNT_ORGANIZATIONS"`
+
+	// Non-sensitive — from YAML (can override via ENV if needed)
+	TokenTTL time.Duration `yaml:"tokenTTL" env:"AUTH_TOKEN_TTL" env-default:"1h"`
 }
 
 // MustInit loads config or panics — use in main()
@@ -85,11 +129,13 @@ func (c *Config) LogValue() slog.Value {
 			slog.Duration("read_timeout", c.HTTP.ReadTimeout),
 			slog.Duration("write_timeout", c.HTTP.WriteTimeout),
 			slog.Int("max_header_megabytes", c.HTTP.MaxHeaderMegabytes),
+			slog.String("admin_address", c.HTTP.AdminHost+":"+c.HTTP.AdminPort),
 		),
 		slog.Group("postgres",
 			slog.String("address", c.PG.Host+":"+c.PG.Port),
 			slog.String("database", c.PG.DBName),
 			slog.String("user", c.PG.User),
+			slog.String("role", c.PG.Role),
 			// Password intentionally omitted!
 			slog.String("ssl_mode", c.PG.SSLMode),
 			slog.Duration("connect_timeout", c.PG.ConnectTimeout),
@@ -98,5 +144,19 @@ func (c *Config) LogValue() slog.Value {
 			slog.Duration("max_conn_lifetime", c.PG.MaxConnLifeTime),
 			slog.Duration("max_conn_idle_time", c.PG.MaxConnIdleTime),
 		),
+		slog.Group("retention",
+			slog.Int("deleted_subscriptions_days", c.Retention.DeletedSubscriptionsDays),
+			slog.Duration("purge_interval", c.Retention.PurgeInterval),
+		),
+		slog.Group("idempotency",
+			slog.Duration("ttl", c.Idempotency.TTL),
+			slog.Duration("sweep_interval", c.Idempotency.SweepInterval),
+			slog.Int64("max_body_bytes", c.Idempotency.MaxBodyBytes),
+		),
+		slog.Group("auth",
+			// Service account secrets intentionally omitted!
+			slog.Int("service_accounts", len(c.Auth.ServiceAccounts)),
+			slog.Duration("token_ttl", c.Auth.TokenTTL),
+		),
 	)
 }
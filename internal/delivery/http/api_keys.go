@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// CreateAPIKey выпускает ключ API
+// @Summary      Выпустить ключ API
+// @Description  Выпускает долгоживущий ключ для машинных клиентов. Ключ передаётся заголовком
+// @Description  Authorization: ApiKey <key> и показывается только в этом ответе. Права ключа задаются
+// @Description  scopes и не могут превышать права выпускающего; без users:all ключ ограничен
+// @Description  подписками пользователя user_id.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        request  body      CreateAPIKeyRequest  true  "Данные ключа"
+// @Success      201      {object}  CreateAPIKeyResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api-keys [post]
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+
+	input := domain.CreateAPIKeyInput{
+		Name:   req.Name,
+		Scopes: make([]domain.Scope, len(req.Scopes)),
+		UserID: req.UserID,
+	}
+	for i, scope := range req.Scopes {
+		input.Scopes[i] = domain.Scope(scope)
+	}
+	if req.ExpiresAt != nil {
+		expiresAt, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidExpiresAt)
+			return
+		}
+		input.ExpiresAt = &expiresAt
+	}
+
+	key, plaintext, err := h.business.CreateAPIKey(r.Context(), input)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	// Ключ нельзя кэшировать: он больше нигде не показывается
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondJSON(w, http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: h.toAPIKeyResponse(key),
+		Key:            plaintext,
+	})
+}
+
+// ListAPIKeys возвращает ключи API
+// @Summary      Список ключей API
+// @Description  Возвращает ключи API, новые первыми, включая отозванные и просроченные. Сами ключи не возвращаются.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        limit   query     int  false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset  query     int  false  "Смещение (по умолчанию 0)"
+// @Success      200     {object}  ListAPIKeysResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /api-keys [get]
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.business.ListAPIKeys(r.Context(), h.parsePagination(r))
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"api_keys": h.toAPIKeyListResponse(keys),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// RevokeAPIKey отзывает ключ API
+// @Summary      Отозвать ключ API
+// @Description  Отзывает ключ API: запросы с ним больше не проходят аутентификацию. Ключ остаётся в списке.
+// @Tags         api-keys
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path  int  true  "ID ключа"
+// @Success      204  "Ключ отозван"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	if err := h.business.RevokeAPIKey(r.Context(), id); err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) toAPIKeyResponse(key *domain.APIKey) APIKeyResponse {
+	resp := APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     make([]string, len(key.Scopes)),
+		UserID:     key.UserID,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:  formatOptionalTimestamp(key.ExpiresAt),
+		LastUsedAt: formatOptionalTimestamp(key.LastUsedAt),
+		RevokedAt:  formatOptionalTimestamp(key.RevokedAt),
+	}
+	for i, scope := range key.Scopes {
+		resp.Scopes[i] = string(scope)
+	}
+	return resp
+}
+
+func (h *Handler) toAPIKeyListResponse(keys []domain.APIKey) []APIKeyResponse {
+	result := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		result[i] = h.toAPIKeyResponse(&key)
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// ListSubscriptionHistory возвращает журнал изменений подписки
+// @Summary      История изменений подписки
+// @Description  Возвращает записи журнала изменений подписки, новые первыми. before и after — снимки
+// @Description  подписки до и после изменения. История доступна и после удаления подписки.
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id      path      int  true   "ID подписки"
+// @Param        limit   query     int  false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset  query     int  false  "Смещение (по умолчанию 0)"
+// @Success      200     {object}  ListSubscriptionEventsResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /subscriptions/{id}/history [get]
+func (h *Handler) ListSubscriptionHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	events, err := h.business.ListSubscriptionHistory(r.Context(), id, h.parsePagination(r))
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"events": h.toSubscriptionEventListResponse(events),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// ListAuditEvents возвращает журнал изменений всех подписок
+// @Summary      Журнал изменений
+// @Description  Возвращает записи журнала изменений подписок, новые первыми. from включается в период, to — нет.
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        subscription_id  query     int     false  "Фильтр по ID подписки"
+// @Param        action           query     string  false  "Фильтр по действию"  Enums(created, updated, deleted, restored, purged)
+// @Param        actor            query     string  false  "Фильтр по автору изменения"
+// @Param        request_id       query     string  false  "Фильтр по ID запроса"
+// @Param        from             query     string  false  "Начало периода (RFC 3339)"
+// @Param        to               query     string  false  "Конец периода (RFC 3339)"
+// @Param        limit            query     int     false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset           query     int     false  "Смещение (по умолчанию 0)"
+// @Success      200              {object}  ListSubscriptionEventsResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      403              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /audit [get]
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseAuditFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.business.ListAuditEvents(r.Context(), filter, h.parsePagination(r))
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"events": h.toSubscriptionEventListResponse(events),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// parseAuditFilter разбирает фильтр журнала изменений из query параметров
+func (h *Handler) parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	query := r.URL.Query()
+	var filter domain.AuditFilter
+
+	if value := query.Get("subscription_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return domain.AuditFilter{}, errors.New(ErrInvalidSubscriptionID)
+		}
+		filter.SubscriptionID = &id
+	}
+
+	if value := query.Get("action"); value != "" {
+		action := domain.AuditAction(value)
+		filter.Action = &action
+	}
+
+	if value := query.Get("actor"); value != "" {
+		filter.Actor = &value
+	}
+
+	if value := query.Get("request_id"); value != "" {
+		filter.RequestID = &value
+	}
+
+	var err error
+	if filter.From, err = parseOptionalTimestamp(query.Get("from")); err != nil {
+		return domain.AuditFilter{}, errors.New(ErrInvalidTimestamp)
+	}
+	if filter.To, err = parseOptionalTimestamp(query.Get("to")); err != nil {
+		return domain.AuditFilter{}, errors.New(ErrInvalidTimestamp)
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) toSubscriptionEventResponse(event *domain.SubscriptionEvent) SubscriptionEventResponse {
+	resp := SubscriptionEventResponse{
+		ID:             event.ID,
+		SubscriptionID: event.SubscriptionID,
+		Action:         string(event.Action),
+		Before:         event.Before,
+		After:          event.After,
+		CreatedAt:      event.CreatedAt.Format(time.RFC3339),
+	}
+	if event.Actor != "" {
+		resp.Actor = &event.Actor
+	}
+	if event.RequestID != "" {
+		resp.RequestID = &event.RequestID
+	}
+	return resp
+}
+
+func (h *Handler) toSubscriptionEventListResponse(events []domain.SubscriptionEvent) []SubscriptionEventResponse {
+	result := make([]SubscriptionEventResponse, len(events))
+	for i, event := range events {
+		result[i] = h.toSubscriptionEventResponse(&event)
+	}
+	return result
+}
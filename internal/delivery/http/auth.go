@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/auth"
+	"github.com/Krokozabra213/effective_mobile/internal/business"
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+	bearerScheme          = "Bearer"
+	apiKeyScheme          = "ApiKey"
+)
+
+// authenticate пропускает к next только запросы с действительным токеном или ключом API
+// в заголовке Authorization. Субъект сохраняется в контексте и становится автором
+// изменений в журнале, его организация ограничивает все данные запроса.
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := h.authenticateRequest(w, r)
+		if !ok {
+			return
+		}
+
+		ctx := domain.WithPrincipal(r.Context(), principal)
+		ctx = domain.WithOrganization(ctx, principal.OrganizationID)
+		meta := domain.AuditMetaFrom(ctx)
+		meta.Actor = principal.Subject
+		ctx = domain.WithAuditMeta(ctx, meta)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticateRequest определяет субъекта по заголовку Authorization: Bearer <JWT> или
+// ApiKey <ключ>. Если субъект не определён, ответ уже отправлен.
+func (h *Handler) authenticateRequest(w http.ResponseWriter, r *http.Request) (domain.Principal, bool) {
+	scheme, credentials, ok := authorization(r)
+	switch {
+	case ok && strings.EqualFold(scheme, bearerScheme):
+		principal, err := h.auth.Authenticate(credentials)
+		if err == nil {
+			return principal, true
+		}
+		h.respondUnauthorized(w, ErrInvalidToken)
+	case ok && strings.EqualFold(scheme, apiKeyScheme):
+		principal, err := h.business.AuthenticateAPIKey(r.Context(), credentials)
+		if err == nil {
+			return principal, true
+		}
+		if errors.Is(err, business.ErrInvalidAPIKey) {
+			h.respondUnauthorized(w, ErrInvalidAPIKey)
+		} else {
+			h.handleBusinessError(w, err)
+		}
+	default:
+		h.respondUnauthorized(w, ErrMissingToken)
+	}
+	return domain.Principal{}, false
+}
+
+// authorization разбирает заголовок Authorization на схему и учётные данные
+func authorization(r *http.Request) (scheme, credentials string, ok bool) {
+	scheme, credentials, ok = strings.Cut(r.Header.Get(headerAuthorization), " ")
+	credentials = strings.TrimSpace(credentials)
+	return scheme, credentials, ok && credentials != ""
+}
+
+func (h *Handler) respondUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Add(headerWWWAuthenticate, bearerScheme)
+	w.Header().Add(headerWWWAuthenticate, apiKeyScheme)
+	h.respondError(w, http.StatusUnauthorized, message)
+}
+
+// IssueToken выдаёт токен доступа сервисному аккаунту
+// @Summary      Получить токен
+// @Description  Выдаёт токен доступа сервисному аккаунту по client_id и client_secret. Токен передаётся
+// @Description  в остальные запросы заголовком Authorization: Bearer.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      TokenRequest  true  "Учётные данные сервисного аккаунта"
+// @Success      200      {object}  TokenResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /auth/token [post]
+func (h *Handler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	var req TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.auth.IssueServiceToken(req.ClientID, req.ClientSecret)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			h.respondError(w, http.StatusUnauthorized, ErrInvalidCredentials)
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, TokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   bearerScheme,
+		ExpiresIn:   int64(time.Until(token.ExpiresAt).Round(time.Second) / time.Second),
+	})
+}
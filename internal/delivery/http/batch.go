@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// CreateSubscriptionsBatch создаёт подписки пакетом
+// @Summary      Пакетное создание подписок
+// @Description  Создаёт до 500 подписок в одной транзакции. Каждый элемент items проверяется так же,
+// @Description  как в POST /subscriptions. С atomic=true (по умолчанию) ошибка любого элемента отменяет
+// @Description  весь пакет и возвращается 422; с atomic=false создаются все корректные элементы,
+// @Description  а ошибки возвращаются по индексам.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        atomic           query   bool                             false  "Всё или ничего (по умолчанию true)"
+// @Param        request          body    BatchCreateSubscriptionsRequest  true   "Подписки"
+// @Param        Idempotency-Key  header  string                           false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200      {object}  BatchCreateSubscriptionsResponse  "Часть подписок не создана (atomic=false)"
+// @Success      201      {object}  BatchCreateSubscriptionsResponse  "Все подписки созданы"
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      413      {object}  ErrorResponse
+// @Failure      422      {object}  BatchCreateSubscriptionsResponse  "Пакет отменён (atomic=true)"
+// @Failure      500      {object}  ErrorResponse
+// @Router       /subscriptions/batch [post]
+func (h *Handler) CreateSubscriptionsBatch(w http.ResponseWriter, r *http.Request) {
+	atomic := true
+	if value := r.URL.Query().Get("atomic"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidAtomic)
+			return
+		}
+		atomic = parsed
+	}
+
+	var req BatchCreateSubscriptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := BatchCreateSubscriptionsResponse{Results: []BatchItemResponse{}}
+
+	// Некорректные элементы не доходят до бизнес-логики; positions связывает
+	// корректные элементы с их индексами в запросе
+	inputs := make([]*domain.CreateSubscriptionInput, 0, len(req.Items))
+	positions := make([]int, 0, len(req.Items))
+	for i, item := range req.Items {
+		input, err := h.toCreateSubscriptionInput(item)
+		if err != nil {
+			resp.Results = append(resp.Results, BatchItemResponse{Index: i, Error: err.Error()})
+			continue
+		}
+		inputs = append(inputs, &input)
+		positions = append(positions, i)
+	}
+
+	if len(inputs) > 0 && !(atomic && len(resp.Results) > 0) {
+		results, err := h.business.CreateSubscriptions(r.Context(), inputs, atomic)
+		if err != nil {
+			h.handleBusinessError(w, err)
+			return
+		}
+		resp.Results = append(resp.Results, h.toBatchItemResponses(results, positions)...)
+	}
+
+	h.respondJSON(w, h.summarizeBatch(&resp, atomic), &resp)
+}
+
+// toBatchItemResponses переводит результаты бизнес-логики в ответ с индексами запроса
+func (h *Handler) toBatchItemResponses(results []domain.BatchResult, positions []int) []BatchItemResponse {
+	items := []BatchItemResponse{}
+	for i, result := range results {
+		switch {
+		case result.Err != nil:
+			items = append(items, BatchItemResponse{Index: positions[i], Error: result.Err.Error()})
+		case result.Subscription != nil:
+			sub := h.toSubscriptionResponse(result.Subscription)
+			items = append(items, BatchItemResponse{Index: positions[i], Subscription: &sub})
+		}
+	}
+	return items
+}
+
+// summarizeBatch упорядочивает результаты по индексу, считает итоги и выбирает код ответа
+func (h *Handler) summarizeBatch(resp *BatchCreateSubscriptionsResponse, atomic bool) int {
+	sort.Slice(resp.Results, func(i, j int) bool { return resp.Results[i].Index < resp.Results[j].Index })
+
+	for _, item := range resp.Results {
+		if item.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Created++
+		}
+	}
+
+	switch {
+	case resp.Failed == 0:
+		return http.StatusCreated
+	case atomic:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Krokozabra213/effective_mobile/internal/business"
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
+)
+
+// importColumns колонки CSV импорта; end_date может отсутствовать
+var importColumns = []string{"service_name", "price", "user_id", "start_date", "end_date"}
+
+// ImportSubscriptions импортирует подписки из CSV
+// @Summary      Импорт подписок из CSV
+// @Description  Принимает text/csv с заголовком service_name,price,user_id,start_date,end_date (end_date
+// @Description  необязателен). Строки проверяются так же, как в POST /subscriptions, корректные создаются
+// @Description  пакетами, ошибочные попадают в отчёт с номером строки файла. С dry_run=true строки только
+// @Description  проверяются: разрешение сервиса и цена по умолчанию при этом не проверяются.
+// @Description  Каждый пакет фиксируется отдельно: если ошибка пакета прерывает импорт после уже созданных
+// @Description  строк, возвращается 200 с отчётом о них и полем interrupted — строкой, с которой файл
+// @Description  не импортирован. Повтор с тем же Idempotency-Key вернёт этот же отчёт.
+// @Tags         subscriptions
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        dry_run          query   bool    false  "Только проверить строки, ничего не создавая"
+// @Param        file             body    string  true   "CSV с подписками"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200      {object}  ImportSubscriptionsResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      415      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /subscriptions/import [post]
+func (h *Handler) ImportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/csv" {
+		h.respondError(w, http.StatusUnsupportedMediaType, ErrInvalidContentType)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidDryRun)
+			return
+		}
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCSVHeader)
+		return
+	}
+	columns, err := parseImportHeader(header)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := ImportSubscriptionsResponse{
+		DryRun:   dryRun,
+		Accepted: []ImportAcceptedRow{},
+		Rejected: []ImportRejectedRow{},
+	}
+
+	// Корректные строки копятся до MaxBatchSize и создаются одним пакетом
+	inputs := make([]*domain.CreateSubscriptionInput, 0, MaxBatchSize)
+	lines := make([]int, 0, MaxBatchSize)
+	flush := func() error {
+		if len(inputs) == 0 {
+			return nil
+		}
+		results, err := h.business.CreateSubscriptions(r.Context(), inputs, false)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				resp.Rejected = append(resp.Rejected, ImportRejectedRow{Line: lines[i], Error: result.Err.Error()})
+				continue
+			}
+			resp.Accepted = append(resp.Accepted, ImportAcceptedRow{Line: lines[i], ID: &result.Subscription.ID})
+		}
+		inputs, lines = inputs[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			resp.Rejected = append(resp.Rejected, ImportRejectedRow{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+			return
+		}
+		line, _ := reader.FieldPos(0)
+
+		input, err := h.parseImportRecord(record, columns)
+		if err != nil {
+			resp.Rejected = append(resp.Rejected, ImportRejectedRow{Line: line, Error: err.Error()})
+			continue
+		}
+
+		if dryRun {
+			resp.Accepted = append(resp.Accepted, ImportAcceptedRow{Line: line})
+			continue
+		}
+
+		inputs = append(inputs, &input)
+		lines = append(lines, line)
+		if len(inputs) == MaxBatchSize {
+			if err := flush(); err != nil {
+				h.interruptImport(w, &resp, lines[0], err)
+				return
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		h.interruptImport(w, &resp, lines[0], err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, &resp)
+}
+
+// interruptImport отвечает на ошибку пакета, начинающегося со строки line. Пока ничего
+// не создано, это обычная ошибка. После созданных пакетов отчёт о них отдаётся с 200:
+// ответ сохраняется под ключом идемпотентности, и повтор не создаст строки ещё раз.
+func (h *Handler) interruptImport(w http.ResponseWriter, resp *ImportSubscriptionsResponse, line int, err error) {
+	if len(resp.Accepted) == 0 {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	message := "internal error"
+	if errors.Is(err, business.ErrForbidden) {
+		message = business.ErrForbidden.Error()
+	}
+	resp.Interrupted = &ImportInterruption{Line: line, Error: message}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// parseImportHeader сопоставляет колонкам импорта их позиции в заголовке CSV
+func parseImportHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok && name != "end_date" {
+			return nil, fmt.Errorf("%s: missing column %s", ErrInvalidCSVHeader, name)
+		}
+	}
+	return columns, nil
+}
+
+// parseImportRecord переводит строку CSV в запрос на создание подписки и проверяет его.
+// Текст ошибки пригоден для отчёта клиенту.
+func (h *Handler) parseImportRecord(record []string, columns map[string]int) (domain.CreateSubscriptionInput, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	req := CreateSubscriptionRequest{
+		ServiceName: field("service_name"),
+		StartDate:   field("start_date"),
+	}
+
+	if price := field("price"); price != "" {
+		parsed, err := strconv.ParseInt(price, 10, 32)
+		if err != nil {
+			return domain.CreateSubscriptionInput{}, errors.New(ErrInvalidPriceFormat)
+		}
+		req.Price = int32(parsed)
+	}
+
+	userID, err := uuid.Parse(field("user_id"))
+	if err != nil {
+		return domain.CreateSubscriptionInput{}, errors.New(ErrInvalidUserIDFormat)
+	}
+	req.UserID = userID
+
+	if endDate := field("end_date"); endDate != "" {
+		req.EndDate = &endDate
+	}
+
+	return h.toCreateSubscriptionInput(req)
+}
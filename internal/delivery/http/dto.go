@@ -1,36 +1,76 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	"github.com/google/uuid"
 )
 
-var ErrPrice = errors.New("price should be >=0")
+var (
+	ErrPrice         = errors.New("price should be >=0")
+	ErrBillingPeriod = errors.New("billing_period should be one of weekly, monthly, quarterly, yearly")
+	ErrCurrency      = errors.New("currency should be ISO 4217 code")
+	ErrRate          = errors.New("rate should be > 0")
+	ErrService       = errors.New("service_name or service_id is required")
+	ErrServiceName   = errors.New("name is required")
+	ErrDefaultPrice  = errors.New("default_price should be > 0")
+	ErrTrialMonths   = errors.New("trial_months should be >=0")
+	ErrIntroPrice    = errors.New("intro_price should be >=0 and set together with intro_months > 0")
+	ErrBatchSize     = fmt.Errorf("items should contain from 1 to %d subscriptions", MaxBatchSize)
+	ErrCredentials   = errors.New("client_id and client_secret are required")
+)
 
 // ===== Request DTOs =====
 
 // CreateSubscriptionRequest запрос на создание подписки
 type CreateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" example:"Yandex Plus"`
-	Price       int32     `json:"price" example:"400"`
-	UserID      uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string    `json:"start_date" example:"07-2025"`
-	EndDate     *string   `json:"end_date,omitempty" example:"12-2025"`
+	ServiceID     *int64    `json:"service_id,omitempty" example:"1"`
+	ServiceName   string    `json:"service_name" example:"Yandex Plus"`
+	Price         int32     `json:"price" example:"400"`
+	Currency      string    `json:"currency,omitempty" example:"RUB"`
+	BillingPeriod string    `json:"billing_period,omitempty" example:"monthly" enums:"weekly,monthly,quarterly,yearly"`
+	UserID        uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate     string    `json:"start_date" example:"07-2025"`
+	EndDate       *string   `json:"end_date,omitempty" example:"12-2025"`
+	TrialMonths   int32     `json:"trial_months,omitempty" example:"1"`
+	IntroPrice    *int32    `json:"intro_price,omitempty" example:"1"`
+	IntroMonths   int32     `json:"intro_months,omitempty" example:"3"`
 }
 
 func (r CreateSubscriptionRequest) Validate() error {
+	if r.ServiceID == nil && strings.TrimSpace(r.ServiceName) == "" {
+		return ErrService
+	}
 	if r.Price < 0 {
 		return ErrPrice
 	}
+	if r.BillingPeriod != "" && !domain.BillingPeriod(r.BillingPeriod).Valid() {
+		return ErrBillingPeriod
+	}
+	if r.Currency != "" && !domain.ValidCurrency(strings.ToUpper(r.Currency)) {
+		return ErrCurrency
+	}
+	if r.TrialMonths < 0 {
+		return ErrTrialMonths
+	}
+	if r.IntroMonths < 0 || (r.IntroPrice != nil) != (r.IntroMonths > 0) || (r.IntroPrice != nil && *r.IntroPrice < 0) {
+		return ErrIntroPrice
+	}
 	return nil
 }
 
 // UpdateSubscriptionRequest запрос на обновление подписки
 type UpdateSubscriptionRequest struct {
-	ServiceName *string `json:"service_name,omitempty" example:"Netflix"`
-	Price       *int    `json:"price,omitempty" example:"800"`
-	EndDate     *string `json:"end_date,omitempty" example:"12-2025"`
+	ServiceID     *int64  `json:"service_id,omitempty" example:"2"`
+	ServiceName   *string `json:"service_name,omitempty" example:"Netflix"`
+	Price         *int    `json:"price,omitempty" example:"800"`
+	Currency      *string `json:"currency,omitempty" example:"USD"`
+	BillingPeriod *string `json:"billing_period,omitempty" example:"yearly" enums:"weekly,monthly,quarterly,yearly"`
+	EndDate       *string `json:"end_date,omitempty" example:"12-2025"`
 }
 
 func (r UpdateSubscriptionRequest) Validate() error {
@@ -40,16 +80,82 @@ func (r UpdateSubscriptionRequest) Validate() error {
 	return nil
 }
 
+// MaxBatchSize максимальное число подписок в одном пакетном запросе
+const MaxBatchSize = 500
+
+// BatchCreateSubscriptionsRequest запрос на пакетное создание подписок
+type BatchCreateSubscriptionsRequest struct {
+	Items []CreateSubscriptionRequest `json:"items"`
+}
+
+func (r BatchCreateSubscriptionsRequest) Validate() error {
+	if len(r.Items) == 0 || len(r.Items) > MaxBatchSize {
+		return ErrBatchSize
+	}
+	return nil
+}
+
 // ===== Response DTOs =====
 
 type SubscriptionResponse struct {
-	ID          int64     `json:"id" example:"1"`
-	ServiceName string    `json:"service_name" example:"Yandex Plus"`
-	Price       int32     `json:"price" example:"400"`
-	UserID      uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
-	StartDate   string    `json:"start_date" example:"07-2025"`
-	EndDate     *string   `json:"end_date,omitempty" example:"12-2025"`
-	CreatedAt   string    `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	ID            int64     `json:"id" example:"1"`
+	ServiceID     int64     `json:"service_id" example:"1"`
+	ServiceName   string    `json:"service_name" example:"Yandex Plus"`
+	Price         int32     `json:"price" example:"400"`
+	Currency      string    `json:"currency" example:"RUB"`
+	BillingPeriod string    `json:"billing_period" example:"monthly"`
+	Status        string    `json:"status" example:"active" enums:"active,paused,cancelled,expired"`
+	TrialMonths   int32     `json:"trial_months" example:"1"`
+	IntroPrice    *int32    `json:"intro_price,omitempty" example:"1"`
+	IntroMonths   int32     `json:"intro_months" example:"3"`
+	UserID        uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate     string    `json:"start_date" example:"07-2025"`
+	EndDate       *string   `json:"end_date,omitempty" example:"12-2025"`
+	CreatedAt     string    `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	DeletedAt     *string   `json:"deleted_at,omitempty" example:"2025-02-01T09:00:00Z"`
+	Version       int32     `json:"version" example:"1"`
+}
+
+// BatchItemResponse результат создания одной подписки пакета; index — позиция в items
+type BatchItemResponse struct {
+	Index        int                   `json:"index" example:"0"`
+	Subscription *SubscriptionResponse `json:"subscription,omitempty"`
+	Error        string                `json:"error,omitempty" example:"invalid date format, expected MM-YYYY"`
+}
+
+// BatchCreateSubscriptionsResponse ответ на пакетное создание подписок
+// @Description Результаты по каждой подписке пакета. В атомарном режиме при любой
+// @Description ошибке ни одна подписка не создаётся, а results содержит только ошибки.
+type BatchCreateSubscriptionsResponse struct {
+	Created int                 `json:"created" example:"2"`
+	Failed  int                 `json:"failed" example:"1"`
+	Results []BatchItemResponse `json:"results"`
+}
+
+// ImportAcceptedRow строка CSV, прошедшая импорт; id отсутствует в режиме dry_run
+type ImportAcceptedRow struct {
+	Line int    `json:"line" example:"2"`
+	ID   *int64 `json:"id,omitempty" example:"1"`
+}
+
+// ImportRejectedRow строка CSV, отклонённая при импорте
+type ImportRejectedRow struct {
+	Line  int    `json:"line" example:"3"`
+	Error string `json:"error" example:"invalid date format, expected MM-YYYY"`
+}
+
+// ImportInterruption ошибка, прервавшая импорт: строки начиная с line не импортированы
+type ImportInterruption struct {
+	Line  int    `json:"line" example:"502"`
+	Error string `json:"error" example:"internal error"`
+}
+
+// ImportSubscriptionsResponse отчёт об импорте подписок; line — номер строки файла
+type ImportSubscriptionsResponse struct {
+	DryRun      bool                `json:"dry_run" example:"false"`
+	Accepted    []ImportAcceptedRow `json:"accepted"`
+	Rejected    []ImportRejectedRow `json:"rejected"`
+	Interrupted *ImportInterruption `json:"interrupted,omitempty"`
 }
 
 // TotalCostResponse ответ с суммарной стоимостью
@@ -65,8 +171,259 @@ func NewTotalCostResponse(totalCost int64, count int64) TotalCostResponse {
 	}
 }
 
+// MonthlyCostResponse стоимость подписок за один месяц
+type MonthlyCostResponse struct {
+	Month     string `json:"month" example:"07-2025"`
+	TotalCost int64  `json:"total_cost" example:"1200"`
+	Count     int64  `json:"count" example:"3"`
+}
+
+func NewMonthlyCostResponse(month string, totalCost int64, count int64) MonthlyCostResponse {
+	return MonthlyCostResponse{
+		Month:     month,
+		TotalCost: totalCost,
+		Count:     count,
+	}
+}
+
+// MonthlyCostListResponse ответ с помесячной стоимостью
+type MonthlyCostListResponse struct {
+	Months []MonthlyCostResponse `json:"months"`
+}
+
+// CostGroupResponse стоимость подписок одной группы
+type CostGroupResponse struct {
+	Key       string `json:"key" example:"Yandex Plus"`
+	TotalCost int64  `json:"total_cost" example:"4800"`
+	Count     int64  `json:"count" example:"2"`
+}
+
+func NewCostGroupResponse(key string, totalCost int64, count int64) CostGroupResponse {
+	return CostGroupResponse{
+		Key:       key,
+		TotalCost: totalCost,
+		Count:     count,
+	}
+}
+
+// CostGroupListResponse ответ со стоимостью по группам
+type CostGroupListResponse struct {
+	Groups []CostGroupResponse `json:"groups"`
+}
+
+// OrganizationCostResponse стоимость подписок всех пользователей организации
+type OrganizationCostResponse struct {
+	OrganizationID   uuid.UUID `json:"organization_id" example:"00000000-0000-0000-0000-000000000001"`
+	OrganizationName string    `json:"organization_name" example:"default"`
+	TotalCost        int64     `json:"total_cost" example:"1200"`
+	Count            int64     `json:"count" example:"3"`
+	UserCount        int64     `json:"user_count" example:"2"`
+}
+
+func NewOrganizationCostResponse(cost domain.OrganizationCost) OrganizationCostResponse {
+	return OrganizationCostResponse{
+		OrganizationID:   cost.Organization.ID,
+		OrganizationName: cost.Organization.Name,
+		TotalCost:        cost.TotalCost,
+		Count:            cost.Count,
+		UserCount:        cost.UserCount,
+	}
+}
+
 // ListSubscriptionsResponse ответ со списком подписок
-// @Description Список подписок с пагинацией
+// @Description Список подписок с пагинацией. total — число подписок под фильтрами без учёта
+// @Description пагинации. next_cursor передаётся в cursor для получения следующей страницы;
+// @Description отсутствует на последней странице и при сортировке, отличной от умолчания.
 type ListSubscriptionsResponse struct {
 	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+	Total         int64                  `json:"total" example:"42"`
+	Limit         int32                  `json:"limit" example:"10"`
+	Offset        int32                  `json:"offset" example:"0"`
+	HasMore       bool                   `json:"has_more" example:"true"`
+	NextCursor    *string                `json:"next_cursor,omitempty" example:"MTczNjkzNzAwMDAwMDAwMDoxMg"`
+}
+
+// SubscriptionPriceResponse цена подписки, действующая с месяца effective_from
+type SubscriptionPriceResponse struct {
+	Price         int32  `json:"price" example:"400"`
+	EffectiveFrom string `json:"effective_from" example:"01-2025"`
+	CreatedAt     string `json:"created_at" example:"2025-01-15T10:30:00Z"`
+}
+
+// ListSubscriptionPricesResponse ответ с историей цен подписки
+type ListSubscriptionPricesResponse struct {
+	Prices []SubscriptionPriceResponse `json:"prices"`
+}
+
+// SubscriptionEventResponse запись журнала изменений подписки
+// @Description before отсутствует у создания, after — у окончательной очистки.
+type SubscriptionEventResponse struct {
+	ID             int64           `json:"id" example:"1"`
+	SubscriptionID int64           `json:"subscription_id" example:"12"`
+	Action         string          `json:"action" example:"updated" enums:"created,updated,deleted,restored,purged"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Actor          *string         `json:"actor,omitempty" example:"admin"`
+	RequestID      *string         `json:"request_id,omitempty" example:"3f6c1c9e-8a1d-4d0e-9d4b-2b7d1c0e5f21"`
+	CreatedAt      string          `json:"created_at" example:"2025-01-15T10:30:00Z"`
+}
+
+// ListSubscriptionEventsResponse ответ с записями журнала изменений
+type ListSubscriptionEventsResponse struct {
+	Events []SubscriptionEventResponse `json:"events"`
+}
+
+// ===== Services =====
+
+// CreateServiceRequest запрос на создание сервиса каталога
+type CreateServiceRequest struct {
+	Name         string   `json:"name" example:"Yandex Plus"`
+	Aliases      []string `json:"aliases,omitempty" example:"Яндекс Плюс"`
+	Category     *string  `json:"category,omitempty" example:"music"`
+	DefaultPrice *int32   `json:"default_price,omitempty" example:"400"`
+}
+
+func (r CreateServiceRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrServiceName
+	}
+	if r.DefaultPrice != nil && *r.DefaultPrice <= 0 {
+		return ErrDefaultPrice
+	}
+	return nil
+}
+
+// UpdateServiceRequest запрос на обновление сервиса. Переданный список aliases
+// заменяет текущий целиком.
+type UpdateServiceRequest struct {
+	Name         *string  `json:"name,omitempty" example:"Yandex Plus"`
+	Aliases      []string `json:"aliases,omitempty" example:"Яндекс Плюс"`
+	Category     *string  `json:"category,omitempty" example:"music"`
+	DefaultPrice *int32   `json:"default_price,omitempty" example:"450"`
+}
+
+func (r UpdateServiceRequest) Validate() error {
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		return ErrServiceName
+	}
+	if r.DefaultPrice != nil && *r.DefaultPrice <= 0 {
+		return ErrDefaultPrice
+	}
+	return nil
+}
+
+// ServiceResponse сервис каталога
+type ServiceResponse struct {
+	ID           int64    `json:"id" example:"1"`
+	Name         string   `json:"name" example:"Yandex Plus"`
+	Aliases      []string `json:"aliases" example:"Яндекс Плюс"`
+	Category     *string  `json:"category,omitempty" example:"music"`
+	DefaultPrice *int32   `json:"default_price,omitempty" example:"400"`
+	CreatedAt    string   `json:"created_at" example:"2025-01-15T10:30:00Z"`
+}
+
+// ListServicesResponse ответ со списком сервисов
+type ListServicesResponse struct {
+	Services []ServiceResponse `json:"services"`
+}
+
+// ===== Exchange rates =====
+
+// CreateExchangeRateRequest запрос на создание курса валюты
+type CreateExchangeRateRequest struct {
+	Currency string  `json:"currency" example:"USD"`
+	Month    string  `json:"month" example:"07-2025"`
+	Rate     float64 `json:"rate" example:"92.5"`
+}
+
+func (r CreateExchangeRateRequest) Validate() error {
+	currency := strings.ToUpper(r.Currency)
+	if !domain.ValidCurrency(currency) || currency == domain.BaseCurrency {
+		return ErrCurrency
+	}
+	if r.Rate <= 0 {
+		return ErrRate
+	}
+	return nil
+}
+
+// UpdateExchangeRateRequest запрос на обновление курса валюты
+type UpdateExchangeRateRequest struct {
+	Rate float64 `json:"rate" example:"93.1"`
+}
+
+func (r UpdateExchangeRateRequest) Validate() error {
+	if r.Rate <= 0 {
+		return ErrRate
+	}
+	return nil
+}
+
+// ExchangeRateResponse курс валюты к базовой валюте на месяц
+type ExchangeRateResponse struct {
+	ID        int64   `json:"id" example:"1"`
+	Currency  string  `json:"currency" example:"USD"`
+	Month     string  `json:"month" example:"07-2025"`
+	Rate      float64 `json:"rate" example:"92.5"`
+	CreatedAt string  `json:"created_at" example:"2025-01-15T10:30:00Z"`
+}
+
+// ListExchangeRatesResponse ответ со списком курсов
+type ListExchangeRatesResponse struct {
+	ExchangeRates []ExchangeRateResponse `json:"exchange_rates"`
+}
+
+// ===== Auth =====
+
+// TokenRequest запрос токена доступа сервисным аккаунтом
+type TokenRequest struct {
+	ClientID     string `json:"client_id" example:"billing"`
+	ClientSecret string `json:"client_secret" example:"secret"`
+}
+
+func (r TokenRequest) Validate() error {
+	if r.ClientID == "" || r.ClientSecret == "" {
+		return ErrCredentials
+	}
+	return nil
+}
+
+// TokenResponse выданный токен доступа. expires_in — срок действия в секундах.
+type TokenResponse struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"3600"`
+}
+
+// CreateAPIKeyRequest запрос на выпуск ключа API. Без expires_at ключ бессрочный.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" example:"billing-export"`
+	Scopes    []string   `json:"scopes" example:"subscriptions:read,users:all"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ExpiresAt *string    `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+// APIKeyResponse ключ API без самого ключа; prefix — его начало
+type APIKeyResponse struct {
+	ID         int64      `json:"id" example:"1"`
+	Name       string     `json:"name" example:"billing-export"`
+	Prefix     string     `json:"prefix" example:"em_Xk3v9QaB"`
+	Scopes     []string   `json:"scopes" example:"subscriptions:read,users:all"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	CreatedBy  string     `json:"created_by" example:"admin"`
+	CreatedAt  string     `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	ExpiresAt  *string    `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"`
+	LastUsedAt *string    `json:"last_used_at,omitempty" example:"2025-01-16T08:00:00Z"`
+	RevokedAt  *string    `json:"revoked_at,omitempty" example:"2025-02-01T12:00:00Z"`
+}
+
+// CreateAPIKeyResponse выпущенный ключ API. key показывается только в этом ответе.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"em_Xk3v9QaB..."`
+}
+
+// ListAPIKeysResponse ответ со списком ключей API
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
 }
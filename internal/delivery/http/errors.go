@@ -1,9 +1,33 @@
 package handler
 
 const (
-	ErrInvalidBody         = "invalid request body"
-	ErrInvalidDate         = "invalid date format, expected MM-YYYY"
-	ErrInvalidIDFormat     = "invalid id format"
-	ErrInvalidID           = "id should be > 0"
-	ErrInvalidUserIDFormat = "invalid user_id format"
+	ErrInvalidBody           = "invalid request body"
+	ErrInvalidDate           = "invalid date format, expected MM-YYYY"
+	ErrInvalidIDFormat       = "invalid id format"
+	ErrInvalidID             = "id should be > 0"
+	ErrInvalidUserIDFormat   = "invalid user_id format"
+	ErrInvalidCostMode       = "invalid mode, expected overlap or prorated"
+	ErrInvalidGroupBy        = "invalid group_by, expected service_name or user_id"
+	ErrInvalidAmortize       = "invalid amortize, expected true or false"
+	ErrInvalidCurrency       = "invalid currency, expected ISO 4217 code"
+	ErrInvalidCursor         = "invalid cursor"
+	ErrInvalidPriceFilter    = "invalid price_min or price_max, expected integer"
+	ErrInvalidHasEndDate     = "invalid has_end_date, expected true or false"
+	ErrInvalidAtomic         = "invalid atomic, expected true or false"
+	ErrInvalidDryRun         = "invalid dry_run, expected true or false"
+	ErrInvalidContentType    = "invalid content type, expected text/csv"
+	ErrInvalidCSVHeader      = "invalid csv header"
+	ErrInvalidPriceFormat    = "invalid price format, expected integer"
+	ErrInvalidExportFormat   = "invalid format, expected csv or ndjson"
+	ErrInvalidIncludeDeleted = "invalid include_deleted, expected true or false"
+	ErrInvalidSubscriptionID = "invalid subscription_id, expected positive integer"
+	ErrInvalidTimestamp      = "invalid from or to, expected RFC 3339 timestamp"
+	ErrInvalidIfMatch        = "invalid If-Match, expected single entity tag or *"
+	ErrInvalidIdempotencyKey = "invalid Idempotency-Key, expected at most 255 characters"
+	ErrBodyTooLarge          = "request body too large"
+	ErrMissingToken          = "missing bearer token or api key"
+	ErrInvalidToken          = "invalid or expired token"
+	ErrInvalidAPIKey         = "invalid or expired api key"
+	ErrInvalidExpiresAt      = "invalid expires_at, expected RFC 3339 timestamp"
+	ErrInvalidCredentials    = "invalid client credentials"
 )
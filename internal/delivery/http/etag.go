@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// subscriptionETag возвращает сильный ETag подписки по её версии
+func subscriptionETag(sub *domain.Subscription) string {
+	return `"` + strconv.FormatInt(int64(sub.Version), 10) + `"`
+}
+
+// respondSubscription отвечает подпиской с её ETag
+func (h *Handler) respondSubscription(w http.ResponseWriter, status int, sub *domain.Subscription) {
+	w.Header().Set("ETag", subscriptionETag(sub))
+	h.respondJSON(w, status, h.toSubscriptionResponse(sub))
+}
+
+// parseIfMatch разбирает заголовок If-Match в ожидаемую версию подписки. Без заголовка
+// и для «*» версия не проверяется. Поддерживается один сильный ETag.
+func parseIfMatch(r *http.Request) (*int32, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, errors.New(ErrInvalidIfMatch)
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 32)
+	if err != nil {
+		return nil, errors.New(ErrInvalidIfMatch)
+	}
+
+	result := int32(version)
+	return &result, nil
+}
+
+// noneMatch сообщает, совпадает ли etag с одним из тегов заголовка If-None-Match.
+// Сравнение слабое: W/"1" совпадает с "1".
+func noneMatch(r *http.Request, etag string) bool {
+	value := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if value == "" {
+		return false
+	}
+	if value == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(value, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
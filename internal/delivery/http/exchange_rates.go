@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// CreateExchangeRate создаёт курс валюты на месяц
+// @Summary      Создать курс валюты
+// @Description  Задаёт стоимость одной единицы валюты в RUB на указанный месяц
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        request          body    CreateExchangeRateRequest  true   "Данные курса"
+// @Param        Idempotency-Key  header  string                     false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      201      {object}  ExchangeRateResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      413      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /exchange-rates [post]
+func (h *Handler) CreateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var req CreateExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	month, err := parseMonthYear(req.Month)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidDate)
+		return
+	}
+
+	input := domain.NewCreateExchangeRateInput(strings.ToUpper(req.Currency), month, req.Rate)
+
+	rate, err := h.business.CreateExchangeRate(r.Context(), &input)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+	resp := h.toExchangeRateResponse(rate)
+
+	h.respondJSON(w, http.StatusCreated, &resp)
+}
+
+// GetExchangeRateByID получает курс валюты по ID
+// @Summary      Получить курс валюты
+// @Description  Возвращает курс валюты по его идентификатору
+// @Tags         exchange-rates
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "ID курса"
+// @Success      200  {object}  ExchangeRateResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /exchange-rates/{id} [get]
+func (h *Handler) GetExchangeRateByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	rate, err := h.business.GetExchangeRateByID(r.Context(), id)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toExchangeRateResponse(rate))
+}
+
+// ListExchangeRates возвращает список курсов валют
+// @Summary      Список курсов валют
+// @Description  Возвращает курсы валют с пагинацией, новые месяцы первыми
+// @Tags         exchange-rates
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        currency  query     string  false  "Фильтр по валюте (ISO 4217)"
+// @Param        limit     query     int     false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset    query     int     false  "Смещение (по умолчанию 0)"
+// @Success      200       {object}  ListExchangeRatesResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /exchange-rates [get]
+func (h *Handler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	params := h.parsePagination(r)
+
+	var filter domain.ExchangeRateFilter
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		currency = strings.ToUpper(currency)
+		if !domain.ValidCurrency(currency) {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidCurrency)
+			return
+		}
+		filter.Currency = &currency
+	}
+
+	rates, err := h.business.ListExchangeRates(r.Context(), filter, params)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"exchange_rates": h.toExchangeRateListResponse(rates),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// UpdateExchangeRate обновляет значение курса
+// @Summary      Обновить курс валюты
+// @Description  Изменяет значение курса, валюта и месяц остаются прежними
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id       path      int                        true  "ID курса"
+// @Param        request  body      UpdateExchangeRateRequest  true  "Новое значение курса"
+// @Success      200      {object}  ExchangeRateResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /exchange-rates/{id} [patch]
+func (h *Handler) UpdateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	var req UpdateExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rate, err := h.business.UpdateExchangeRate(r.Context(), id, req.Rate)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toExchangeRateResponse(rate))
+}
+
+// DeleteExchangeRate удаляет курс валюты
+// @Summary      Удалить курс валюты
+// @Description  Удаляет курс валюты по ID
+// @Tags         exchange-rates
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path  int  true  "ID курса"
+// @Success      204  "Курс удалён"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /exchange-rates/{id} [delete]
+func (h *Handler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	if err := h.business.DeleteExchangeRate(r.Context(), id); err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// exportFormat формат выгрузки
+type exportFormat string
+
+const (
+	exportFormatCSV    exportFormat = "csv"
+	exportFormatNDJSON exportFormat = "ndjson"
+)
+
+// exportWriteTimeout заменяет общий таймаут записи сервера для выгрузок,
+// которые не успевают передаться за время обычного ответа
+const exportWriteTimeout = 10 * time.Minute
+
+var (
+	subscriptionExportColumns = []string{
+		"id", "service_id", "service_name", "price", "currency", "billing_period", "status",
+		"trial_months", "intro_price", "intro_months", "user_id", "start_date", "end_date", "created_at",
+	}
+	monthlyCostExportColumns = []string{"month", "total_cost", "count"}
+)
+
+// ExportSubscriptions выгружает подписки в CSV или NDJSON
+// @Summary      Выгрузка подписок
+// @Description  Выгружает все подписки, подходящие под фильтры списка, без пагинации. Строки передаются
+// @Description  по мере чтения из базы; ошибка посреди выгрузки обрывает соединение.
+// @Tags         subscriptions
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        format               query     string  false  "Формат выгрузки (по умолчанию csv)"  Enums(csv, ndjson)
+// @Param        user_id              query     string  false  "UUID пользователя"
+// @Param        service_name         query     string  false  "Название сервиса или синоним"
+// @Param        service_name_prefix  query     string  false  "Начало названия сервиса без учёта регистра"
+// @Param        price_min            query     int     false  "Минимальная цена включительно"
+// @Param        price_max            query     int     false  "Максимальная цена включительно"
+// @Param        active_in            query     string  false  "Подписка действует в месяце (MM-YYYY)"
+// @Param        start_from           query     string  false  "Начало подписки не раньше (MM-YYYY)"
+// @Param        start_to             query     string  false  "Начало подписки не позже (MM-YYYY)"
+// @Param        has_end_date         query     bool    false  "Наличие даты окончания"
+// @Param        include_deleted      query     bool    false  "Включать удалённые подписки (по умолчанию false)"
+// @Param        sort                 query     string  false  "Сортировка" Enums(price, -price, start_date, service_name)
+// @Success      200                  {string}  string  "CSV с заголовком или NDJSON с объектами SubscriptionResponse"
+// @Failure      400                  {object}  ErrorResponse
+// @Failure      401                  {object}  ErrorResponse
+// @Failure      403                  {object}  ErrorResponse
+// @Failure      500                  {object}  ErrorResponse
+// @Router       /subscriptions/export [get]
+func (h *Handler) ExportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	format, err := parseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidExportFormat)
+		return
+	}
+
+	filter, err := h.parseListFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Ошибку игнорируем: без поддержки дедлайнов действует таймаут сервера
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+	out := newRecordWriter(w, format, "subscriptions", subscriptionExportColumns)
+	err = h.business.ExportSubscriptions(r.Context(), filter, func(sub *domain.Subscription) error {
+		resp := h.toSubscriptionResponse(sub)
+		return out.write(subscriptionRecord(&resp), &resp)
+	})
+	if err == nil {
+		err = out.close()
+	}
+	if err == nil {
+		return
+	}
+	if !out.started {
+		h.handleBusinessError(w, err)
+		return
+	}
+	// Статус уже отправлен: обрываем соединение, чтобы клиент не принял неполный файл за целый
+	panic(http.ErrAbortHandler)
+}
+
+// ExportMonthlyCost выгружает стоимость подписок по месяцам в CSV или NDJSON
+// @Summary      Выгрузка стоимости по месяцам
+// @Description  Возвращает то же, что GET /subscriptions/cost/monthly, в виде CSV или NDJSON
+// @Tags         subscriptions
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        format         query     string  false  "Формат выгрузки (по умолчанию csv)"  Enums(csv, ndjson)
+// @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
+// @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
+// @Param        user_id        query     string  false  "UUID пользователя"
+// @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Success      200            {string}  string  "CSV с колонками month,total_cost,count или NDJSON с объектами MonthlyCostResponse"
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /subscriptions/cost/monthly/export [get]
+func (h *Handler) ExportMonthlyCost(w http.ResponseWriter, r *http.Request) {
+	format, err := parseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidExportFormat)
+		return
+	}
+
+	filter, err := h.parseCostFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	months, err := h.business.CalculateMonthlyCost(r.Context(), filter)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	out := newRecordWriter(w, format, "monthly_cost", monthlyCostExportColumns)
+	for _, month := range h.toMonthlyCostListResponse(months) {
+		record := []string{month.Month, strconv.FormatInt(month.TotalCost, 10), strconv.FormatInt(month.Count, 10)}
+		if err := out.write(record, &month); err != nil {
+			return
+		}
+	}
+	out.close()
+}
+
+// subscriptionRecord переводит подписку в строку CSV в порядке subscriptionExportColumns
+func subscriptionRecord(resp *SubscriptionResponse) []string {
+	introPrice := ""
+	if resp.IntroPrice != nil {
+		introPrice = strconv.FormatInt(int64(*resp.IntroPrice), 10)
+	}
+	endDate := ""
+	if resp.EndDate != nil {
+		endDate = *resp.EndDate
+	}
+
+	return []string{
+		strconv.FormatInt(resp.ID, 10),
+		strconv.FormatInt(resp.ServiceID, 10),
+		resp.ServiceName,
+		strconv.FormatInt(int64(resp.Price), 10),
+		resp.Currency,
+		resp.BillingPeriod,
+		resp.Status,
+		strconv.FormatInt(int64(resp.TrialMonths), 10),
+		introPrice,
+		strconv.FormatInt(int64(resp.IntroMonths), 10),
+		resp.UserID.String(),
+		resp.StartDate,
+		endDate,
+		resp.CreatedAt,
+	}
+}
+
+// recordWriter пишет выгрузку построчно: CSV с заголовком или NDJSON.
+// Заголовки ответа отправляются с первой строкой, чтобы до неё ещё можно было ответить ошибкой.
+type recordWriter struct {
+	w       http.ResponseWriter
+	format  exportFormat
+	name    string
+	columns []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+func newRecordWriter(w http.ResponseWriter, format exportFormat, name string, columns []string) *recordWriter {
+	return &recordWriter{
+		w:       w,
+		format:  format,
+		name:    name,
+		columns: columns,
+	}
+}
+
+// write пишет одну строку: record для CSV или value для NDJSON
+func (rw *recordWriter) write(record []string, value any) error {
+	if err := rw.start(); err != nil {
+		return err
+	}
+
+	if rw.format == exportFormatNDJSON {
+		return rw.json.Encode(value)
+	}
+	return rw.csv.Write(record)
+}
+
+// close дописывает буферизованные строки; пустая выгрузка состоит из одного заголовка CSV
+func (rw *recordWriter) close() error {
+	if err := rw.start(); err != nil {
+		return err
+	}
+
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	return nil
+}
+
+func (rw *recordWriter) start() error {
+	if rw.started {
+		return nil
+	}
+	rw.started = true
+
+	contentType := "text/csv; charset=utf-8"
+	if rw.format == exportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	rw.w.Header().Set("Content-Type", contentType)
+	rw.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, rw.name, rw.format))
+	rw.w.WriteHeader(http.StatusOK)
+
+	if rw.format == exportFormatNDJSON {
+		rw.json = json.NewEncoder(rw.w)
+		return nil
+	}
+	rw.csv = csv.NewWriter(rw.w)
+	return rw.csv.Write(rw.columns)
+}
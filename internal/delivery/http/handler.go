@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Krokozabra213/effective_mobile/internal/auth"
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	"github.com/google/uuid"
 )
@@ -14,52 +16,163 @@ import (
 // Business defines business layer interface.
 type Business interface {
 	CreateSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) (*domain.Subscription, error)
+	CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error)
-	ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error)
-	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error)
+	ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) (*domain.SubscriptionPage, error)
+	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) (*domain.SubscriptionPage, error)
+	ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error
 	UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error)
-	DeleteSubscription(ctx context.Context, id int64) error
+	PauseSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error
+	RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
 	CalculateTotalCost(ctx context.Context, filter domain.CostFilter) (domain.TotalCost, error)
+	CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error)
+	CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error)
+	CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error)
+	BuildCostReport(ctx context.Context, filter domain.CostFilter) (*domain.CostReport, error)
+	ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error)
+	CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error)
+	GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id int64) error
+	CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error)
+	GetServiceByID(ctx context.Context, id int64) (*domain.Service, error)
+	ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error)
+	UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error)
+	DeleteService(ctx context.Context, id int64) error
+	ListSubscriptionHistory(ctx context.Context, id int64, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+	ListAuditEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+	BeginIdempotentRequest(ctx context.Context, key, requestHash string, ttl time.Duration) (*domain.IdempotentResponse, error)
+	CompleteIdempotentRequest(ctx context.Context, key string, response domain.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, key string) error
+	CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error)
+}
+
+// Authenticator verifies access tokens and issues them to service accounts.
+type Authenticator interface {
+	Authenticate(token string) (domain.Principal, error)
+	IssueServiceToken(clientID, clientSecret string) (auth.Token, error)
+}
+
+// Config holds handler settings.
+type Config struct {
+	// IdempotencyTTL is how long responses to requests with Idempotency-Key are replayed
+	IdempotencyTTL time.Duration
+	// MaxIdempotentBodyBytes limits bodies of requests with Idempotency-Key, which are read
+	// into memory to be hashed; CSV import is hashed while streaming and is not limited
+	MaxIdempotentBodyBytes int64
 }
 
 // Handler handles HTTP requests.
 type Handler struct {
-	business Business
+	business          Business
+	auth              Authenticator
+	idempotencyTTL    time.Duration
+	maxIdempotentBody int64
 }
 
 // New creates a new Handler and registers routes.
-func New(mux *http.ServeMux, business Business) *Handler {
+// All routes except token issuing and Swagger require a bearer token or an API key.
+// Wrap mux with WithMetrics to count requests per route pattern.
+func New(mux *http.ServeMux, business Business, authenticator Authenticator, cfg Config) *Handler {
 	h := &Handler{
-		business: business,
+		business:          business,
+		auth:              authenticator,
+		idempotencyTTL:    cfg.IdempotencyTTL,
+		maxIdempotentBody: cfg.MaxIdempotentBodyBytes,
 	}
 
-	// Subscriptions CRUD
-	mux.HandleFunc("POST /subscriptions", h.CreateSubscription)
-	mux.HandleFunc("GET /subscriptions/{id}", h.GetSubscriptionByID)
-	mux.HandleFunc("GET /subscriptions", h.ListSubscriptions)
-	mux.HandleFunc("PATCH /subscriptions/{id}", h.UpdateSubscription)
-	mux.HandleFunc("DELETE /subscriptions/{id}", h.DeleteSubscription)
-	mux.HandleFunc("GET /subscriptions/cost", h.CalculateTotalCost)
-
-	// User subscriptions
-	mux.HandleFunc("GET /users/{user_id}/subscriptions", h.ListSubscriptionsByUserID)
+	// Authentication
+	mux.HandleFunc("POST /auth/token", h.IssueToken)
 
 	// Swagger
 	mux.HandleFunc("GET /swagger/doc.json", h.SwaggerJSON)
 	mux.HandleFunc("GET /swagger/", h.SwaggerUI)
 
+	api := http.NewServeMux()
+	mux.Handle("/", routePattern(api, h.authenticate(api)))
+
+	// Subscriptions CRUD
+	api.HandleFunc("POST /subscriptions", h.idempotent(h.CreateSubscription))
+	api.HandleFunc("POST /subscriptions/batch", h.idempotent(h.CreateSubscriptionsBatch))
+	api.HandleFunc("POST /subscriptions/import", h.idempotentStream(h.ImportSubscriptions))
+	api.HandleFunc("GET /subscriptions/export", h.ExportSubscriptions)
+	api.HandleFunc("GET /subscriptions/{id}", h.GetSubscriptionByID)
+	api.HandleFunc("GET /subscriptions", h.ListSubscriptions)
+	api.HandleFunc("PATCH /subscriptions/{id}", h.UpdateSubscription)
+	api.HandleFunc("DELETE /subscriptions/{id}", h.DeleteSubscription)
+	api.HandleFunc("GET /subscriptions/{id}/prices", h.ListSubscriptionPrices)
+	api.HandleFunc("POST /subscriptions/{id}/pause", h.idempotent(h.PauseSubscription))
+	api.HandleFunc("POST /subscriptions/{id}/resume", h.idempotent(h.ResumeSubscription))
+	api.HandleFunc("POST /subscriptions/{id}/cancel", h.idempotent(h.CancelSubscription))
+	api.HandleFunc("POST /subscriptions/{id}/restore", h.idempotent(h.RestoreSubscription))
+	api.HandleFunc("GET /subscriptions/{id}/history", h.ListSubscriptionHistory)
+	api.HandleFunc("GET /subscriptions/cost", h.CalculateTotalCost)
+	api.HandleFunc("GET /subscriptions/cost/monthly", h.CalculateMonthlyCost)
+	api.HandleFunc("GET /subscriptions/cost/monthly/export", h.ExportMonthlyCost)
+	api.HandleFunc("GET /subscriptions/cost/grouped", h.CalculateGroupedCost)
+	api.HandleFunc("GET /subscriptions/cost/report", h.CostReport)
+
+	// Services catalog
+	api.HandleFunc("POST /services", h.idempotent(h.CreateService))
+	api.HandleFunc("GET /services", h.ListServices)
+	api.HandleFunc("GET /services/{id}", h.GetServiceByID)
+	api.HandleFunc("PATCH /services/{id}", h.UpdateService)
+	api.HandleFunc("DELETE /services/{id}", h.DeleteService)
+
+	// Exchange rates
+	api.HandleFunc("POST /exchange-rates", h.idempotent(h.CreateExchangeRate))
+	api.HandleFunc("GET /exchange-rates", h.ListExchangeRates)
+	api.HandleFunc("GET /exchange-rates/{id}", h.GetExchangeRateByID)
+	api.HandleFunc("PATCH /exchange-rates/{id}", h.UpdateExchangeRate)
+	api.HandleFunc("DELETE /exchange-rates/{id}", h.DeleteExchangeRate)
+
+	// Organization
+	api.HandleFunc("GET /organization/cost", h.CalculateOrganizationCost)
+
+	// Audit log
+	api.HandleFunc("GET /audit", h.ListAuditEvents)
+
+	// API keys. Creation is not idempotent: a replayed response would store the key.
+	api.HandleFunc("POST /api-keys", h.CreateAPIKey)
+	api.HandleFunc("GET /api-keys", h.ListAPIKeys)
+	api.HandleFunc("DELETE /api-keys/{id}", h.RevokeAPIKey)
+
+	// User subscriptions
+	api.HandleFunc("GET /users/{user_id}/subscriptions", h.ListSubscriptionsByUserID)
+
 	return h
 }
 
 // CreateSubscription создаёт новую подписку
 // @Summary      Создать подписку
-// @Description  Создаёт новую подписку для пользователя
+// @Description  Создаёт новую подписку для пользователя. Сервис задаётся service_id или названием: название
+// @Description  ищется в каталоге с учётом синонимов, неизвестное добавляется как новый сервис, если у
+// @Description  клиента есть право catalog:write, иначе возвращается 422. Без price используется цена
+// @Description  сервиса по умолчанию.
+// @Description  trial_months — бесплатные месяцы от начала подписки, intro_price действует следующие intro_months месяцев.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CreateSubscriptionRequest  true  "Данные подписки"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        request          body    CreateSubscriptionRequest  true   "Данные подписки"
+// @Param        Idempotency-Key  header  string                     false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
 // @Success      201      {object}  SubscriptionResponse
+// @Header       201      {string}  ETag  "Версия подписки"
 // @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      413      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /subscriptions [post]
 func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
@@ -68,47 +181,37 @@ func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
 		return
 	}
-	if err := req.Validate(); err != nil {
-		h.respondError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	startDate, err := parseMonthYear(req.StartDate)
+	input, err := h.toCreateSubscriptionInput(req)
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, ErrInvalidDate)
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	var endDate *time.Time
-	if req.EndDate != nil {
-		parsed, err := parseMonthYear(*req.EndDate)
-		if err != nil {
-			h.respondError(w, http.StatusBadRequest, ErrInvalidDate)
-			return
-		}
-		endDate = &parsed
-	}
-
-	input := domain.NewCreateSubscriptionInput(req.ServiceName, req.Price, req.UserID, startDate, endDate)
-
 	sub, err := h.business.CreateSubscription(r.Context(), &input)
 	if err != nil {
 		h.handleBusinessError(w, err)
 		return
 	}
-	resp := h.toSubscriptionResponse(sub)
 
-	h.respondJSON(w, http.StatusCreated, &resp)
+	h.respondSubscription(w, http.StatusCreated, sub)
 }
 
 // GetSubscriptionByID получает подписку по ID
 // @Summary      Получить подписку
-// @Description  Возвращает подписку по её идентификатору
+// @Description  Возвращает подписку по её идентификатору. ETag ответа — версия подписки: с If-None-Match
+// @Description  и тем же ETag возвращается 304 без тела.
 // @Tags         subscriptions
 // @Produce      json
-// @Param        id   path      int  true  "ID подписки"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id             path      int     true   "ID подписки"
+// @Param        If-None-Match  header    string  false  "ETag известной клиенту версии"
 // @Success      200  {object}  SubscriptionResponse
+// @Header       200  {string}  ETag  "Версия подписки"
+// @Success      304  "Подписка не изменилась"
 // @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /subscriptions/{id} [get]
@@ -130,33 +233,68 @@ func (h *Handler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, h.toSubscriptionResponse(sub))
+	if etag := subscriptionETag(sub); noneMatch(r, etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.respondSubscription(w, http.StatusOK, sub)
 }
 
 // ListSubscriptions возвращает список всех подписок
 // @Summary      Список подписок
-// @Description  Возвращает список всех подписок с пагинацией
+// @Description  Возвращает подписки, подходящие под фильтры; по умолчанию новые первыми. Пагинация
+// @Description  по смещению (limit/offset) или по курсору: next_cursor ответа передаётся в cursor
+// @Description  следующего запроса. Курсор доступен только с сортировкой по умолчанию.
 // @Tags         subscriptions
 // @Produce      json
-// @Param        limit   query     int  false  "Лимит (по умолчанию 10, макс 100)"
-// @Param        offset  query     int  false  "Смещение (по умолчанию 0)"
-// @Success      200     {object}  ListSubscriptionsResponse
-// @Failure      500     {object}  ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        user_id              query     string  false  "UUID пользователя"
+// @Param        service_name         query     string  false  "Название сервиса или синоним"
+// @Param        service_name_prefix  query     string  false  "Начало названия сервиса без учёта регистра"
+// @Param        price_min            query     int     false  "Минимальная цена включительно"
+// @Param        price_max            query     int     false  "Максимальная цена включительно"
+// @Param        active_in            query     string  false  "Подписка действует в месяце (MM-YYYY)"
+// @Param        start_from           query     string  false  "Начало подписки не раньше (MM-YYYY)"
+// @Param        start_to             query     string  false  "Начало подписки не позже (MM-YYYY)"
+// @Param        has_end_date         query     bool    false  "Наличие даты окончания"
+// @Param        include_deleted      query     bool    false  "Включать удалённые подписки (по умолчанию false)"
+// @Param        sort                 query     string  false  "Сортировка" Enums(price, -price, start_date, service_name)
+// @Param        limit                query     int     false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset               query     int     false  "Смещение (по умолчанию 0), игнорируется при cursor"
+// @Param        cursor               query     string  false  "Курсор следующей страницы из next_cursor"
+// @Success      200                  {object}  ListSubscriptionsResponse
+// @Header       200                  {string}  Link  "Ссылки на соседние страницы (RFC 8288): rel=next, rel=prev"
+// @Failure      400                  {object}  ErrorResponse
+// @Failure      401                  {object}  ErrorResponse
+// @Failure      403                  {object}  ErrorResponse
+// @Failure      500                  {object}  ErrorResponse
 // @Router       /subscriptions [get]
 func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
-	params := h.parsePagination(r)
+	params, err := h.parseSubscriptionPagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCursor)
+		return
+	}
 
-	subs, err := h.business.ListSubscriptions(r.Context(), params)
+	filter, err := h.parseListFilter(r)
 	if err != nil {
-		h.handleBusinessError(w, err)
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response := map[string]any{
-		"subscriptions": h.toSubscriptionListResponse(subs),
+	page, err := h.business.ListSubscriptions(r.Context(), filter, params)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
 	}
 
-	h.respondJSON(w, http.StatusOK, response)
+	resp := h.toListSubscriptionsResponse(page, params, filter.Sort)
+	h.setPaginationLinks(w, r, resp, params)
+
+	h.respondJSON(w, http.StatusOK, resp)
 }
 
 // ListSubscriptionsByUserID возвращает подписки пользователя
@@ -164,11 +302,17 @@ func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
 // @Description  Возвращает список подписок конкретного пользователя
 // @Tags         users
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        user_id  path      string  true  "UUID пользователя"
 // @Param        limit    query     int     false  "Лимит (по умолчанию 10, макс 100)"
-// @Param        offset   query     int     false  "Смещение (по умолчанию 0)"
+// @Param        offset   query     int     false  "Смещение (по умолчанию 0), игнорируется при cursor"
+// @Param        cursor   query     string  false  "Курсор следующей страницы из next_cursor"
 // @Success      200      {object}  ListSubscriptionsResponse
+// @Header       200      {string}  Link  "Ссылки на соседние страницы (RFC 8288): rel=next, rel=prev"
 // @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /users/{user_id}/subscriptions [get]
 func (h *Handler) ListSubscriptionsByUserID(w http.ResponseWriter, r *http.Request) {
@@ -179,32 +323,44 @@ func (h *Handler) ListSubscriptionsByUserID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	params := h.parsePagination(r)
+	params, err := h.parseSubscriptionPagination(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCursor)
+		return
+	}
 
-	subs, err := h.business.ListSubscriptionsByUserID(r.Context(), userID, params)
+	page, err := h.business.ListSubscriptionsByUserID(r.Context(), userID, params)
 	if err != nil {
 		h.handleBusinessError(w, err)
 		return
 	}
 
-	response := map[string]any{
-		"subscriptions": h.toSubscriptionListResponse(subs),
-	}
+	resp := h.toListSubscriptionsResponse(page, params, domain.SubscriptionSortDefault)
+	h.setPaginationLinks(w, r, resp, params)
 
-	h.respondJSON(w, http.StatusOK, response)
+	h.respondJSON(w, http.StatusOK, resp)
 }
 
 // UpdateSubscription обновляет подписку
 // @Summary      Обновить подписку
-// @Description  Частично обновляет подписку по ID. Все поля опциональны.
+// @Description  Частично обновляет подписку по ID. Все поля опциональны. С If-Match подписка обновляется,
+// @Description  только если её текущий ETag совпадает, иначе возвращается 412.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
-// @Param        id       path      int                        true  "ID подписки"
-// @Param        request  body      UpdateSubscriptionRequest   true  "Поля для обновления"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path      int                        true   "ID подписки"
+// @Param        If-Match  header    string                     false  "ETag версии, которую клиент изменяет"
+// @Param        request   body      UpdateSubscriptionRequest  true   "Поля для обновления"
 // @Success      200      {object}  SubscriptionResponse
+// @Header       200      {string}  ETag  "Новая версия подписки"
 // @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
 // @Failure      404      {object}  ErrorResponse
+// @Failure      412      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /subscriptions/{id} [patch]
 func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
@@ -214,6 +370,12 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var req UpdateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
@@ -221,8 +383,28 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := domain.UpdateSubscriptionInput{
+		ServiceID:   req.ServiceID,
 		ServiceName: req.ServiceName,
 		Price:       req.Price,
+		IfVersion:   ifVersion,
+	}
+
+	if req.Currency != nil {
+		currency := strings.ToUpper(*req.Currency)
+		if !domain.ValidCurrency(currency) {
+			h.respondError(w, http.StatusBadRequest, ErrCurrency.Error())
+			return
+		}
+		input.Currency = &currency
+	}
+
+	if req.BillingPeriod != nil {
+		billingPeriod := domain.BillingPeriod(*req.BillingPeriod)
+		if !billingPeriod.Valid() {
+			h.respondError(w, http.StatusBadRequest, ErrBillingPeriod.Error())
+			return
+		}
+		input.BillingPeriod = &billingPeriod
 	}
 
 	if req.EndDate != nil {
@@ -240,17 +422,94 @@ func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, h.toSubscriptionResponse(sub))
+	h.respondSubscription(w, http.StatusOK, sub)
+}
+
+// PauseSubscription приостанавливает подписку
+// @Summary      Приостановить подписку
+// @Description  Переводит активную подписку в статус paused; начиная с текущего месяца она не учитывается в стоимости
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id               path    int     true   "ID подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/{id}/pause [post]
+func (h *Handler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionStatus(w, r, h.business.PauseSubscription)
+}
+
+// ResumeSubscription возобновляет подписку
+// @Summary      Возобновить подписку
+// @Description  Переводит приостановленную подписку в статус active начиная с текущего месяца
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id               path    int     true   "ID подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/{id}/resume [post]
+func (h *Handler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionStatus(w, r, h.business.ResumeSubscription)
+}
+
+// CancelSubscription отменяет подписку
+// @Summary      Отменить подписку
+// @Description  Переводит активную или приостановленную подписку в статус cancelled; текущий месяц становится последним
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id               path    int     true   "ID подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/{id}/cancel [post]
+func (h *Handler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionStatus(w, r, h.business.CancelSubscription)
 }
 
 // DeleteSubscription удаляет подписку
 // @Summary      Удалить подписку
-// @Description  Удаляет подписку по ID
+// @Description  Помечает подписку удалённой: она пропадает из выборок и расчётов стоимости, но до
+// @Description  окончательной очистки по сроку хранения её можно восстановить через POST /subscriptions/{id}/restore.
+// @Description  С If-Match подписка удаляется, только если её текущий ETag совпадает, иначе возвращается 412.
 // @Tags         subscriptions
-// @Param        id   path  int  true  "ID подписки"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id        path    int     true   "ID подписки"
+// @Param        If-Match  header  string  false  "ETag версии, которую клиент удаляет"
 // @Success      204  "Подписка удалена"
 // @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
+// @Failure      412  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /subscriptions/{id} [delete]
 func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
@@ -260,7 +519,13 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.business.DeleteSubscription(r.Context(), id); err != nil {
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.business.DeleteSubscription(r.Context(), id, ifVersion); err != nil {
 		h.handleBusinessError(w, err)
 		return
 	}
@@ -268,55 +533,200 @@ func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreSubscription восстанавливает удалённую подписку
+// @Summary      Восстановить подписку
+// @Description  Снимает пометку удаления, пока подписка не очищена по сроку хранения
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id               path    int     true   "ID подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/{id}/restore [post]
+func (h *Handler) RestoreSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscriptionStatus(w, r, h.business.RestoreSubscription)
+}
+
+// ListSubscriptionPrices возвращает историю цен подписки
+// @Summary      История цен подписки
+// @Description  Возвращает цены подписки с месяцами, с которых они действуют, по возрастанию месяца
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "ID подписки"
+// @Success      200  {object}  ListSubscriptionPricesResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /subscriptions/{id}/prices [get]
+func (h *Handler) ListSubscriptionPrices(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	prices, err := h.business.ListSubscriptionPrices(r.Context(), id)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"prices": h.toSubscriptionPriceListResponse(prices),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
 // CalculateTotalCost рассчитывает суммарную стоимость подписок
 // @Summary      Рассчитать стоимость
 // @Description  Рассчитывает суммарную стоимость подписок за период с опциональной фильтрацией по пользователю и сервису
 // @Tags         subscriptions
 // @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
 // @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
 // @Param        user_id        query     string  false  "UUID пользователя"
 // @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Param        mode           query     string  false  "Режим расчёта: prorated — цена × месяцы в периоде, overlap — цена один раз (по умолчанию overlap)"  Enums(prorated, overlap)
 // @Success      200            {object}  TotalCostResponse
 // @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
 // @Failure      500            {object}  ErrorResponse
 // @Router       /subscriptions/cost [get]
 func (h *Handler) CalculateTotalCost(w http.ResponseWriter, r *http.Request) {
-	startPeriod, err := parseMonthYear(r.URL.Query().Get("start_period"))
+	filter, err := h.parseCostFilter(r)
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, ErrInvalidDate)
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	endPeriod, err := parseMonthYear(r.URL.Query().Get("end_period"))
+	filter.Mode, err = parseCostMode(r.URL.Query().Get("mode"))
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, ErrInvalidDate)
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCostMode)
 		return
 	}
 
-	filter := domain.CostFilter{
-		StartPeriod: startPeriod,
-		EndPeriod:   endPeriod,
+	result, err := h.business.CalculateTotalCost(r.Context(), filter)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
 	}
 
-	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			h.respondError(w, http.StatusBadRequest, ErrInvalidUserIDFormat)
-			return
-		}
-		filter.UserID = &userID
+	h.respondJSON(w, http.StatusOK, NewTotalCostResponse(result.TotalCost, result.Count))
+}
+
+// CalculateMonthlyCost рассчитывает стоимость подписок по месяцам
+// @Summary      Стоимость по месяцам
+// @Description  Возвращает стоимость и количество активных подписок для каждого месяца периода
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
+// @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
+// @Param        user_id        query     string  false  "UUID пользователя"
+// @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Success      200            {object}  MonthlyCostListResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /subscriptions/cost/monthly [get]
+func (h *Handler) CalculateMonthlyCost(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseCostFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if serviceName := r.URL.Query().Get("service_name"); serviceName != "" {
-		filter.ServiceName = &serviceName
+	months, err := h.business.CalculateMonthlyCost(r.Context(), filter)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
 	}
 
-	result, err := h.business.CalculateTotalCost(r.Context(), filter)
+	h.respondJSON(w, http.StatusOK, MonthlyCostListResponse{Months: h.toMonthlyCostListResponse(months)})
+}
+
+// CalculateGroupedCost рассчитывает стоимость подписок в разрезе сервисов или пользователей
+// @Summary      Стоимость по группам
+// @Description  Возвращает top-N сервисов или пользователей по суммарной стоимости подписок за период
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
+// @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
+// @Param        group_by       query     string  true   "Поле группировки"  Enums(service_name, user_id)
+// @Param        limit          query     int     false  "Количество групп (по умолчанию 10, макс 100)"
+// @Param        user_id        query     string  false  "UUID пользователя"
+// @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Param        mode           query     string  false  "Режим расчёта (по умолчанию overlap)"  Enums(prorated, overlap)
+// @Success      200            {object}  CostGroupListResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /subscriptions/cost/grouped [get]
+func (h *Handler) CalculateGroupedCost(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseCostFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter.Mode, err = parseCostMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCostMode)
+		return
+	}
+
+	groupBy, err := parseCostGroupBy(r.URL.Query().Get("group_by"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidGroupBy)
+		return
+	}
+
+	params := domain.CostGroupParams{
+		GroupBy: groupBy,
+		Limit:   h.parsePagination(r).Limit,
+	}
+
+	groups, err := h.business.CalculateGroupedCost(r.Context(), filter, params)
 	if err != nil {
 		h.handleBusinessError(w, err)
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, NewTotalCostResponse(result.TotalCost, result.Count))
+	h.respondJSON(w, http.StatusOK, CostGroupListResponse{Groups: h.toCostGroupListResponse(groups)})
 }
@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Krokozabra213/effective_mobile/internal/business"
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
 )
 
 // ErrorResponse ответ с ошибкой
@@ -28,12 +33,20 @@ func (h *Handler) respondError(w http.ResponseWriter, status int, message string
 
 func (h *Handler) toSubscriptionResponse(sub *domain.Subscription) SubscriptionResponse {
 	resp := SubscriptionResponse{
-		ID:          sub.ID,
-		ServiceName: sub.ServiceName,
-		Price:       sub.Price,
-		UserID:      sub.UserID,
-		StartDate:   formatMonthYear(sub.StartDate),
-		CreatedAt:   sub.CreatedAt.Format(time.RFC3339),
+		ID:            sub.ID,
+		ServiceID:     sub.ServiceID,
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price,
+		Currency:      sub.Currency,
+		BillingPeriod: string(sub.BillingPeriod),
+		Status:        string(sub.Status),
+		TrialMonths:   sub.Intro.TrialMonths,
+		IntroPrice:    sub.Intro.IntroPrice,
+		IntroMonths:   sub.Intro.IntroMonths,
+		UserID:        sub.UserID,
+		StartDate:     formatMonthYear(sub.StartDate),
+		CreatedAt:     sub.CreatedAt.Format(time.RFC3339),
+		Version:       sub.Version,
 	}
 
 	if sub.EndDate != nil {
@@ -41,6 +54,11 @@ func (h *Handler) toSubscriptionResponse(sub *domain.Subscription) SubscriptionR
 		resp.EndDate = &formatted
 	}
 
+	if sub.DeletedAt != nil {
+		formatted := sub.DeletedAt.Format(time.RFC3339)
+		resp.DeletedAt = &formatted
+	}
+
 	return resp
 }
 
@@ -49,6 +67,30 @@ func (h *Handler) parseID(r *http.Request, param string) (int64, error) {
 	return strconv.ParseInt(idStr, 10, 64)
 }
 
+// changeSubscriptionStatus выполняет переход подписки {id} в другой статус
+func (h *Handler) changeSubscriptionStatus(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, id int64) (*domain.Subscription, error),
+) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	sub, err := change(r.Context(), id)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondSubscription(w, http.StatusOK, sub)
+}
+
 func (h *Handler) parsePagination(r *http.Request) domain.ListParams {
 	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
 	offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 32)
@@ -69,6 +111,269 @@ func (h *Handler) parsePagination(r *http.Request) domain.ListParams {
 	}
 }
 
+// toCreateSubscriptionInput проверяет запрос на создание подписки и переводит его
+// во входные данные бизнес-логики. Текст ошибки пригоден для ответа клиенту.
+func (h *Handler) toCreateSubscriptionInput(req CreateSubscriptionRequest) (domain.CreateSubscriptionInput, error) {
+	if err := req.Validate(); err != nil {
+		return domain.CreateSubscriptionInput{}, err
+	}
+
+	startDate, err := parseMonthYear(req.StartDate)
+	if err != nil {
+		return domain.CreateSubscriptionInput{}, errors.New(ErrInvalidDate)
+	}
+
+	var endDate *time.Time
+	if req.EndDate != nil {
+		parsed, err := parseMonthYear(*req.EndDate)
+		if err != nil {
+			return domain.CreateSubscriptionInput{}, errors.New(ErrInvalidDate)
+		}
+		endDate = &parsed
+	}
+
+	billingPeriod := domain.BillingPeriodMonthly
+	if req.BillingPeriod != "" {
+		billingPeriod = domain.BillingPeriod(req.BillingPeriod)
+	}
+
+	currency := domain.BaseCurrency
+	if req.Currency != "" {
+		currency = strings.ToUpper(req.Currency)
+	}
+
+	intro := domain.IntroTerms{
+		TrialMonths: req.TrialMonths,
+		IntroPrice:  req.IntroPrice,
+		IntroMonths: req.IntroMonths,
+	}
+
+	input := domain.NewCreateSubscriptionInput(req.ServiceName, req.Price, currency, billingPeriod, intro, req.UserID, startDate, endDate)
+	if req.ServiceID != nil {
+		input.ServiceID = *req.ServiceID
+	}
+
+	return input, nil
+}
+
+// parseSubscriptionPagination парсит пагинацию списков подписок: limit/offset
+// или непрозрачный курсор cursor из next_cursor предыдущей страницы
+func (h *Handler) parseSubscriptionPagination(r *http.Request) (domain.ListParams, error) {
+	params := h.parsePagination(r)
+
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		cursor, err := decodeCursor(token)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = &cursor
+		params.Offset = 0
+	}
+
+	return params, nil
+}
+
+// encodeCursor кодирует курсор в непрозрачную строку для клиента
+func encodeCursor(cursor domain.Cursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixMicro(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (domain.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return domain.Cursor{}, err
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return domain.Cursor{}, errors.New("malformed cursor")
+	}
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return domain.Cursor{}, err
+	}
+	cursorID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return domain.Cursor{}, err
+	}
+
+	return domain.Cursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: cursorID}, nil
+}
+
+// parseCostFilter парсит общие параметры расчёта стоимости: период, user_id и service_name.
+// Текст ошибки пригоден для ответа клиенту.
+func (h *Handler) parseCostFilter(r *http.Request) (domain.CostFilter, error) {
+	query := r.URL.Query()
+
+	startPeriod, err := parseMonthYear(query.Get("start_period"))
+	if err != nil {
+		return domain.CostFilter{}, errors.New(ErrInvalidDate)
+	}
+
+	endPeriod, err := parseMonthYear(query.Get("end_period"))
+	if err != nil {
+		return domain.CostFilter{}, errors.New(ErrInvalidDate)
+	}
+
+	filter := domain.CostFilter{
+		StartPeriod: startPeriod,
+		EndPeriod:   endPeriod,
+	}
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return domain.CostFilter{}, errors.New(ErrInvalidUserIDFormat)
+		}
+		filter.UserID = &userID
+	}
+
+	if serviceName := query.Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
+	}
+
+	if currency := query.Get("currency"); currency != "" {
+		filter.Currency = strings.ToUpper(currency)
+		if !domain.ValidCurrency(filter.Currency) {
+			return domain.CostFilter{}, errors.New(ErrInvalidCurrency)
+		}
+	}
+
+	if amortize := query.Get("amortize"); amortize != "" {
+		filter.Amortize, err = strconv.ParseBool(amortize)
+		if err != nil {
+			return domain.CostFilter{}, errors.New(ErrInvalidAmortize)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseListFilter парсит фильтры и сортировку списка подписок.
+// Текст ошибки пригоден для ответа клиенту.
+func (h *Handler) parseListFilter(r *http.Request) (domain.ListFilter, error) {
+	query := r.URL.Query()
+
+	filter := domain.ListFilter{
+		Sort: domain.SubscriptionSort(query.Get("sort")),
+	}
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return domain.ListFilter{}, errors.New(ErrInvalidUserIDFormat)
+		}
+		filter.UserID = &userID
+	}
+
+	if serviceName := query.Get("service_name"); serviceName != "" {
+		filter.ServiceName = &serviceName
+	}
+	if prefix, ok := query["service_name_prefix"]; ok {
+		filter.ServiceNamePrefix = &prefix[0]
+	}
+
+	var err error
+	if filter.PriceMin, err = parseOptionalInt32(query.Get("price_min")); err != nil {
+		return domain.ListFilter{}, errors.New(ErrInvalidPriceFilter)
+	}
+	if filter.PriceMax, err = parseOptionalInt32(query.Get("price_max")); err != nil {
+		return domain.ListFilter{}, errors.New(ErrInvalidPriceFilter)
+	}
+
+	if filter.ActiveIn, err = parseOptionalMonthYear(query.Get("active_in")); err != nil {
+		return domain.ListFilter{}, errors.New(ErrInvalidDate)
+	}
+	if filter.StartFrom, err = parseOptionalMonthYear(query.Get("start_from")); err != nil {
+		return domain.ListFilter{}, errors.New(ErrInvalidDate)
+	}
+	if filter.StartTo, err = parseOptionalMonthYear(query.Get("start_to")); err != nil {
+		return domain.ListFilter{}, errors.New(ErrInvalidDate)
+	}
+
+	if value := query.Get("has_end_date"); value != "" {
+		hasEndDate, err := strconv.ParseBool(value)
+		if err != nil {
+			return domain.ListFilter{}, errors.New(ErrInvalidHasEndDate)
+		}
+		filter.HasEndDate = &hasEndDate
+	}
+
+	if value := query.Get("include_deleted"); value != "" {
+		if filter.IncludeDeleted, err = strconv.ParseBool(value); err != nil {
+			return domain.ListFilter{}, errors.New(ErrInvalidIncludeDeleted)
+		}
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) toMonthlyCostListResponse(months []domain.MonthlyCost) []MonthlyCostResponse {
+	result := make([]MonthlyCostResponse, len(months))
+	for i, m := range months {
+		result[i] = NewMonthlyCostResponse(formatMonthYear(m.Month), m.TotalCost, m.Count)
+	}
+	return result
+}
+
+func (h *Handler) toCostGroupListResponse(groups []domain.CostGroup) []CostGroupResponse {
+	result := make([]CostGroupResponse, len(groups))
+	for i, g := range groups {
+		result[i] = NewCostGroupResponse(g.Key, g.TotalCost, g.Count)
+	}
+	return result
+}
+
+func (h *Handler) toSubscriptionPriceListResponse(prices []domain.SubscriptionPrice) []SubscriptionPriceResponse {
+	result := make([]SubscriptionPriceResponse, len(prices))
+	for i, price := range prices {
+		result[i] = SubscriptionPriceResponse{
+			Price:         price.Price,
+			EffectiveFrom: formatMonthYear(price.EffectiveFrom),
+			CreatedAt:     price.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return result
+}
+
+func (h *Handler) toServiceResponse(service *domain.Service) ServiceResponse {
+	return ServiceResponse{
+		ID:           service.ID,
+		Name:         service.Name,
+		Aliases:      service.Aliases,
+		Category:     service.Category,
+		DefaultPrice: service.DefaultPrice,
+		CreatedAt:    service.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) toServiceListResponse(services []domain.Service) []ServiceResponse {
+	result := make([]ServiceResponse, len(services))
+	for i, service := range services {
+		result[i] = h.toServiceResponse(&service)
+	}
+	return result
+}
+
+func (h *Handler) toExchangeRateResponse(rate *domain.ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		ID:        rate.ID,
+		Currency:  rate.Currency,
+		Month:     formatMonthYear(rate.Month),
+		Rate:      rate.Rate,
+		CreatedAt: rate.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) toExchangeRateListResponse(rates []domain.ExchangeRate) []ExchangeRateResponse {
+	result := make([]ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		result[i] = h.toExchangeRateResponse(&rate)
+	}
+	return result
+}
+
 func (h *Handler) toSubscriptionListResponse(subs []domain.Subscription) []SubscriptionResponse {
 	result := make([]SubscriptionResponse, len(subs))
 	for i, sub := range subs {
@@ -77,10 +382,99 @@ func (h *Handler) toSubscriptionListResponse(subs []domain.Subscription) []Subsc
 	return result
 }
 
+func (h *Handler) toListSubscriptionsResponse(page *domain.SubscriptionPage, params domain.ListParams,
+	sort domain.SubscriptionSort,
+) ListSubscriptionsResponse {
+	resp := ListSubscriptionsResponse{
+		Subscriptions: h.toSubscriptionListResponse(page.Subscriptions),
+		Total:         page.Total,
+		Limit:         params.Limit,
+		Offset:        params.Offset,
+		HasMore:       page.HasMore,
+	}
+
+	if page.HasMore && sort.SupportsCursor() {
+		if cursor := domain.NextCursor(page.Subscriptions, params.Limit); cursor != nil {
+			next := encodeCursor(*cursor)
+			resp.NextCursor = &next
+		}
+	}
+
+	return resp
+}
+
+// setPaginationLinks выставляет заголовок Link (RFC 8288) со ссылками next и prev.
+// Ссылки сохраняют параметры запроса и продолжают тот же режим пагинации:
+// по курсору, если он передан, иначе по смещению. В режиме курсора prev нет.
+func (h *Handler) setPaginationLinks(w http.ResponseWriter, r *http.Request, resp ListSubscriptionsResponse, params domain.ListParams) {
+	links := []string{}
+	limit := strconv.Itoa(int(params.Limit))
+
+	if resp.HasMore {
+		next := r.URL.Query()
+		next.Set("limit", limit)
+		if params.Cursor != nil && resp.NextCursor != nil {
+			next.Set("cursor", *resp.NextCursor)
+			next.Del("offset")
+		} else {
+			next.Del("cursor")
+			next.Set("offset", strconv.Itoa(int(params.Offset+params.Limit)))
+		}
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+
+	if params.Cursor == nil && params.Offset > 0 {
+		prev := r.URL.Query()
+		prev.Set("limit", limit)
+		prev.Set("offset", strconv.Itoa(int(max(params.Offset-params.Limit, 0))))
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="prev"`, r.URL.Path, prev.Encode()))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
 func (h *Handler) handleBusinessError(w http.ResponseWriter, err error) {
 	switch {
+	case errors.Is(err, business.ErrForbidden):
+		h.respondError(w, http.StatusForbidden, business.ErrForbidden.Error())
 	case errors.Is(err, business.ErrNotFound):
 		h.respondError(w, http.StatusNotFound, "subscription not found")
+	case errors.Is(err, business.ErrServiceNotFound):
+		h.respondError(w, http.StatusNotFound, business.ErrServiceNotFound.Error())
+	case errors.Is(err, business.ErrServiceExists):
+		h.respondError(w, http.StatusConflict, business.ErrServiceExists.Error())
+	case errors.Is(err, business.ErrServiceInUse):
+		h.respondError(w, http.StatusConflict, business.ErrServiceInUse.Error())
+	case errors.Is(err, business.ErrInvalidFilter):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, business.ErrNotDeleted):
+		h.respondError(w, http.StatusConflict, business.ErrNotDeleted.Error())
+	case errors.Is(err, business.ErrVersionMismatch):
+		h.respondError(w, http.StatusPreconditionFailed, business.ErrVersionMismatch.Error())
+	case errors.Is(err, business.ErrIdempotencyKeyReused):
+		h.respondError(w, http.StatusUnprocessableEntity, business.ErrIdempotencyKeyReused.Error())
+	case errors.Is(err, business.ErrIdempotencyKeyInProgress):
+		h.respondError(w, http.StatusConflict, business.ErrIdempotencyKeyInProgress.Error())
+	case errors.Is(err, business.ErrInvalidTransition):
+		// Сообщение содержит текущий и запрошенный статусы
+		h.respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, business.ErrPriceRequired):
+		h.respondError(w, http.StatusBadRequest, business.ErrPriceRequired.Error())
+	case errors.Is(err, business.ErrUnknownService):
+		h.respondError(w, http.StatusUnprocessableEntity, business.ErrUnknownService.Error())
+	case errors.Is(err, business.ErrExchangeRateNotFound):
+		h.respondError(w, http.StatusNotFound, business.ErrExchangeRateNotFound.Error())
+	case errors.Is(err, business.ErrExchangeRateExists):
+		h.respondError(w, http.StatusConflict, business.ErrExchangeRateExists.Error())
+	case errors.Is(err, business.ErrAPIKeyNotFound):
+		h.respondError(w, http.StatusNotFound, business.ErrAPIKeyNotFound.Error())
+	case errors.Is(err, business.ErrInvalidAPIKeyInput):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, business.ErrMissingExchangeRate):
+		// Сообщение содержит список недостающих курсов
+		h.respondError(w, http.StatusUnprocessableEntity, err.Error())
 	default:
 		h.respondError(w, http.StatusInternalServerError, "internal error")
 	}
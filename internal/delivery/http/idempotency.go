@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+
+	"github.com/Krokozabra213/effective_mobile/internal/business"
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLen     = 255
+)
+
+// idempotentHeaders заголовки ответа, которые сохраняются и повторяются вместе с телом
+var idempotentHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotent повторяет ответ на запрос с заголовком Idempotency-Key, если запрос с этим
+// ключом уже выполнялся. Ключ с другим запросом отклоняется с 422, ключ выполняющегося
+// запроса — с 409. Ответы 5xx не сохраняются, чтобы запрос можно было повторить.
+// Тело запроса с ключом читается в память, чтобы сверить его с первым запросом до
+// выполнения; тело больше лимита отклоняется с 413.
+func (h *Handler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return h.withIdempotency(next, false)
+}
+
+// idempotentStream работает как idempotent, но не читает тело в память: обработчик читает
+// его потоком, а хэш считается по ходу чтения. Повтор запроса сверяется с первым после
+// чтения всего тела. Подходит для больших тел, например импорта CSV.
+func (h *Handler) idempotentStream(next http.HandlerFunc) http.HandlerFunc {
+	return h.withIdempotency(next, true)
+}
+
+func (h *Handler) withIdempotency(next http.HandlerFunc, stream bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(headerIdempotencyKey)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			h.respondError(w, http.StatusBadRequest, ErrInvalidIdempotencyKey)
+			return
+		}
+
+		// Ключи разных субъектов не пересекаются; ключи организаций разделяет репозиторий
+		if principal, ok := domain.PrincipalFrom(r.Context()); ok {
+			key = string(principal.Kind) + ":" + principal.Subject + ":" + key
+		}
+
+		// При потоковом чтении хэш запроса пуст до конца тела
+		bodyHash := newRequestHash(r)
+		var requestHash string
+		if !stream {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxIdempotentBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				h.respondError(w, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+				return
+			}
+			if err != nil {
+				h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			bodyHash.Write(body)
+			requestHash = hex.EncodeToString(bodyHash.Sum(nil))
+		}
+
+		stored, err := h.business.BeginIdempotentRequest(r.Context(), key, requestHash, h.idempotencyTTL)
+		if err != nil {
+			h.handleBusinessError(w, err)
+			return
+		}
+		if stored != nil {
+			if stream {
+				if _, err := io.Copy(bodyHash, r.Body); err != nil {
+					h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+					return
+				}
+				if stored.RequestHash != hex.EncodeToString(bodyHash.Sum(nil)) {
+					h.handleBusinessError(w, business.ErrIdempotencyKeyReused)
+					return
+				}
+			}
+			replayResponse(w, stored)
+			return
+		}
+
+		// Ключ освобождается и при панике обработчика
+		rec := &recordingWriter{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if !completed {
+				h.business.ReleaseIdempotentRequest(context.WithoutCancel(r.Context()), key)
+			}
+		}()
+
+		body := r.Body
+		if stream {
+			// Остаток тела дочитывается после ответа обработчика, чтобы хэш покрыл весь запрос.
+			// HTTP/2 такое чтение разрешает всегда, поэтому ошибку можно не проверять.
+			http.NewResponseController(w).EnableFullDuplex()
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(body, bodyHash), body}
+		}
+
+		next(rec, r)
+
+		status := rec.statusCode()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		if stream {
+			if _, err := io.Copy(bodyHash, body); err != nil {
+				return
+			}
+			requestHash = hex.EncodeToString(bodyHash.Sum(nil))
+		}
+
+		response := domain.IdempotentResponse{
+			StatusCode:  status,
+			Header:      map[string][]string{},
+			Body:        rec.body.Bytes(),
+			RequestHash: requestHash,
+		}
+		for _, name := range idempotentHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				response.Header[name] = values
+			}
+		}
+
+		// Ответ уже отправлен клиенту: ошибку сохранения логирует бизнес-слой, а ключ освобождается
+		completed = h.business.CompleteIdempotentRequest(context.WithoutCancel(r.Context()), key, response) == nil
+	}
+}
+
+// newRequestHash начинает хэш, отличающий повтор запроса от другого запроса с тем же ключом;
+// тело запроса дописывается в него вызывающим кодом
+func newRequestHash(r *http.Request) hash.Hash {
+	sum := sha256.New()
+	io.WriteString(sum, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n")
+	return sum
+}
+
+// replayResponse отправляет сохранённый ответ
+func replayResponse(w http.ResponseWriter, response *domain.IdempotentResponse) {
+	for name, values := range response.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(headerIdempotentReplayed, "true")
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+}
+
+// recordingWriter копирует статус и тело ответа, передавая их клиенту
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap открывает исходный writer для http.ResponseController
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode возвращает отправленный статус; обработчик без ответа отвечает 200
+func (w *recordingWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// unmatchedRoute метка запросов, не совпавших ни с одним маршрутом
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics учитывает обработанные HTTP-запросы
+type HTTPMetrics interface {
+	ObserveHTTPRequest(route string, status int, duration time.Duration)
+}
+
+// routeKey ключ контекста, по которому routePattern передаёт шаблон защищённого маршрута
+type routeKey struct{}
+
+// WithMetrics учитывает запросы по шаблону маршрута и статусу ответа. next — ServeMux,
+// переданный в New: шаблоны открытых маршрутов он записывает в запрос сам,
+// шаблоны защищённых передаёт routePattern.
+func WithMetrics(next http.Handler, metrics HTTPMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		var route string
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, &route))
+		sw := &statusWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r)
+
+		if route == "" {
+			route = r.Pattern
+		}
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(route, sw.statusCode(), time.Since(start))
+	})
+}
+
+// routePattern сохраняет для WithMetrics шаблон маршрута mux, совпавший с запросом, ещё до
+// аутентификации: так и отклонённые запросы учитываются по своему маршруту
+func routePattern(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			if _, *route = mux.Handler(r); *route == "" {
+				*route = unmatchedRoute
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusWriter запоминает отправленный статус ответа
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap открывает исходный writer для http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode возвращает отправленный статус; обработчик без ответа отвечает 200
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	headerRequestID = "X-Request-ID"
+
+	// maxAuditHeaderLen ограничивает длину значений, попадающих в журнал из заголовков
+	maxAuditHeaderLen = 128
+)
+
+// WithRequestMeta добавляет в контекст запроса сведения для журнала изменений:
+// ID запроса из X-Request-ID (или новый, если заголовка нет). Автора изменений
+// добавляет аутентификация. ID запроса возвращается клиенту в заголовке ответа.
+func WithRequestMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := auditHeader(r, headerRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(headerRequestID, requestID)
+
+		ctx := domain.WithAuditMeta(r.Context(), domain.AuditMeta{RequestID: requestID})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// auditHeader возвращает значение заголовка без пробелов по краям; слишком длинное отбрасывается
+func auditHeader(r *http.Request, name string) string {
+	value := strings.TrimSpace(r.Header.Get(name))
+	if len(value) > maxAuditHeaderLen {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package handler
+
+import "net/http"
+
+// CalculateOrganizationCost рассчитывает стоимость подписок организации
+// @Summary      Стоимость организации
+// @Description  Рассчитывает суммарную стоимость подписок всех пользователей организации субъекта за период. Доступно субъектам с правом на подписки всех пользователей
+// @Tags         organization
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
+// @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
+// @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Param        mode           query     string  false  "Режим расчёта (по умолчанию overlap)"  Enums(prorated, overlap)
+// @Success      200            {object}  OrganizationCostResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /organization/cost [get]
+func (h *Handler) CalculateOrganizationCost(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseCostFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter.Mode, err = parseCostMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCostMode)
+		return
+	}
+
+	result, err := h.business.CalculateOrganizationCost(r.Context(), filter)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, NewOrganizationCostResponse(result))
+}
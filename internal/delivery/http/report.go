@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	reportSheetSummary       = "Summary"
+	reportSheetServices      = "By service"
+	reportSheetUsers         = "By user"
+	reportSheetSubscriptions = "Subscriptions"
+)
+
+// CostReport формирует XLSX отчёт о стоимости подписок за период
+// @Summary      XLSX отчёт о стоимости
+// @Description  Книга Excel с листами: Summary — итог как в GET /subscriptions/cost, By service и By user —
+// @Description  стоимость по всем сервисам и пользователям, Subscriptions — подписки, попавшие в период,
+// @Description  со стоимостью каждой.
+// @Tags         subscriptions
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        start_period   query     string  true   "Начало периода (MM-YYYY)"  example(01-2024)
+// @Param        end_period     query     string  true   "Конец периода (MM-YYYY)"   example(12-2024)
+// @Param        user_id        query     string  false  "UUID пользователя"
+// @Param        service_name   query     string  false  "Название сервиса"
+// @Param        amortize       query     bool    false  "Распределять цену по месяцам вместо списания в месяц продления"
+// @Param        currency       query     string  false  "Валюта результата (ISO 4217, по умолчанию RUB)"  example(USD)
+// @Param        mode           query     string  false  "Режим расчёта (по умолчанию overlap)"  Enums(prorated, overlap)
+// @Success      200            {file}    file
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      422            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /subscriptions/cost/report [get]
+func (h *Handler) CostReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseCostFilter(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter.Mode, err = parseCostMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidCostMode)
+		return
+	}
+
+	report, err := h.business.BuildCostReport(r.Context(), filter)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	book, err := buildCostReportWorkbook(report, filter)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	defer book.Close()
+
+	// Книга собирается в памяти целиком, чтобы ошибку можно было вернуть до отправки статуса
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	filename := fmt.Sprintf("cost_report_%s_%s.xlsx", formatMonthYear(filter.StartPeriod), formatMonthYear(filter.EndPeriod))
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
+
+// buildCostReportWorkbook раскладывает отчёт по листам книги
+func buildCostReportWorkbook(report *domain.CostReport, filter domain.CostFilter) (*excelize.File, error) {
+	book := excelize.NewFile()
+
+	currency := filter.Currency
+	if currency == "" {
+		currency = domain.BaseCurrency
+	}
+
+	summary := [][]any{
+		{"start_period", formatMonthYear(filter.StartPeriod)},
+		{"end_period", formatMonthYear(filter.EndPeriod)},
+		{"mode", string(filter.Mode)},
+		{"amortize", filter.Amortize},
+		{"currency", currency},
+		{"total_cost", report.Total.TotalCost},
+		{"count", report.Total.Count},
+	}
+	if filter.UserID != nil {
+		summary = append(summary, []any{"user_id", filter.UserID.String()})
+	}
+	if filter.ServiceName != nil {
+		summary = append(summary, []any{"service_name", *filter.ServiceName})
+	}
+
+	subscriptions := make([][]any, 0, len(report.Subscriptions))
+	for _, item := range report.Subscriptions {
+		sub := item.Subscription
+		var endDate any
+		if sub.EndDate != nil {
+			endDate = formatMonthYear(*sub.EndDate)
+		}
+		subscriptions = append(subscriptions, []any{
+			sub.ID, sub.ServiceName, sub.UserID.String(), sub.Price, sub.Currency, string(sub.BillingPeriod),
+			string(sub.Status), formatMonthYear(sub.StartDate), endDate, item.TotalCost,
+		})
+	}
+
+	sheets := []struct {
+		name   string
+		header []any
+		rows   [][]any
+	}{
+		{reportSheetSummary, nil, summary},
+		{reportSheetServices, []any{"service_name", "total_cost", "count"}, costGroupRows(report.Services)},
+		{reportSheetUsers, []any{"user_id", "total_cost", "count"}, costGroupRows(report.Users)},
+		{reportSheetSubscriptions, []any{
+			"id", "service_name", "user_id", "price", "currency", "billing_period",
+			"status", "start_date", "end_date", "total_cost",
+		}, subscriptions},
+	}
+
+	bold, err := book.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		book.Close()
+		return nil, err
+	}
+
+	for i, sheet := range sheets {
+		if err := writeReportSheet(book, i, sheet.name, sheet.header, sheet.rows, bold); err != nil {
+			book.Close()
+			return nil, fmt.Errorf("sheet %s: %w", sheet.name, err)
+		}
+	}
+
+	return book, nil
+}
+
+// writeReportSheet заполняет лист: заголовок жирным в первой строке, затем строки данных.
+// Первый лист книги переименовывается, остальные создаются.
+func writeReportSheet(book *excelize.File, index int, name string, header []any, rows [][]any, headerStyle int) error {
+	if index == 0 {
+		if err := book.SetSheetName(book.GetSheetName(0), name); err != nil {
+			return err
+		}
+	} else if _, err := book.NewSheet(name); err != nil {
+		return err
+	}
+
+	row := 1
+	if header != nil {
+		if err := book.SetSheetRow(name, "A1", &header); err != nil {
+			return err
+		}
+		end, err := excelize.CoordinatesToCellName(len(header), 1)
+		if err != nil {
+			return err
+		}
+		if err := book.SetCellStyle(name, "A1", end, headerStyle); err != nil {
+			return err
+		}
+		if err := book.SetPanes(name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+		row++
+	}
+
+	for _, values := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		if err := book.SetSheetRow(name, cell, &values); err != nil {
+			return err
+		}
+		row++
+	}
+
+	return nil
+}
+
+// costGroupRows переводит группы стоимости в строки листа
+func costGroupRows(groups []domain.CostGroup) [][]any {
+	rows := make([][]any, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, []any{g.Key, g.TotalCost, g.Count})
+	}
+	return rows
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// CreateService создаёт сервис каталога
+// @Summary      Создать сервис
+// @Description  Добавляет сервис в каталог с каноническим названием и синонимами
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        request          body    CreateServiceRequest  true   "Данные сервиса"
+// @Param        Idempotency-Key  header  string                false  "Ключ идемпотентности: повтор запроса с ним возвращает первый ответ"
+// @Success      201      {object}  ServiceResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      413      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /services [post]
+func (h *Handler) CreateService(w http.ResponseWriter, r *http.Request) {
+	var req CreateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := domain.NewCreateServiceInput(req.Name, req.Aliases, req.Category, req.DefaultPrice)
+
+	service, err := h.business.CreateService(r.Context(), &input)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+	resp := h.toServiceResponse(service)
+
+	h.respondJSON(w, http.StatusCreated, &resp)
+}
+
+// GetServiceByID получает сервис по ID
+// @Summary      Получить сервис
+// @Description  Возвращает сервис каталога по его идентификатору
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "ID сервиса"
+// @Success      200  {object}  ServiceResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /services/{id} [get]
+func (h *Handler) GetServiceByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	service, err := h.business.GetServiceByID(r.Context(), id)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toServiceResponse(service))
+}
+
+// ListServices возвращает список сервисов каталога
+// @Summary      Список сервисов
+// @Description  Возвращает сервисы каталога по алфавиту с пагинацией
+// @Tags         services
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        category  query     string  false  "Фильтр по категории"
+// @Param        limit     query     int     false  "Лимит (по умолчанию 10, макс 100)"
+// @Param        offset    query     int     false  "Смещение (по умолчанию 0)"
+// @Success      200       {object}  ListServicesResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /services [get]
+func (h *Handler) ListServices(w http.ResponseWriter, r *http.Request) {
+	params := h.parsePagination(r)
+
+	var filter domain.ServiceFilter
+	if category := r.URL.Query().Get("category"); category != "" {
+		filter.Category = &category
+	}
+
+	services, err := h.business.ListServices(r.Context(), filter, params)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"services": h.toServiceListResponse(services),
+	}
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// UpdateService обновляет сервис каталога
+// @Summary      Обновить сервис
+// @Description  Частично обновляет сервис. При переименовании подписки получают новое название, старое
+// @Description  остаётся синонимом; переданный список aliases заменяет текущий целиком.
+// @Tags         services
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id       path      int                   true  "ID сервиса"
+// @Param        request  body      UpdateServiceRequest  true  "Поля для обновления"
+// @Success      200      {object}  ServiceResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /services/{id} [patch]
+func (h *Handler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	var req UpdateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := domain.UpdateServiceInput{
+		Name:         req.Name,
+		Aliases:      req.Aliases,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+	}
+
+	service, err := h.business.UpdateService(r.Context(), id, input)
+	if err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, h.toServiceResponse(service))
+}
+
+// DeleteService удаляет сервис каталога
+// @Summary      Удалить сервис
+// @Description  Удаляет сервис, на который не ссылается ни одна подписка
+// @Tags         services
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path  int  true  "ID сервиса"
+// @Success      204  "Сервис удалён"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /services/{id} [delete]
+func (h *Handler) DeleteService(w http.ResponseWriter, r *http.Request) {
+	id, err := h.parseID(r, "id")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidIDFormat)
+		return
+	}
+
+	if id <= 0 {
+		h.respondError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	if err := h.business.DeleteService(r.Context(), id); err != nil {
+		h.handleBusinessError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
 )
 
 // ParseMonthYear парсит "07-2025" в time.Time
@@ -14,7 +17,87 @@ func parseMonthYear(s string) (time.Time, error) {
 	return t, nil
 }
 
+// parseOptionalInt32 парсит необязательный числовой параметр; пустая строка — nil
+func parseOptionalInt32(value string) (*int32, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	result := int32(parsed)
+	return &result, nil
+}
+
+// parseOptionalMonthYear парсит необязательный месяц MM-YYYY; пустая строка — nil
+func parseOptionalMonthYear(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := parseMonthYear(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseOptionalTimestamp парсит необязательную метку времени RFC 3339; пустая строка — nil
+func parseOptionalTimestamp(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// formatOptionalTimestamp форматирует время в RFC 3339; nil остаётся nil
+func formatOptionalTimestamp(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
 // FormatMonthYear форматирует time.Time в "07-2025"
 func formatMonthYear(t time.Time) string {
 	return t.Format("01-2006")
 }
+
+// parseCostMode парсит режим расчёта стоимости, по умолчанию overlap
+func parseCostMode(s string) (domain.CostMode, error) {
+	switch mode := domain.CostMode(s); mode {
+	case "":
+		return domain.CostModeOverlap, nil
+	case domain.CostModeProrated, domain.CostModeOverlap:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown cost mode %q", s)
+	}
+}
+
+// parseCostGroupBy парсит поле группировки стоимости
+func parseCostGroupBy(s string) (domain.CostGroupBy, error) {
+	switch groupBy := domain.CostGroupBy(s); groupBy {
+	case domain.CostGroupByServiceName, domain.CostGroupByUserID:
+		return groupBy, nil
+	default:
+		return "", fmt.Errorf("unknown group_by %q", s)
+	}
+}
+
+// parseExportFormat парсит формат выгрузки, по умолчанию csv
+func parseExportFormat(s string) (exportFormat, error) {
+	switch format := exportFormat(s); format {
+	case "":
+		return exportFormatCSV, nil
+	case exportFormatCSV, exportFormatNDJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q", s)
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey долгоживущий ключ машинного клиента. Сам ключ не хранится, Prefix — его
+// начало, по которому владелец узнаёт ключ.
+type APIKey struct {
+	ID             int64
+	Name           string
+	Prefix         string
+	Scopes         []Scope
+	UserID         *uuid.UUID // пользователь, к подпискам которого ограничен ключ без права users:all
+	CreatedBy      string
+	CreatedAt      time.Time
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+	RevokedAt      *time.Time
+	OrganizationID uuid.UUID
+}
+
+// Principal возвращает субъекта запроса, аутентифицированного ключом
+func (k *APIKey) Principal() Principal {
+	return Principal{
+		Subject:        APIKeySubject(k.ID),
+		Kind:           PrincipalAPIKey,
+		UserID:         k.UserID,
+		Scopes:         k.Scopes,
+		OrganizationID: k.OrganizationID,
+	}
+}
+
+// APIKeySubject субъект ключа с этим ID, под которым его изменения попадают в журнал
+func APIKeySubject(id int64) string {
+	return "api_key:" + strconv.FormatInt(id, 10)
+}
+
+// CreateAPIKeyInput данные нового ключа; ExpiresAt nil — бессрочный ключ
+type CreateAPIKeyInput struct {
+	Name      string
+	Scopes    []Scope
+	UserID    *uuid.UUID
+	ExpiresAt *time.Time
+	CreatedBy string
+	Prefix    string
+	KeyHash   string
+}
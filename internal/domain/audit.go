@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction вид изменения подписки в журнале
+type AuditAction string
+
+const (
+	AuditActionCreated  AuditAction = "created"
+	AuditActionUpdated  AuditAction = "updated"
+	AuditActionDeleted  AuditAction = "deleted"
+	AuditActionRestored AuditAction = "restored"
+	AuditActionPurged   AuditAction = "purged"
+)
+
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditActionCreated, AuditActionUpdated, AuditActionDeleted, AuditActionRestored, AuditActionPurged:
+		return true
+	}
+	return false
+}
+
+// AuditMeta сведения о запросе, записываемые в журнал вместе с изменением
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+}
+
+type auditMetaKey struct{}
+
+// WithAuditMeta сохраняет сведения для журнала в контексте запроса
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// AuditMetaFrom возвращает сведения для журнала из контекста; пустые, если их нет
+func AuditMetaFrom(ctx context.Context) AuditMeta {
+	meta, _ := ctx.Value(auditMetaKey{}).(AuditMeta)
+	return meta
+}
+
+// SubscriptionEvent запись журнала изменений подписки. Before и After — снимки
+// подписки до и после изменения в JSON; Before пуст у создания, After — у очистки.
+type SubscriptionEvent struct {
+	ID             int64
+	SubscriptionID int64
+	Action         AuditAction
+	Before         json.RawMessage
+	After          json.RawMessage
+	Actor          string
+	RequestID      string
+	CreatedAt      time.Time
+}
+
+// AuditFilter фильтр журнала изменений. To не включается в период.
+type AuditFilter struct {
+	SubscriptionID *int64
+	Action         *AuditAction
+	Actor          *string
+	RequestID      *string
+	From           *time.Time
+	To             *time.Time
+}
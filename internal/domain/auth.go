@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// PrincipalKind вид аутентифицированного субъекта
+type PrincipalKind string
+
+const (
+	PrincipalUser    PrincipalKind = "user"
+	PrincipalService PrincipalKind = "service"
+	PrincipalAPIKey  PrincipalKind = "api_key" // только ключи API, в JWT не допускается
+)
+
+// Valid сообщает, допустим ли вид субъекта в JWT
+func (k PrincipalKind) Valid() bool {
+	switch k {
+	case PrincipalUser, PrincipalService:
+		return true
+	}
+	return false
+}
+
+// Role роль субъекта, определяющая его права
+type Role string
+
+const (
+	RoleUser    Role = "user"    // свои подписки
+	RoleFinance Role = "finance" // чтение подписок и стоимости всех пользователей
+	RoleAdmin   Role = "admin"   // всё
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleFinance, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Scope право на группу операций
+type Scope string
+
+const (
+	ScopeSubscriptionsRead  Scope = "subscriptions:read"  // чтение подписок, их истории и стоимости, каталога сервисов и курсов валют
+	ScopeSubscriptionsWrite Scope = "subscriptions:write" // создание и изменение подписок
+	ScopeAllUsers           Scope = "users:all"           // доступ к подпискам всех пользователей, а не только своим
+	ScopeCatalogWrite       Scope = "catalog:write"       // изменение каталога сервисов и курсов валют
+	ScopeAuditRead          Scope = "audit:read"          // чтение журнала изменений всех подписок
+	ScopeAPIKeysManage      Scope = "api_keys:manage"     // выпуск и отзыв ключей API
+)
+
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeAllUsers, ScopeCatalogWrite, ScopeAuditRead, ScopeAPIKeysManage:
+		return true
+	}
+	return false
+}
+
+// roleScopes права ролей
+var roleScopes = map[Role][]Scope{
+	RoleUser:    {ScopeSubscriptionsRead, ScopeSubscriptionsWrite},
+	RoleFinance: {ScopeSubscriptionsRead, ScopeAllUsers, ScopeAuditRead},
+	RoleAdmin: {ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeAllUsers, ScopeCatalogWrite, ScopeAuditRead,
+		ScopeAPIKeysManage},
+}
+
+// Scopes возвращает права роли
+func (r Role) Scopes() []Scope {
+	return slices.Clone(roleScopes[r])
+}
+
+// Principal аутентифицированный субъект запроса: пользователь, сервисный аккаунт или
+// ключ API. У ключа API нет роли, права заданы при выпуске ключа. UserID задан, если
+// субъект — пользователь подписок; без права users:all субъект видит только подписки
+// этого пользователя. Права действуют только внутри организации субъекта.
+type Principal struct {
+	Subject        string
+	Kind           PrincipalKind
+	Role           Role
+	UserID         *uuid.UUID
+	Scopes         []Scope
+	OrganizationID uuid.UUID
+}
+
+// HasScope сообщает, есть ли у субъекта право scope
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Owns сообщает, доступны ли субъекту подписки пользователя userID
+func (p Principal) Owns(userID uuid.UUID) bool {
+	return p.HasScope(ScopeAllUsers) || (p.UserID != nil && *p.UserID == userID)
+}
+
+type principalKey struct{}
+
+// WithPrincipal сохраняет аутентифицированного субъекта в контексте запроса
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom возвращает аутентифицированного субъекта из контекста; false, если запрос не аутентифицирован
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+)
+
+// BaseCurrency валюта, в которой заданы курсы exchange_rates
+const BaseCurrency = "RUB"
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency проверяет формат кода валюты ISO 4217
+func ValidCurrency(code string) bool {
+	return currencyCodeRe.MatchString(code)
+}
+
+// ExchangeRate курс валюты к BaseCurrency на месяц
+type ExchangeRate struct {
+	ID        int64
+	Currency  string
+	Month     time.Time // первое число месяца
+	Rate      float64   // стоимость одной единицы Currency в BaseCurrency
+	CreatedAt time.Time
+}
+
+func NewExchangeRate(id int64, currency string, month time.Time, rate float64, createdAt time.Time) *ExchangeRate {
+	return &ExchangeRate{
+		ID:        id,
+		Currency:  currency,
+		Month:     month,
+		Rate:      rate,
+		CreatedAt: createdAt,
+	}
+}
+
+type CreateExchangeRateInput struct {
+	Currency string
+	Month    time.Time
+	Rate     float64
+}
+
+func NewCreateExchangeRateInput(currency string, month time.Time, rate float64) CreateExchangeRateInput {
+	return CreateExchangeRateInput{
+		Currency: currency,
+		Month:    month,
+		Rate:     rate,
+	}
+}
+
+// ExchangeRateFilter фильтр списка курсов
+type ExchangeRateFilter struct {
+	Currency *string
+}
+
+// MissingExchangeRate курс, которого не хватает для пересчёта стоимости
+type MissingExchangeRate struct {
+	Currency string
+	Month    time.Time
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// IdempotentResponse сохранённый ответ на первый запрос с ключом идемпотентности.
+// RequestHash — хэш запроса, на который дан ответ.
+type IdempotentResponse struct {
+	StatusCode  int
+	Header      map[string][]string
+	Body        []byte
+	RequestHash string
+}
+
+// IdempotencyKey ключ идемпотентности. RequestHash отличает повтор запроса от другого
+// запроса с тем же ключом. Response пуст, пока первый запрос выполняется.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	Response    *IdempotentResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationID организация данных, созданных до разделения по организациям,
+// и субъектов, у которых организация не указана
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Organization организация (подразделение). Подписки, журнал изменений, ключи API,
+// каталог сервисов и курсы валют организации недоступны остальным.
+type Organization struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// OrganizationCost стоимость подписок всех пользователей организации за период
+type OrganizationCost struct {
+	Organization Organization
+	TotalCost    int64
+	Count        int64 // подписки с начислениями в периоде
+	UserCount    int64 // пользователи с начислениями в периоде
+}
+
+type organizationKey struct{}
+
+// WithOrganization сохраняет организацию запроса в контексте. Репозиторий ограничивает
+// ей все запросы к данным организаций.
+func WithOrganization(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey{}, id)
+}
+
+// OrganizationFrom возвращает организацию запроса из контекста; false, если её нет
+func OrganizationFrom(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(organizationKey{}).(uuid.UUID)
+	return id, ok
+}
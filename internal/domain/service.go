@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Service сервис каталога. Подписки ссылаются на сервис по ID и хранят
+// его каноническое название Name.
+type Service struct {
+	ID           int64
+	Name         string
+	Aliases      []string // альтернативные названия, по которым находится сервис
+	Category     *string
+	DefaultPrice *int32 // цена подписки, если она не указана при создании
+	CreatedAt    time.Time
+}
+
+func NewService(id int64, name string, aliases []string, category *string, defaultPrice *int32, createdAt time.Time) *Service {
+	return &Service{
+		ID:           id,
+		Name:         name,
+		Aliases:      aliases,
+		Category:     category,
+		DefaultPrice: defaultPrice,
+		CreatedAt:    createdAt,
+	}
+}
+
+type CreateServiceInput struct {
+	Name         string
+	Aliases      []string
+	Category     *string
+	DefaultPrice *int32
+}
+
+func NewCreateServiceInput(name string, aliases []string, category *string, defaultPrice *int32) CreateServiceInput {
+	return CreateServiceInput{
+		Name:         name,
+		Aliases:      aliases,
+		Category:     category,
+		DefaultPrice: defaultPrice,
+	}
+}
+
+type UpdateServiceInput struct {
+	Name         *string
+	Aliases      []string // nil — не менять, пустой срез — удалить все
+	Category     *string
+	DefaultPrice *int32
+}
+
+// ServiceFilter фильтр списка сервисов
+type ServiceFilter struct {
+	Category *string
+}
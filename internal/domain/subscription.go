@@ -6,59 +6,183 @@ import (
 	"github.com/google/uuid"
 )
 
+// BillingPeriod периодичность списания цены подписки
+type BillingPeriod string
+
+const (
+	BillingPeriodWeekly    BillingPeriod = "weekly"
+	BillingPeriodMonthly   BillingPeriod = "monthly"
+	BillingPeriodQuarterly BillingPeriod = "quarterly"
+	BillingPeriodYearly    BillingPeriod = "yearly"
+)
+
+// Valid сообщает, известна ли периодичность
+func (p BillingPeriod) Valid() bool {
+	switch p {
+	case BillingPeriodWeekly, BillingPeriodMonthly, BillingPeriodQuarterly, BillingPeriodYearly:
+		return true
+	}
+	return false
+}
+
+// SubscriptionStatus состояние подписки
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive    SubscriptionStatus = "active"
+	SubscriptionStatusPaused    SubscriptionStatus = "paused"
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+	// SubscriptionStatusExpired не хранится: активная или приостановленная
+	// подписка истекает после месяца end_date
+	SubscriptionStatusExpired SubscriptionStatus = "expired"
+)
+
+// Valid сообщает, известен ли статус
+func (s SubscriptionStatus) Valid() bool {
+	switch s {
+	case SubscriptionStatusActive, SubscriptionStatusPaused, SubscriptionStatusCancelled, SubscriptionStatusExpired:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo сообщает, допустим ли переход в статус next:
+// active ⇄ paused, active и paused → cancelled
+func (s SubscriptionStatus) CanTransitionTo(next SubscriptionStatus) bool {
+	switch next {
+	case SubscriptionStatusPaused:
+		return s == SubscriptionStatusActive
+	case SubscriptionStatusActive:
+		return s == SubscriptionStatusPaused
+	case SubscriptionStatusCancelled:
+		return s == SubscriptionStatusActive || s == SubscriptionStatusPaused
+	}
+	return false
+}
+
+// SubscriptionStatusAt возвращает статус подписки на момент now с учётом истечения
+func SubscriptionStatusAt(stored SubscriptionStatus, end *time.Time, now time.Time) SubscriptionStatus {
+	if stored == SubscriptionStatusCancelled || end == nil {
+		return stored
+	}
+	if end.Before(MonthStart(now)) {
+		return SubscriptionStatusExpired
+	}
+	return stored
+}
+
+// MonthStart возвращает первое число месяца t в UTC
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// IntroTerms пробный период и вводная цена подписки. Месяцы отсчитываются
+// от начала подписки: первые TrialMonths бесплатны, следующие IntroMonths
+// оплачиваются по IntroPrice, дальше — по обычной цене.
+type IntroTerms struct {
+	TrialMonths int32
+	IntroPrice  *int32 // задана тогда и только тогда, когда IntroMonths > 0
+	IntroMonths int32
+}
+
 type Subscription struct {
-	ID          int64
-	ServiceName string
-	Price       int32
-	UserID      uuid.UUID
-	StartDate   time.Time
-	EndDate     *time.Time
-	CreatedAt   time.Time
+	ID            int64
+	ServiceID     int64
+	ServiceName   string // каноническое название сервиса
+	Price         int32
+	Currency      string // ISO 4217
+	BillingPeriod BillingPeriod
+	Status        SubscriptionStatus
+	Intro         IntroTerms
+	UserID        uuid.UUID
+	StartDate     time.Time
+	EndDate       *time.Time
+	CreatedAt     time.Time
+	DeletedAt     *time.Time // задано у удалённой подписки, которую ещё можно восстановить
+	Version       int32      // увеличивается при каждом изменении подписки
 }
 
-func NewSubscription(id int64, serviceName string, price int32, userID uuid.UUID, start time.Time,
-	end *time.Time, createdAt time.Time,
+func NewSubscription(id int64, serviceID int64, serviceName string, price int32, currency string, billingPeriod BillingPeriod,
+	status SubscriptionStatus, intro IntroTerms, userID uuid.UUID, start time.Time, end *time.Time, createdAt time.Time,
 ) *Subscription {
 	return &Subscription{
-		ID:          id,
-		ServiceName: serviceName,
-		Price:       price,
-		UserID:      userID,
-		StartDate:   start,
-		EndDate:     end,
-		CreatedAt:   createdAt,
+		ID:            id,
+		ServiceID:     serviceID,
+		ServiceName:   serviceName,
+		Price:         price,
+		Currency:      currency,
+		BillingPeriod: billingPeriod,
+		Status:        status,
+		Intro:         intro,
+		UserID:        userID,
+		StartDate:     start,
+		EndDate:       end,
+		CreatedAt:     createdAt,
 	}
 }
 
 type CreateSubscriptionInput struct {
-	ServiceName string
-	Price       int32
-	UserID      uuid.UUID
-	StartDate   time.Time
-	EndDate     *time.Time
+	ServiceID     int64 // 0 — сервис определяется по ServiceName
+	ServiceName   string
+	Price         int32 // 0 — цена по умолчанию из каталога
+	Currency      string
+	BillingPeriod BillingPeriod
+	Intro         IntroTerms
+	UserID        uuid.UUID
+	StartDate     time.Time
+	EndDate       *time.Time
 }
 
-func NewCreateSubscriptionInput(service string, price int32, userID uuid.UUID, start time.Time, end *time.Time) CreateSubscriptionInput {
+func NewCreateSubscriptionInput(service string, price int32, currency string, billingPeriod BillingPeriod,
+	intro IntroTerms, userID uuid.UUID, start time.Time, end *time.Time,
+) CreateSubscriptionInput {
 	return CreateSubscriptionInput{
-		ServiceName: service,
-		Price:       price,
-		UserID:      userID,
-		StartDate:   start,
-		EndDate:     end,
+		ServiceName:   service,
+		Price:         price,
+		Currency:      currency,
+		BillingPeriod: billingPeriod,
+		Intro:         intro,
+		UserID:        userID,
+		StartDate:     start,
+		EndDate:       end,
 	}
 }
 
+// BatchResult результат создания одной подписки пакета: Subscription при успехе,
+// Err при ошибке. Если атомарный пакет отменён, у подписок без ошибки оба поля пусты.
+type BatchResult struct {
+	Subscription *Subscription
+	Err          error
+}
+
 type UpdateSubscriptionInput struct {
-	ServiceName *string
-	Price       *int
-	EndDate     *time.Time
+	ServiceID     *int64
+	ServiceName   *string
+	Price         *int
+	Currency      *string
+	BillingPeriod *BillingPeriod
+	EndDate       *time.Time
+	IfVersion     *int32 // если задано, подписка обновляется только в этой версии
 }
 
+// CostMode определяет, как цена подписки учитывается в периоде; пустой режим — CostModeOverlap
+type CostMode string
+
+const (
+	// CostModeProrated — цена умножается на число месяцев подписки внутри периода
+	CostModeProrated CostMode = "prorated"
+	// CostModeOverlap — цена учитывается один раз, если подписка пересекает период
+	CostModeOverlap CostMode = "overlap"
+)
+
 type CostFilter struct {
 	StartPeriod time.Time // "01-2025"
 	EndPeriod   time.Time // "12-2025"
 	UserID      *uuid.UUID
 	ServiceName *string
+	Mode        CostMode
+	Amortize    bool   // распределять цену по месяцам вместо списания в месяц продления
+	Currency    string // валюта результата, пусто — BaseCurrency
 }
 
 type TotalCost struct {
@@ -66,7 +190,118 @@ type TotalCost struct {
 	Count     int64
 }
 
+// MonthlyCost стоимость подписок за один месяц периода
+type MonthlyCost struct {
+	Month     time.Time // первое число месяца
+	TotalCost int64
+	Count     int64
+}
+
+// CostGroupBy поле, по которому группируется стоимость
+type CostGroupBy string
+
+const (
+	CostGroupByServiceName CostGroupBy = "service_name"
+	CostGroupByUserID      CostGroupBy = "user_id"
+)
+
+// CostGroupParams параметры группировки стоимости
+type CostGroupParams struct {
+	GroupBy CostGroupBy
+	Limit   int32 // top-N групп по стоимости, 0 — все группы
+}
+
+// CostGroup стоимость подписок внутри одной группы
+type CostGroup struct {
+	Key       string
+	TotalCost int64
+	Count     int64
+}
+
+// SubscriptionCost подписка со стоимостью за период
+type SubscriptionCost struct {
+	Subscription Subscription
+	TotalCost    int64
+}
+
+// CostReport данные отчёта о стоимости подписок за период
+type CostReport struct {
+	Total         TotalCost
+	Services      []CostGroup // все сервисы по убыванию стоимости
+	Users         []CostGroup // все пользователи по убыванию стоимости
+	Subscriptions []SubscriptionCost
+}
+
+// SubscriptionSort порядок списка подписок
+type SubscriptionSort string
+
+const (
+	// SubscriptionSortDefault — новые первыми, по (created_at, id) по убыванию
+	SubscriptionSortDefault     SubscriptionSort = ""
+	SubscriptionSortPrice       SubscriptionSort = "price"
+	SubscriptionSortPriceDesc   SubscriptionSort = "-price"
+	SubscriptionSortStartDate   SubscriptionSort = "start_date"
+	SubscriptionSortServiceName SubscriptionSort = "service_name"
+)
+
+// Valid сообщает, известен ли порядок сортировки
+func (s SubscriptionSort) Valid() bool {
+	switch s {
+	case SubscriptionSortDefault, SubscriptionSortPrice, SubscriptionSortPriceDesc,
+		SubscriptionSortStartDate, SubscriptionSortServiceName:
+		return true
+	}
+	return false
+}
+
+// SupportsCursor сообщает, можно ли листать выдачу курсором. Курсор хранит
+// позицию по (created_at, id), поэтому работает только с порядком по умолчанию.
+func (s SubscriptionSort) SupportsCursor() bool {
+	return s == SubscriptionSortDefault
+}
+
+// ListFilter фильтры и сортировка списка подписок. Пустые поля не ограничивают выдачу.
+type ListFilter struct {
+	UserID            *uuid.UUID
+	ServiceName       *string    // точное название сервиса или его синоним
+	ServiceNamePrefix *string    // начало названия без учёта регистра
+	PriceMin          *int32     // включительно
+	PriceMax          *int32     // включительно
+	ActiveIn          *time.Time // подписка действует в этом месяце
+	StartFrom         *time.Time // start_date не раньше месяца
+	StartTo           *time.Time // start_date не позже месяца
+	HasEndDate        *bool
+	IncludeDeleted    bool // включать удалённые подписки
+	Sort              SubscriptionSort
+}
+
+// ListParams параметры пагинации. Если задан Cursor, Offset не используется и
+// выдача продолжается после записи курсора.
 type ListParams struct {
 	Limit  int32
 	Offset int32
+	Cursor *Cursor
+}
+
+// SubscriptionPage страница списка подписок
+type SubscriptionPage struct {
+	Subscriptions []Subscription
+	Total         int64 // всего подписок под фильтром без учёта пагинации
+	HasMore       bool  // после страницы есть ещё подписки
+}
+
+// Cursor позиция в списке подписок, упорядоченном по (created_at, id) по убыванию
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// NextCursor возвращает курсор следующей страницы: позицию последней подписки
+// полной страницы или nil, если страница неполная и дальше записей нет
+func NextCursor(subs []Subscription, limit int32) *Cursor {
+	if limit <= 0 || len(subs) < int(limit) {
+		return nil
+	}
+	last := subs[len(subs)-1]
+	return &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// SubscriptionPrice цена подписки, действующая с месяца EffectiveFrom
+// до следующей записи истории
+type SubscriptionPrice struct {
+	Price         int32
+	EffectiveFrom time.Time
+	CreatedAt     time.Time
+}
+
+func NewSubscriptionPrice(price int32, effectiveFrom time.Time, createdAt time.Time) *SubscriptionPrice {
+	return &SubscriptionPrice{
+		Price:         price,
+		EffectiveFrom: effectiveFrom,
+		CreatedAt:     createdAt,
+	}
+}
@@ -0,0 +1,75 @@
+// Package metrics собирает метрики приложения в формате Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics хранит реестр и метрики HTTP-запросов и бизнес-операций
+type Metrics struct {
+	registry          *prometheus.Registry
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	operationDuration *prometheus.HistogramVec
+}
+
+// New создаёт метрики приложения вместе с метриками среды выполнения Go и процесса
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by route pattern and status code.",
+		}, []string{"route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route pattern and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "business_operation_duration_seconds",
+			Help:    "Duration of business operations by operation name.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"op"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.operationDuration,
+	)
+	return m
+}
+
+// MustRegister добавляет в реестр коллекторы, например статистику пула соединений
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus. Ошибка одного коллектора
+// не скрывает остальные метрики.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ObserveHTTPRequest учитывает обработанный HTTP-запрос к маршруту route
+func (m *Metrics) ObserveHTTPRequest(route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(route, code).Inc()
+	m.httpDuration.WithLabelValues(route, code).Observe(duration.Seconds())
+}
+
+// ObserveOperation учитывает длительность бизнес-операции op
+func (m *Metrics) ObserveOperation(op string, duration time.Duration) {
+	m.operationDuration.WithLabelValues(op).Observe(duration.Seconds())
+}
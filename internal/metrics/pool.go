@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater отдаёт статистику пула соединений; реализуется *pgxpool.Pool и pgxclient.Client
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// poolCollector отдаёт статистику пула соединений PostgreSQL на момент сбора
+type poolCollector struct {
+	pool PoolStater
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	totalConns        *prometheus.Desc
+	constructingConns *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	emptyAcquireWait  *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+}
+
+// NewPoolCollector создаёт коллектор статистики пула соединений pool
+func NewPoolCollector(pool PoolStater) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+name, help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_connections", "Number of connections currently acquired from the pool."),
+		idleConns:         desc("idle_connections", "Number of idle connections in the pool."),
+		totalConns:        desc("total_connections", "Total number of connections in the pool."),
+		constructingConns: desc("constructing_connections", "Number of connections being established."),
+		maxConns:          desc("max_connections", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Number of successful acquires from the pool."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent on successful acquires from the pool."),
+		emptyAcquires:     desc("empty_acquires_total", "Number of acquires that waited for a connection because the pool was empty."),
+		emptyAcquireWait:  desc("empty_acquire_wait_seconds_total", "Total time acquires waited for a connection because the pool was empty."),
+		canceledAcquires:  desc("canceled_acquires_total", "Number of acquires cancelled by context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.constructingConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquires
+	ch <- c.emptyAcquireWait
+	ch <- c.canceledAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireWait, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// subscriptionsTimeout ограничивает время подсчёта подписок при сборе метрик
+const subscriptionsTimeout = 5 * time.Second
+
+// subscriptionStatuses статусы, которые отдаются всегда, даже без подписок
+var subscriptionStatuses = []domain.SubscriptionStatus{
+	domain.SubscriptionStatusActive,
+	domain.SubscriptionStatusPaused,
+	domain.SubscriptionStatusCancelled,
+	domain.SubscriptionStatusExpired,
+}
+
+// SubscriptionCounter подсчитывает неудалённые подписки всех организаций по статусам
+type SubscriptionCounter interface {
+	CountSubscriptionsByStatus(ctx context.Context) (map[domain.SubscriptionStatus]int64, error)
+}
+
+// subscriptionCollector отдаёт число подписок по статусам, запрашивая его при каждом сборе
+type subscriptionCollector struct {
+	counter SubscriptionCounter
+	desc    *prometheus.Desc
+}
+
+// NewSubscriptionCollector создаёт коллектор числа подписок по статусам
+func NewSubscriptionCollector(counter SubscriptionCounter) prometheus.Collector {
+	return &subscriptionCollector{
+		counter: counter,
+		desc: prometheus.NewDesc("subscriptions",
+			"Number of not deleted subscriptions across all organizations by status.", []string{"status"}, nil),
+	}
+}
+
+func (c *subscriptionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *subscriptionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), subscriptionsTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountSubscriptionsByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for _, status := range subscriptionStatuses {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
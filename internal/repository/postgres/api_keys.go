@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
+)
+
+// CreateAPIKey сохраняет новый ключ API
+func (r *PostgresRepository) CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, error) {
+	const op = "repository.CreateAPIKey"
+	log := slog.With(slog.String("op", op), slog.String("name", input.Name))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to create api key", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	scopes := make([]string, len(input.Scopes))
+	for i, scope := range input.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	result, err := r.Queries.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		Name:           input.Name,
+		Prefix:         input.Prefix,
+		KeyHash:        input.KeyHash,
+		Scopes:         scopes,
+		UserID:         input.UserID,
+		CreatedBy:      input.CreatedBy,
+		ExpiresAt:      input.ExpiresAt,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to create api key", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return apiKeyToDomain(&result), nil
+}
+
+// ListAPIKeys возвращает ключи API, новые первыми, включая отозванные и просроченные
+func (r *PostgresRepository) ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error) {
+	const op = "repository.ListAPIKeys"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	rows, err := r.Queries.ListAPIKeys(ctx, sqlc.ListAPIKeysParams{
+		OrganizationID: orgID,
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+	})
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	keys := make([]domain.APIKey, len(rows))
+	for i := range rows {
+		keys[i] = *apiKeyToDomain(&rows[i])
+	}
+
+	return keys, nil
+}
+
+// GetActiveAPIKeyByHash находит не отозванный и не просроченный ключ по хэшу в любой
+// организации: организация запроса определяется ключом. Политика RLS открывает ключ
+// по хэшу, переданному в app.api_key_hash на время транзакции.
+func (r *PostgresRepository) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	const op = "repository.GetActiveAPIKeyByHash"
+	log := slog.With(slog.String("op", op))
+
+	var result sqlc.ApiKey
+	err := r.withTx(ctx, func(tx *PostgresRepository) error {
+		if _, err := tx.DB.Exec(ctx, "SELECT set_config('app.api_key_hash', $1, true)", keyHash); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = tx.Queries.GetActiveAPIKeyByHash(ctx, keyHash)
+		return err
+	})
+	if err != nil {
+		log.Debug("failed to get api key", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return apiKeyToDomain(&result), nil
+}
+
+// RevokeAPIKey отзывает ключ API; уже отозванный ключ не найдётся
+func (r *PostgresRepository) RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	const op = "repository.RevokeAPIKey"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to revoke api key", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.RevokeAPIKey(ctx, sqlc.RevokeAPIKeyParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to revoke api key", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return apiKeyToDomain(&result), nil
+}
+
+// TouchAPIKey запоминает время последнего использования ключа организации из контекста
+func (r *PostgresRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	const op = "repository.TouchAPIKey"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to touch api key", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	err = r.Queries.TouchAPIKey(ctx, sqlc.TouchAPIKeyParams{
+		ID:             id,
+		OrganizationID: orgID,
+		UsedAt:         &usedAt,
+	})
+	if err != nil {
+		log.Error("failed to touch api key", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+func apiKeyToDomain(key *sqlc.ApiKey) *domain.APIKey {
+	scopes := make([]domain.Scope, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = domain.Scope(scope)
+	}
+
+	return &domain.APIKey{
+		ID:             key.ID,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         scopes,
+		UserID:         key.UserID,
+		CreatedBy:      key.CreatedBy,
+		CreatedAt:      key.CreatedAt,
+		ExpiresAt:      key.ExpiresAt,
+		LastUsedAt:     key.LastUsedAt,
+		RevokedAt:      key.RevokedAt,
+		OrganizationID: key.OrganizationID,
+	}
+}
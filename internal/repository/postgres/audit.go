@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ListSubscriptionEvents возвращает записи журнала изменений подписок, новые первыми
+func (r *PostgresRepository) ListSubscriptionEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error) {
+	const op = "repository.ListSubscriptionEvents"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list subscription events", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	args := sqlc.ListSubscriptionEventsParams{
+		OrganizationID: orgID,
+		SubscriptionID: filter.SubscriptionID,
+		Actor:          filter.Actor,
+		RequestID:      filter.RequestID,
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+	}
+	if filter.Action != nil {
+		args.Action = sqlc.NullSubscriptionEventAction{
+			SubscriptionEventAction: sqlc.SubscriptionEventAction(*filter.Action),
+			Valid:                   true,
+		}
+	}
+	if filter.From != nil {
+		args.CreatedFrom = pgtype.Timestamptz{Time: *filter.From, Valid: true}
+	}
+	if filter.To != nil {
+		args.CreatedTo = pgtype.Timestamptz{Time: *filter.To, Valid: true}
+	}
+
+	results, err := r.Queries.ListSubscriptionEvents(ctx, args)
+	if err != nil {
+		log.Error("failed to list subscription events", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	events := make([]domain.SubscriptionEvent, len(results))
+	for i, result := range results {
+		events[i] = eventToDomain(&result)
+	}
+
+	return events, nil
+}
+
+// mutateSubscription выполняет изменение подписки в транзакции и записывает его в журнал.
+// Строка подписки блокируется до изменения, чтобы снимок «до» соответствовал изменённой версии.
+// Если задан ifVersion, а версия подписки другая, изменение откатывается с ErrVersionMismatch.
+// Изменяется только подписка организации запроса, mutate получает эту организацию.
+func (r *PostgresRepository) mutateSubscription(
+	ctx context.Context,
+	id int64,
+	ifVersion *int32,
+	action domain.AuditAction,
+	mutate func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error),
+) (sqlc.Subscription, error) {
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		return sqlc.Subscription{}, err
+	}
+
+	var result sqlc.Subscription
+	err = r.withTx(ctx, func(tx *PostgresRepository) error {
+		before, err := tx.Queries.GetSubscriptionForUpdate(ctx, sqlc.GetSubscriptionForUpdateParams{
+			ID:             id,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = mutate(tx, orgID)
+		if err != nil {
+			return err
+		}
+
+		// Версия сверяется после изменения, чтобы его собственные ошибки (например, подписка
+		// уже удалена) имели приоритет над несовпадением версии
+		if ifVersion != nil && before.Version != *ifVersion {
+			return ErrVersionMismatch
+		}
+
+		return tx.recordSubscriptionEvent(ctx, id, action, &before, &result)
+	})
+
+	return result, err
+}
+
+// recordSubscriptionEvent записывает событие журнала со снимками подписки до и после
+// изменения. Автор и ID запроса берутся из контекста, организация — из снимка:
+// очистку выполняет фоновая задача без организации.
+func (r *PostgresRepository) recordSubscriptionEvent(ctx context.Context, id int64, action domain.AuditAction, before, after *sqlc.Subscription) error {
+	snapshot := after
+	if snapshot == nil {
+		snapshot = before
+	}
+
+	beforeJSON, err := subscriptionSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := subscriptionSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	meta := domain.AuditMetaFrom(ctx)
+	return r.Queries.CreateSubscriptionEvent(ctx, sqlc.CreateSubscriptionEventParams{
+		SubscriptionID: id,
+		Action:         sqlc.SubscriptionEventAction(action),
+		Before:         beforeJSON,
+		After:          afterJSON,
+		Actor:          optionalString(meta.Actor),
+		RequestID:      optionalString(meta.RequestID),
+		OrganizationID: snapshot.OrganizationID,
+	})
+}
+
+// subscriptionSnapshot сериализует подписку для журнала; nil — отсутствие снимка
+func subscriptionSnapshot(s *sqlc.Subscription) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// optionalString превращает пустую строку в NULL
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// eventToDomain конвертирует sqlc модель записи журнала в domain
+func eventToDomain(e *sqlc.SubscriptionEvent) domain.SubscriptionEvent {
+	event := domain.SubscriptionEvent{
+		ID:             e.ID,
+		SubscriptionID: e.SubscriptionID,
+		Action:         domain.AuditAction(e.Action),
+		Before:         e.Before,
+		After:          e.After,
+		CreatedAt:      e.CreatedAt,
+	}
+	if e.Actor != nil {
+		event.Actor = *e.Actor
+	}
+	if e.RequestID != nil {
+		event.RequestID = *e.RequestID
+	}
+	return event
+}
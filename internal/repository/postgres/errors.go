@@ -3,6 +3,9 @@ package postgres
 import "errors"
 
 var (
-	ErrNotFound = errors.New("subscription not found")
-	ErrInternal = errors.New("internal repository error")
+	ErrNotFound        = errors.New("subscription not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrInUse           = errors.New("referenced by other records")
+	ErrVersionMismatch = errors.New("version mismatch")
+	ErrInternal        = errors.New("internal repository error")
 )
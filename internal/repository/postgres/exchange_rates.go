@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
+)
+
+// CreateExchangeRate создаёт курс валюты на месяц
+func (r *PostgresRepository) CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error) {
+	const op = "repository.CreateExchangeRate"
+	log := slog.With(slog.String("op", op), slog.String("currency", input.Currency))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to create exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.CreateExchangeRate(ctx, sqlc.CreateExchangeRateParams{
+		Currency:       input.Currency,
+		Month:          input.Month,
+		Rate:           input.Rate,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to create exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.exchangeRateToDomain(&result), nil
+}
+
+// GetExchangeRateByID получает курс по ID
+func (r *PostgresRepository) GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error) {
+	const op = "repository.GetExchangeRateByID"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to get exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.GetExchangeRateByID(ctx, sqlc.GetExchangeRateByIDParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to get exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.exchangeRateToDomain(&result), nil
+}
+
+// ListExchangeRates возвращает список курсов с пагинацией
+func (r *PostgresRepository) ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error) {
+	const op = "repository.ListExchangeRates"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list exchange rates", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	results, err := r.Queries.ListExchangeRates(ctx, sqlc.ListExchangeRatesParams{
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+		OrganizationID: orgID,
+		Currency:       filter.Currency,
+	})
+	if err != nil {
+		log.Error("failed to list exchange rates", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	rates := make([]domain.ExchangeRate, len(results))
+	for i, result := range results {
+		rates[i] = *r.exchangeRateToDomain(&result)
+	}
+
+	return rates, nil
+}
+
+// UpdateExchangeRate обновляет значение курса
+func (r *PostgresRepository) UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error) {
+	const op = "repository.UpdateExchangeRate"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to update exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.UpdateExchangeRate(ctx, sqlc.UpdateExchangeRateParams{
+		ID:             id,
+		Rate:           rate,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to update exchange rate", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.exchangeRateToDomain(&result), nil
+}
+
+// DeleteExchangeRate удаляет курс
+func (r *PostgresRepository) DeleteExchangeRate(ctx context.Context, id int64) error {
+	const op = "repository.DeleteExchangeRate"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to delete exchange rate", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	rowsAffected, err := r.Queries.DeleteExchangeRate(ctx, sqlc.DeleteExchangeRateParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to delete exchange rate", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// exchangeRateToDomain конвертирует sqlc модель курса в domain
+func (r *PostgresRepository) exchangeRateToDomain(e *sqlc.ExchangeRate) *domain.ExchangeRate {
+	return domain.NewExchangeRate(e.ID, e.Currency, e.Month, e.Rate, e.CreatedAt)
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReserveIdempotencyKey занимает ключ идемпотентности организации до expiresAt. Если ключ
+// уже занят и не просрочен, возвращает его с reserved = false.
+func (r *PostgresRepository) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, expiresAt time.Time) (*domain.IdempotencyKey, bool, error) {
+	const op = "repository.ReserveIdempotencyKey"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to reserve idempotency key", slog.String("error", err.Error()))
+		return nil, false, r.handleError(err)
+	}
+
+	result, err := r.Queries.ReserveIdempotencyKey(ctx, sqlc.ReserveIdempotencyKeyParams{
+		Key:            key,
+		RequestHash:    requestHash,
+		ExpiresAt:      expiresAt,
+		OrganizationID: orgID,
+	})
+	if err == nil {
+		record, err := idempotencyKeyToDomain(&result)
+		return record, true, err
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		log.Error("failed to reserve idempotency key", slog.String("error", err.Error()))
+		return nil, false, r.handleError(err)
+	}
+
+	result, err = r.Queries.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{
+		Key:            key,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to get idempotency key", slog.String("error", err.Error()))
+		return nil, false, r.handleError(err)
+	}
+
+	record, err := idempotencyKeyToDomain(&result)
+	if err != nil {
+		log.Error("failed to decode idempotent response", slog.String("error", err.Error()))
+		return nil, false, ErrInternal
+	}
+
+	return record, false, nil
+}
+
+// SaveIdempotentResponse сохраняет ответ на запрос с ключом для повтора при ретраях
+func (r *PostgresRepository) SaveIdempotentResponse(ctx context.Context, key string, response domain.IdempotentResponse) error {
+	const op = "repository.SaveIdempotentResponse"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to save idempotent response", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		log.Error("failed to encode response header", slog.String("error", err.Error()))
+		return ErrInternal
+	}
+
+	statusCode := int32(response.StatusCode)
+	if err := r.Queries.SaveIdempotentResponse(ctx, sqlc.SaveIdempotentResponseParams{
+		Key:             key,
+		StatusCode:      &statusCode,
+		ResponseHeaders: header,
+		ResponseBody:    response.Body,
+		RequestHash:     response.RequestHash,
+		OrganizationID:  orgID,
+	}); err != nil {
+		log.Error("failed to save idempotent response", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey освобождает ключ, ответ на который не сохранён, чтобы запрос можно было повторить
+func (r *PostgresRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "repository.ReleaseIdempotencyKey"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to release idempotency key", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	if err := r.Queries.DeleteIdempotencyKey(ctx, sqlc.DeleteIdempotencyKeyParams{
+		Key:            key,
+		OrganizationID: orgID,
+	}); err != nil {
+		log.Error("failed to release idempotency key", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys удаляет ключи всех организаций, просроченные к моменту now.
+// Возвращает число удалённых ключей.
+func (r *PostgresRepository) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	const op = "repository.PurgeExpiredIdempotencyKeys"
+	log := slog.With(slog.String("op", op))
+
+	var purged int64
+	err := r.forEachOrganization(ctx, func(ctx context.Context, orgID uuid.UUID) error {
+		rowsAffected, err := r.Queries.PurgeExpiredIdempotencyKeys(ctx, sqlc.PurgeExpiredIdempotencyKeysParams{
+			OrganizationID: orgID,
+			Now:            now,
+		})
+		purged += rowsAffected
+		return err
+	})
+	if err != nil {
+		log.Error("failed to purge idempotency keys", slog.String("error", err.Error()))
+		return 0, r.handleError(err)
+	}
+
+	return purged, nil
+}
+
+// idempotencyKeyToDomain конвертирует sqlc модель ключа идемпотентности в domain
+func idempotencyKeyToDomain(k *sqlc.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	record := &domain.IdempotencyKey{
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		CreatedAt:   k.CreatedAt,
+		ExpiresAt:   k.ExpiresAt,
+	}
+	if k.StatusCode == nil {
+		return record, nil
+	}
+
+	record.Response = &domain.IdempotentResponse{
+		StatusCode:  int(*k.StatusCode),
+		Body:        k.ResponseBody,
+		RequestHash: k.RequestHash,
+	}
+	if len(k.ResponseHeaders) > 0 {
+		if err := json.Unmarshal(k.ResponseHeaders, &record.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// errNoOrganization запрос к данным организаций без организации в контексте
+var errNoOrganization = errors.New("organization is not set")
+
+// organizationID возвращает организацию запроса из контекста. Без неё запрос к данным
+// организаций не выполняется, чтобы ошибка вызывающего кода не открыла чужие данные.
+func organizationID(ctx context.Context) (uuid.UUID, error) {
+	id, ok := domain.OrganizationFrom(ctx)
+	if !ok {
+		return uuid.Nil, errNoOrganization
+	}
+	return id, nil
+}
+
+// PrepareConn передаёт организацию из контекста в настройку app.organization_id
+// соединения, выдаваемого пулом; на ней основаны политики RLS. Без организации
+// настройка сбрасывается, и политики не пропускают строк организаций.
+func PrepareConn(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	var setting string
+	if id, ok := domain.OrganizationFrom(ctx); ok {
+		setting = id.String()
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT set_config('app.organization_id', $1, false)", setting); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// forEachOrganization вызывает fn для каждой организации с её ID в контексте: так фоновые
+// задачи обрабатывают данные всех организаций, не обходя политики RLS
+func (r *PostgresRepository) forEachOrganization(ctx context.Context, fn func(ctx context.Context, orgID uuid.UUID) error) error {
+	ids, err := r.Queries.ListOrganizationIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := fn(domain.WithOrganization(ctx, id), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalculateOrganizationCost подсчитывает стоимость подписок всех пользователей организации за период
+func (r *PostgresRepository) CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error) {
+	const op = "repository.CalculateOrganizationCost"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to calculate organization cost", slog.String("error", err.Error()))
+		return domain.OrganizationCost{}, r.handleError(err)
+	}
+
+	filter.UserID = nil
+	cte, args := r.chargesCTE(orgID, filter)
+	args = append(args, orgID)
+	query := cte + fmt.Sprintf(`
+		SELECT o.id, o.name, COALESCE(ROUND(SUM(c.amount)), 0)::BIGINT AS total_cost,
+			COUNT(DISTINCT c.id)::BIGINT AS count, COUNT(DISTINCT c.user_id)::BIGINT AS user_count
+		FROM organizations o
+		LEFT JOIN charges c ON TRUE
+		WHERE o.id = $%d
+		GROUP BY o.id, o.name
+	`, len(args))
+
+	var result domain.OrganizationCost
+	if err := r.DB.QueryRow(ctx, query, args...).Scan(
+		&result.Organization.ID,
+		&result.Organization.Name,
+		&result.TotalCost,
+		&result.Count,
+		&result.UserCount,
+	); err != nil {
+		log.Error("failed to calculate organization cost", slog.String("error", err.Error()))
+		return domain.OrganizationCost{}, r.handleError(err)
+	}
+
+	return result, nil
+}
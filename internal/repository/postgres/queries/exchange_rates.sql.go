@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: exchange_rates.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createExchangeRate = `-- name: CreateExchangeRate :one
+INSERT INTO exchange_rates (
+    currency,
+    month,
+    rate,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, currency, month, rate, created_at, organization_id
+`
+
+type CreateExchangeRateParams struct {
+	Currency       string    `json:"currency"`
+	Month          time.Time `json:"month"`
+	Rate           float64   `json:"rate"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error) {
+	row := q.db.QueryRow(ctx, createExchangeRate,
+		arg.Currency,
+		arg.Month,
+		arg.Rate,
+		arg.OrganizationID,
+	)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Month,
+		&i.Rate,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const deleteExchangeRate = `-- name: DeleteExchangeRate :execrows
+DELETE FROM exchange_rates
+WHERE id = $1 AND organization_id = $2
+`
+
+type DeleteExchangeRateParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExchangeRate, arg.ID, arg.OrganizationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getExchangeRateByID = `-- name: GetExchangeRateByID :one
+SELECT id, currency, month, rate, created_at, organization_id
+FROM exchange_rates
+WHERE id = $1 AND organization_id = $2
+`
+
+type GetExchangeRateByIDParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) GetExchangeRateByID(ctx context.Context, arg GetExchangeRateByIDParams) (ExchangeRate, error) {
+	row := q.db.QueryRow(ctx, getExchangeRateByID, arg.ID, arg.OrganizationID)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Month,
+		&i.Rate,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const listExchangeRates = `-- name: ListExchangeRates :many
+SELECT id, currency, month, rate, created_at, organization_id
+FROM exchange_rates
+WHERE organization_id = $3
+    AND ($4::TEXT IS NULL OR currency = $4)
+ORDER BY month DESC, currency
+LIMIT $1 OFFSET $2
+`
+
+type ListExchangeRatesParams struct {
+	Limit          int32     `json:"limit"`
+	Offset         int32     `json:"offset"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Currency       *string   `json:"currency"`
+}
+
+func (q *Queries) ListExchangeRates(ctx context.Context, arg ListExchangeRatesParams) ([]ExchangeRate, error) {
+	rows, err := q.db.Query(ctx, listExchangeRates,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganizationID,
+		arg.Currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExchangeRate{}
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Month,
+			&i.Rate,
+			&i.CreatedAt,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateExchangeRate = `-- name: UpdateExchangeRate :one
+UPDATE exchange_rates
+SET rate = $2
+WHERE id = $1 AND organization_id = $3
+RETURNING id, currency, month, rate, created_at, organization_id
+`
+
+type UpdateExchangeRateParams struct {
+	ID             int64     `json:"id"`
+	Rate           float64   `json:"rate"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) UpdateExchangeRate(ctx context.Context, arg UpdateExchangeRateParams) (ExchangeRate, error) {
+	row := q.db.QueryRow(ctx, updateExchangeRate, arg.ID, arg.Rate, arg.OrganizationID)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Month,
+		&i.Rate,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
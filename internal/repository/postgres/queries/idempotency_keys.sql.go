@@ -0,0 +1,147 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: idempotency_keys.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND organization_id = $2 AND status_code IS NULL
+`
+
+type DeleteIdempotencyKeyParams struct {
+	Key            string    `json:"key"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, arg.Key, arg.OrganizationID)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, request_hash, status_code, response_headers, response_body, created_at, expires_at, organization_id
+FROM idempotency_keys
+WHERE key = $1 AND organization_id = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	Key            string    `json:"key"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Key, arg.OrganizationID)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ResponseHeaders,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const purgeExpiredIdempotencyKeys = `-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE organization_id = $1 AND expires_at <= $2
+`
+
+type PurgeExpiredIdempotencyKeysParams struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Now            time.Time `json:"now"`
+}
+
+func (q *Queries) PurgeExpiredIdempotencyKeys(ctx context.Context, arg PurgeExpiredIdempotencyKeysParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeExpiredIdempotencyKeys, arg.OrganizationID, arg.Now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :one
+INSERT INTO idempotency_keys (key, request_hash, expires_at, organization_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (organization_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response_headers = NULL,
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+RETURNING key, request_hash, status_code, response_headers, response_body, created_at, expires_at, organization_id
+`
+
+type ReserveIdempotencyKeyParams struct {
+	Key            string    `json:"key"`
+	RequestHash    string    `json:"request_hash"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+// Просроченный ключ, который ещё не очищен, занимается заново
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, reserveIdempotencyKey,
+		arg.Key,
+		arg.RequestHash,
+		arg.ExpiresAt,
+		arg.OrganizationID,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ResponseHeaders,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const saveIdempotentResponse = `-- name: SaveIdempotentResponse :exec
+UPDATE idempotency_keys
+SET request_hash = $5,
+    status_code = $2,
+    response_headers = $3,
+    response_body = $4
+WHERE key = $1 AND organization_id = $6
+`
+
+type SaveIdempotentResponseParams struct {
+	Key             string    `json:"key"`
+	StatusCode      *int32    `json:"status_code"`
+	ResponseHeaders []byte    `json:"response_headers"`
+	ResponseBody    []byte    `json:"response_body"`
+	RequestHash     string    `json:"request_hash"`
+	OrganizationID  uuid.UUID `json:"organization_id"`
+}
+
+// Хэш запроса, тело которого читалось потоком, становится известен только к ответу
+func (q *Queries) SaveIdempotentResponse(ctx context.Context, arg SaveIdempotentResponseParams) error {
+	_, err := q.db.Exec(ctx, saveIdempotentResponse,
+		arg.Key,
+		arg.StatusCode,
+		arg.ResponseHeaders,
+		arg.ResponseBody,
+		arg.RequestHash,
+		arg.OrganizationID,
+	)
+	return err
+}
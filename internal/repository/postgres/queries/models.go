@@ -5,17 +5,246 @@
 package sqlc
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type BillingPeriod string
+
+const (
+	BillingPeriodWeekly    BillingPeriod = "weekly"
+	BillingPeriodMonthly   BillingPeriod = "monthly"
+	BillingPeriodQuarterly BillingPeriod = "quarterly"
+	BillingPeriodYearly    BillingPeriod = "yearly"
+)
+
+func (e *BillingPeriod) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BillingPeriod(s)
+	case string:
+		*e = BillingPeriod(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BillingPeriod: %T", src)
+	}
+	return nil
+}
+
+type NullBillingPeriod struct {
+	BillingPeriod BillingPeriod `json:"billing_period"`
+	Valid         bool          `json:"valid"` // Valid is true if BillingPeriod is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBillingPeriod) Scan(value interface{}) error {
+	if value == nil {
+		ns.BillingPeriod, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BillingPeriod.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBillingPeriod) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BillingPeriod), nil
+}
+
+type SubscriptionEventAction string
+
+const (
+	SubscriptionEventActionCreated  SubscriptionEventAction = "created"
+	SubscriptionEventActionUpdated  SubscriptionEventAction = "updated"
+	SubscriptionEventActionDeleted  SubscriptionEventAction = "deleted"
+	SubscriptionEventActionRestored SubscriptionEventAction = "restored"
+	SubscriptionEventActionPurged   SubscriptionEventAction = "purged"
+)
+
+func (e *SubscriptionEventAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SubscriptionEventAction(s)
+	case string:
+		*e = SubscriptionEventAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SubscriptionEventAction: %T", src)
+	}
+	return nil
+}
+
+type NullSubscriptionEventAction struct {
+	SubscriptionEventAction SubscriptionEventAction `json:"subscription_event_action"`
+	Valid                   bool                    `json:"valid"` // Valid is true if SubscriptionEventAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSubscriptionEventAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.SubscriptionEventAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SubscriptionEventAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSubscriptionEventAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SubscriptionEventAction), nil
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive    SubscriptionStatus = "active"
+	SubscriptionStatusPaused    SubscriptionStatus = "paused"
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+)
+
+func (e *SubscriptionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SubscriptionStatus(s)
+	case string:
+		*e = SubscriptionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SubscriptionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullSubscriptionStatus struct {
+	SubscriptionStatus SubscriptionStatus `json:"subscription_status"`
+	Valid              bool               `json:"valid"` // Valid is true if SubscriptionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSubscriptionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.SubscriptionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SubscriptionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSubscriptionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SubscriptionStatus), nil
+}
+
+type ApiKey struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	KeyHash        string     `json:"key_hash"`
+	Scopes         []string   `json:"scopes"`
+	UserID         *uuid.UUID `json:"user_id"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+}
+
+type ExchangeRate struct {
+	ID             int64     `json:"id"`
+	Currency       string    `json:"currency"`
+	Month          time.Time `json:"month"`
+	Rate           float64   `json:"rate"`
+	CreatedAt      time.Time `json:"created_at"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+type IdempotencyKey struct {
+	Key             string    `json:"key"`
+	RequestHash     string    `json:"request_hash"`
+	StatusCode      *int32    `json:"status_code"`
+	ResponseHeaders []byte    `json:"response_headers"`
+	ResponseBody    []byte    `json:"response_body"`
+	CreatedAt       time.Time `json:"created_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	OrganizationID  uuid.UUID `json:"organization_id"`
+}
+
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Service struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Category       *string   `json:"category"`
+	DefaultPrice   *int32    `json:"default_price"`
+	CreatedAt      time.Time `json:"created_at"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+type ServiceAlias struct {
+	ID             int64     `json:"id"`
+	ServiceID      int64     `json:"service_id"`
+	Alias          string    `json:"alias"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
 type Subscription struct {
-	ID          int64      `json:"id"`
-	ServiceName string     `json:"service_name"`
-	Price       int32      `json:"price"`
-	UserID      uuid.UUID  `json:"user_id"`
-	StartDate   time.Time  `json:"start_date"`
-	EndDate     *time.Time `json:"end_date"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID             int64              `json:"id"`
+	ServiceName    string             `json:"service_name"`
+	Price          int32              `json:"price"`
+	UserID         uuid.UUID          `json:"user_id"`
+	StartDate      time.Time          `json:"start_date"`
+	EndDate        *time.Time         `json:"end_date"`
+	CreatedAt      time.Time          `json:"created_at"`
+	BillingPeriod  BillingPeriod      `json:"billing_period"`
+	Currency       string             `json:"currency"`
+	ServiceID      int64              `json:"service_id"`
+	Status         SubscriptionStatus `json:"status"`
+	TrialMonths    int32              `json:"trial_months"`
+	IntroPrice     *int32             `json:"intro_price"`
+	IntroMonths    int32              `json:"intro_months"`
+	DeletedAt      *time.Time         `json:"deleted_at"`
+	Version        int32              `json:"version"`
+	OrganizationID uuid.UUID          `json:"organization_id"`
+}
+
+type SubscriptionEvent struct {
+	ID             int64                   `json:"id"`
+	SubscriptionID int64                   `json:"subscription_id"`
+	Action         SubscriptionEventAction `json:"action"`
+	Before         []byte                  `json:"before"`
+	After          []byte                  `json:"after"`
+	Actor          *string                 `json:"actor"`
+	RequestID      *string                 `json:"request_id"`
+	CreatedAt      time.Time               `json:"created_at"`
+	OrganizationID uuid.UUID               `json:"organization_id"`
+}
+
+type SubscriptionPause struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	StartMonth     time.Time  `json:"start_month"`
+	EndMonth       *time.Time `json:"end_month"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type SubscriptionPriceHistory struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	Price          int32     `json:"price"`
+	EffectiveFrom  time.Time `json:"effective_from"`
+	CreatedAt      time.Time `json:"created_at"`
 }
@@ -6,14 +6,65 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	CancelSubscription(ctx context.Context, arg CancelSubscriptionParams) (Subscription, error)
+	CloseSubscriptionPause(ctx context.Context, arg CloseSubscriptionPauseParams) error
+	// Сводка для метрик складывается из сводок организаций. Истёкшие подписки
+	// отделяются так же, как в domain.SubscriptionStatusAt
+	CountSubscriptionsByStatus(ctx context.Context, arg CountSubscriptionsByStatusParams) ([]CountSubscriptionsByStatusRow, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error)
+	CreateService(ctx context.Context, arg CreateServiceParams) (Service, error)
+	CreateServiceAlias(ctx context.Context, arg CreateServiceAliasParams) error
 	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
-	DeleteSubscription(ctx context.Context, id int64) (int64, error)
-	GetSubscriptionByID(ctx context.Context, id int64) (Subscription, error)
-	ListSubscriptions(ctx context.Context, arg ListSubscriptionsParams) ([]Subscription, error)
-	ListSubscriptionsByUserID(ctx context.Context, arg ListSubscriptionsByUserIDParams) ([]Subscription, error)
+	CreateSubscriptionEvent(ctx context.Context, arg CreateSubscriptionEventParams) error
+	// Паузы и история цен изменяются в транзакции изменения подписки после её
+	// блокировки запросом, ограниченным организацией (GetSubscriptionForUpdate)
+	CreateSubscriptionPause(ctx context.Context, arg CreateSubscriptionPauseParams) error
+	DeleteEmptySubscriptionPauses(ctx context.Context, arg DeleteEmptySubscriptionPausesParams) error
+	DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteService(ctx context.Context, arg DeleteServiceParams) (int64, error)
+	DeleteServiceAliases(ctx context.Context, serviceID int64) error
+	// Организация ключа ещё неизвестна: по ключу она и определяется. Политика RLS
+	// пропускает ключ, хэш которого передан в app.api_key_hash
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetExchangeRateByID(ctx context.Context, arg GetExchangeRateByIDParams) (ExchangeRate, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetServiceByAlias(ctx context.Context, arg GetServiceByAliasParams) (Service, error)
+	GetServiceByID(ctx context.Context, arg GetServiceByIDParams) (Service, error)
+	GetServiceForUpdate(ctx context.Context, arg GetServiceForUpdateParams) (Service, error)
+	GetSubscriptionByID(ctx context.Context, arg GetSubscriptionByIDParams) (Subscription, error)
+	GetSubscriptionForUpdate(ctx context.Context, arg GetSubscriptionForUpdateParams) (Subscription, error)
+	GetSubscriptionOwner(ctx context.Context, arg GetSubscriptionOwnerParams) (uuid.UUID, error)
+	ListAPIKeys(ctx context.Context, arg ListAPIKeysParams) ([]ApiKey, error)
+	ListExchangeRates(ctx context.Context, arg ListExchangeRatesParams) ([]ExchangeRate, error)
+	ListOrganizationIDs(ctx context.Context) ([]uuid.UUID, error)
+	ListServiceAliases(ctx context.Context, serviceIds []int64) ([]ListServiceAliasesRow, error)
+	ListServiceSubscriptionsForUpdate(ctx context.Context, arg ListServiceSubscriptionsForUpdateParams) ([]Subscription, error)
+	ListServices(ctx context.Context, arg ListServicesParams) ([]Service, error)
+	ListSubscriptionEvents(ctx context.Context, arg ListSubscriptionEventsParams) ([]SubscriptionEvent, error)
+	ListSubscriptionPrices(ctx context.Context, arg ListSubscriptionPricesParams) ([]SubscriptionPriceHistory, error)
+	// Очистка по сроку хранения выполняется фоновой задачей для всех организаций
+	PurgeDeletedSubscriptions(ctx context.Context, arg PurgeDeletedSubscriptionsParams) ([]Subscription, error)
+	PurgeExpiredIdempotencyKeys(ctx context.Context, arg PurgeExpiredIdempotencyKeysParams) (int64, error)
+	RenameServiceSubscriptions(ctx context.Context, arg RenameServiceSubscriptionsParams) ([]Subscription, error)
+	// Просроченный ключ, который ещё не очищен, занимается заново
+	ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (IdempotencyKey, error)
+	RestoreSubscription(ctx context.Context, arg RestoreSubscriptionParams) (Subscription, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	// Хэш запроса, тело которого читалось потоком, становится известен только к ответу
+	SaveIdempotentResponse(ctx context.Context, arg SaveIdempotentResponseParams) error
+	SoftDeleteSubscription(ctx context.Context, arg SoftDeleteSubscriptionParams) (Subscription, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	UpdateExchangeRate(ctx context.Context, arg UpdateExchangeRateParams) (ExchangeRate, error)
+	UpdateService(ctx context.Context, arg UpdateServiceParams) (Service, error)
+	UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscriptionStatusParams) (Subscription, error)
+	UpsertSubscriptionPrice(ctx context.Context, arg UpsertSubscriptionPriceParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,396 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: services.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createService = `-- name: CreateService :one
+INSERT INTO services (
+    name,
+    category,
+    default_price,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, name, category, default_price, created_at, organization_id
+`
+
+type CreateServiceParams struct {
+	Name           string    `json:"name"`
+	Category       *string   `json:"category"`
+	DefaultPrice   *int32    `json:"default_price"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (Service, error) {
+	row := q.db.QueryRow(ctx, createService,
+		arg.Name,
+		arg.Category,
+		arg.DefaultPrice,
+		arg.OrganizationID,
+	)
+	var i Service
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Category,
+		&i.DefaultPrice,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const createServiceAlias = `-- name: CreateServiceAlias :exec
+INSERT INTO service_aliases (
+    service_id,
+    alias,
+    organization_id
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type CreateServiceAliasParams struct {
+	ServiceID      int64     `json:"service_id"`
+	Alias          string    `json:"alias"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) CreateServiceAlias(ctx context.Context, arg CreateServiceAliasParams) error {
+	_, err := q.db.Exec(ctx, createServiceAlias, arg.ServiceID, arg.Alias, arg.OrganizationID)
+	return err
+}
+
+const deleteService = `-- name: DeleteService :execrows
+DELETE FROM services
+WHERE id = $1 AND organization_id = $2
+`
+
+type DeleteServiceParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) DeleteService(ctx context.Context, arg DeleteServiceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteService, arg.ID, arg.OrganizationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteServiceAliases = `-- name: DeleteServiceAliases :exec
+DELETE FROM service_aliases
+WHERE service_id = $1
+`
+
+func (q *Queries) DeleteServiceAliases(ctx context.Context, serviceID int64) error {
+	_, err := q.db.Exec(ctx, deleteServiceAliases, serviceID)
+	return err
+}
+
+const getServiceByAlias = `-- name: GetServiceByAlias :one
+SELECT s.id, s.name, s.category, s.default_price, s.created_at, s.organization_id
+FROM services s
+JOIN service_aliases a ON a.service_id = s.id
+WHERE a.organization_id = $1 AND LOWER(a.alias) = LOWER($2)
+`
+
+type GetServiceByAliasParams struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Alias          string    `json:"alias"`
+}
+
+func (q *Queries) GetServiceByAlias(ctx context.Context, arg GetServiceByAliasParams) (Service, error) {
+	row := q.db.QueryRow(ctx, getServiceByAlias, arg.OrganizationID, arg.Alias)
+	var i Service
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Category,
+		&i.DefaultPrice,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getServiceByID = `-- name: GetServiceByID :one
+SELECT id, name, category, default_price, created_at, organization_id
+FROM services
+WHERE id = $1 AND organization_id = $2
+`
+
+type GetServiceByIDParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) GetServiceByID(ctx context.Context, arg GetServiceByIDParams) (Service, error) {
+	row := q.db.QueryRow(ctx, getServiceByID, arg.ID, arg.OrganizationID)
+	var i Service
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Category,
+		&i.DefaultPrice,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getServiceForUpdate = `-- name: GetServiceForUpdate :one
+SELECT id, name, category, default_price, created_at, organization_id
+FROM services
+WHERE id = $1 AND organization_id = $2
+FOR UPDATE
+`
+
+type GetServiceForUpdateParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) GetServiceForUpdate(ctx context.Context, arg GetServiceForUpdateParams) (Service, error) {
+	row := q.db.QueryRow(ctx, getServiceForUpdate, arg.ID, arg.OrganizationID)
+	var i Service
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Category,
+		&i.DefaultPrice,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const listServiceAliases = `-- name: ListServiceAliases :many
+SELECT service_id, alias
+FROM service_aliases
+WHERE service_id = ANY($1::BIGINT[])
+ORDER BY service_id, alias
+`
+
+type ListServiceAliasesRow struct {
+	ServiceID int64  `json:"service_id"`
+	Alias     string `json:"alias"`
+}
+
+func (q *Queries) ListServiceAliases(ctx context.Context, serviceIds []int64) ([]ListServiceAliasesRow, error) {
+	rows, err := q.db.Query(ctx, listServiceAliases, serviceIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListServiceAliasesRow{}
+	for rows.Next() {
+		var i ListServiceAliasesRow
+		if err := rows.Scan(&i.ServiceID, &i.Alias); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceSubscriptionsForUpdate = `-- name: ListServiceSubscriptionsForUpdate :many
+SELECT id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+FROM subscriptions
+WHERE service_id = $1 AND organization_id = $2
+ORDER BY id
+FOR UPDATE
+`
+
+type ListServiceSubscriptionsForUpdateParams struct {
+	ServiceID      int64     `json:"service_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) ListServiceSubscriptionsForUpdate(ctx context.Context, arg ListServiceSubscriptionsForUpdateParams) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, listServiceSubscriptionsForUpdate, arg.ServiceID, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Subscription{}
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceName,
+			&i.Price,
+			&i.UserID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.CreatedAt,
+			&i.BillingPeriod,
+			&i.Currency,
+			&i.ServiceID,
+			&i.Status,
+			&i.TrialMonths,
+			&i.IntroPrice,
+			&i.IntroMonths,
+			&i.DeletedAt,
+			&i.Version,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServices = `-- name: ListServices :many
+SELECT id, name, category, default_price, created_at, organization_id
+FROM services
+WHERE organization_id = $3
+    AND ($4::TEXT IS NULL OR category = $4)
+ORDER BY name
+LIMIT $1 OFFSET $2
+`
+
+type ListServicesParams struct {
+	Limit          int32     `json:"limit"`
+	Offset         int32     `json:"offset"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Category       *string   `json:"category"`
+}
+
+func (q *Queries) ListServices(ctx context.Context, arg ListServicesParams) ([]Service, error) {
+	rows, err := q.db.Query(ctx, listServices,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganizationID,
+		arg.Category,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Service{}
+	for rows.Next() {
+		var i Service
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Category,
+			&i.DefaultPrice,
+			&i.CreatedAt,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const renameServiceSubscriptions = `-- name: RenameServiceSubscriptions :many
+UPDATE subscriptions
+SET service_name = $2,
+    version = version + 1
+WHERE service_id = $1 AND organization_id = $3
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+`
+
+type RenameServiceSubscriptionsParams struct {
+	ServiceID      int64     `json:"service_id"`
+	ServiceName    string    `json:"service_name"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) RenameServiceSubscriptions(ctx context.Context, arg RenameServiceSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, renameServiceSubscriptions, arg.ServiceID, arg.ServiceName, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Subscription{}
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceName,
+			&i.Price,
+			&i.UserID,
+			&i.StartDate,
+			&i.EndDate,
+			&i.CreatedAt,
+			&i.BillingPeriod,
+			&i.Currency,
+			&i.ServiceID,
+			&i.Status,
+			&i.TrialMonths,
+			&i.IntroPrice,
+			&i.IntroMonths,
+			&i.DeletedAt,
+			&i.Version,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateService = `-- name: UpdateService :one
+UPDATE services
+SET name = COALESCE($1, name),
+    category = COALESCE($2, category),
+    default_price = COALESCE($3, default_price)
+WHERE id = $4 AND organization_id = $5
+RETURNING id, name, category, default_price, created_at, organization_id
+`
+
+type UpdateServiceParams struct {
+	Name           *string   `json:"name"`
+	Category       *string   `json:"category"`
+	DefaultPrice   *int32    `json:"default_price"`
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (Service, error) {
+	row := q.db.QueryRow(ctx, updateService,
+		arg.Name,
+		arg.Category,
+		arg.DefaultPrice,
+		arg.ID,
+		arg.OrganizationID,
+	)
+	var i Service
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Category,
+		&i.DefaultPrice,
+		&i.CreatedAt,
+		&i.OrganizationID,
+	)
+	return i, err
+}
@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: subscription_events.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createSubscriptionEvent = `-- name: CreateSubscriptionEvent :exec
+INSERT INTO subscription_events (
+    subscription_id,
+    action,
+    before,
+    after,
+    actor,
+    request_id,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+`
+
+type CreateSubscriptionEventParams struct {
+	SubscriptionID int64                   `json:"subscription_id"`
+	Action         SubscriptionEventAction `json:"action"`
+	Before         []byte                  `json:"before"`
+	After          []byte                  `json:"after"`
+	Actor          *string                 `json:"actor"`
+	RequestID      *string                 `json:"request_id"`
+	OrganizationID uuid.UUID               `json:"organization_id"`
+}
+
+func (q *Queries) CreateSubscriptionEvent(ctx context.Context, arg CreateSubscriptionEventParams) error {
+	_, err := q.db.Exec(ctx, createSubscriptionEvent,
+		arg.SubscriptionID,
+		arg.Action,
+		arg.Before,
+		arg.After,
+		arg.Actor,
+		arg.RequestID,
+		arg.OrganizationID,
+	)
+	return err
+}
+
+const listSubscriptionEvents = `-- name: ListSubscriptionEvents :many
+SELECT id, subscription_id, action, before, after, actor, request_id, created_at, organization_id
+FROM subscription_events
+WHERE organization_id = $1
+    AND ($2::BIGINT IS NULL OR subscription_id = $2)
+    AND ($3::subscription_event_action IS NULL OR action = $3)
+    AND ($4::TEXT IS NULL OR actor = $4)
+    AND ($5::TEXT IS NULL OR request_id = $5)
+    AND ($6::TIMESTAMPTZ IS NULL OR created_at >= $6)
+    AND ($7::TIMESTAMPTZ IS NULL OR created_at < $7)
+ORDER BY id DESC
+LIMIT $9 OFFSET $8
+`
+
+type ListSubscriptionEventsParams struct {
+	OrganizationID uuid.UUID                   `json:"organization_id"`
+	SubscriptionID *int64                      `json:"subscription_id"`
+	Action         NullSubscriptionEventAction `json:"action"`
+	Actor          *string                     `json:"actor"`
+	RequestID      *string                     `json:"request_id"`
+	CreatedFrom    pgtype.Timestamptz          `json:"created_from"`
+	CreatedTo      pgtype.Timestamptz          `json:"created_to"`
+	Offset         int32                       `json:"offset"`
+	Limit          int32                       `json:"limit"`
+}
+
+func (q *Queries) ListSubscriptionEvents(ctx context.Context, arg ListSubscriptionEventsParams) ([]SubscriptionEvent, error) {
+	rows, err := q.db.Query(ctx, listSubscriptionEvents,
+		arg.OrganizationID,
+		arg.SubscriptionID,
+		arg.Action,
+		arg.Actor,
+		arg.RequestID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SubscriptionEvent{}
+	for rows.Next() {
+		var i SubscriptionEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Action,
+			&i.Before,
+			&i.After,
+			&i.Actor,
+			&i.RequestID,
+			&i.CreatedAt,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,64 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: subscription_pauses.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+)
+
+const closeSubscriptionPause = `-- name: CloseSubscriptionPause :exec
+UPDATE subscription_pauses
+SET end_month = $1
+WHERE subscription_id = $2 AND end_month IS NULL
+`
+
+type CloseSubscriptionPauseParams struct {
+	EndMonth       *time.Time `json:"end_month"`
+	SubscriptionID int64      `json:"subscription_id"`
+}
+
+func (q *Queries) CloseSubscriptionPause(ctx context.Context, arg CloseSubscriptionPauseParams) error {
+	_, err := q.db.Exec(ctx, closeSubscriptionPause, arg.EndMonth, arg.SubscriptionID)
+	return err
+}
+
+const createSubscriptionPause = `-- name: CreateSubscriptionPause :exec
+
+INSERT INTO subscription_pauses (
+    subscription_id,
+    start_month
+) VALUES (
+    $1, $2
+)
+`
+
+type CreateSubscriptionPauseParams struct {
+	SubscriptionID int64     `json:"subscription_id"`
+	StartMonth     time.Time `json:"start_month"`
+}
+
+// Паузы и история цен изменяются в транзакции изменения подписки после её
+// блокировки запросом, ограниченным организацией (GetSubscriptionForUpdate)
+func (q *Queries) CreateSubscriptionPause(ctx context.Context, arg CreateSubscriptionPauseParams) error {
+	_, err := q.db.Exec(ctx, createSubscriptionPause, arg.SubscriptionID, arg.StartMonth)
+	return err
+}
+
+const deleteEmptySubscriptionPauses = `-- name: DeleteEmptySubscriptionPauses :exec
+DELETE FROM subscription_pauses
+WHERE subscription_id = $1 AND end_month IS NULL AND start_month > $2
+`
+
+type DeleteEmptySubscriptionPausesParams struct {
+	SubscriptionID int64     `json:"subscription_id"`
+	EndMonth       time.Time `json:"end_month"`
+}
+
+func (q *Queries) DeleteEmptySubscriptionPauses(ctx context.Context, arg DeleteEmptySubscriptionPausesParams) error {
+	_, err := q.db.Exec(ctx, deleteEmptySubscriptionPauses, arg.SubscriptionID, arg.EndMonth)
+	return err
+}
@@ -0,0 +1,75 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: subscription_prices.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const listSubscriptionPrices = `-- name: ListSubscriptionPrices :many
+SELECT id, subscription_id, price, effective_from, created_at
+FROM subscription_price_history h
+WHERE h.subscription_id = $1
+    AND EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = h.subscription_id AND s.organization_id = $2)
+ORDER BY effective_from
+`
+
+type ListSubscriptionPricesParams struct {
+	SubscriptionID int64     `json:"subscription_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) ListSubscriptionPrices(ctx context.Context, arg ListSubscriptionPricesParams) ([]SubscriptionPriceHistory, error) {
+	rows, err := q.db.Query(ctx, listSubscriptionPrices, arg.SubscriptionID, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SubscriptionPriceHistory{}
+	for rows.Next() {
+		var i SubscriptionPriceHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Price,
+			&i.EffectiveFrom,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSubscriptionPrice = `-- name: UpsertSubscriptionPrice :exec
+INSERT INTO subscription_price_history (
+    subscription_id,
+    price,
+    effective_from
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (subscription_id, effective_from) DO UPDATE
+SET price = EXCLUDED.price
+`
+
+type UpsertSubscriptionPriceParams struct {
+	SubscriptionID int64     `json:"subscription_id"`
+	Price          int32     `json:"price"`
+	EffectiveFrom  time.Time `json:"effective_from"`
+}
+
+func (q *Queries) UpsertSubscriptionPrice(ctx context.Context, arg UpsertSubscriptionPriceParams) error {
+	_, err := q.db.Exec(ctx, upsertSubscriptionPrice, arg.SubscriptionID, arg.Price, arg.EffectiveFrom)
+	return err
+}
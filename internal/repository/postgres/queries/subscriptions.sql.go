@@ -12,25 +12,125 @@ import (
 	"github.com/google/uuid"
 )
 
+const cancelSubscription = `-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'cancelled',
+    end_date = CASE
+        WHEN end_date IS NULL OR end_date > $1 THEN $1
+        ELSE end_date
+    END,
+    version = version + 1
+WHERE id = $2 AND organization_id = $3
+    AND status <> 'cancelled' AND deleted_at IS NULL
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+`
+
+type CancelSubscriptionParams struct {
+	Month          *time.Time `json:"month"`
+	ID             int64      `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+}
+
+func (q *Queries) CancelSubscription(ctx context.Context, arg CancelSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, cancelSubscription, arg.Month, arg.ID, arg.OrganizationID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceName,
+		&i.Price,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const countSubscriptionsByStatus = `-- name: CountSubscriptionsByStatus :many
+SELECT status,
+    (status <> 'cancelled' AND end_date IS NOT NULL AND end_date < $1)::BOOLEAN AS expired,
+    COUNT(*)::BIGINT AS count
+FROM subscriptions
+WHERE organization_id = $2 AND deleted_at IS NULL
+GROUP BY 1, 2
+`
+
+type CountSubscriptionsByStatusParams struct {
+	MonthStart     *time.Time `json:"month_start"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+}
+
+type CountSubscriptionsByStatusRow struct {
+	Status  SubscriptionStatus `json:"status"`
+	Expired bool               `json:"expired"`
+	Count   int64              `json:"count"`
+}
+
+// Сводка для метрик складывается из сводок организаций. Истёкшие подписки
+// отделяются так же, как в domain.SubscriptionStatusAt
+func (q *Queries) CountSubscriptionsByStatus(ctx context.Context, arg CountSubscriptionsByStatusParams) ([]CountSubscriptionsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, countSubscriptionsByStatus, arg.MonthStart, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountSubscriptionsByStatusRow{}
+	for rows.Next() {
+		var i CountSubscriptionsByStatusRow
+		if err := rows.Scan(&i.Status, &i.Expired, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createSubscription = `-- name: CreateSubscription :one
 INSERT INTO subscriptions (
     service_name,
     price,
     user_id,
     start_date,
-    end_date
+    end_date,
+    billing_period,
+    currency,
+    service_id,
+    trial_months,
+    intro_price,
+    intro_months,
+    organization_id
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
-RETURNING id, service_name, price, user_id, start_date, end_date, created_at
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
 `
 
 type CreateSubscriptionParams struct {
-	ServiceName string     `json:"service_name"`
-	Price       int32      `json:"price"`
-	UserID      uuid.UUID  `json:"user_id"`
-	StartDate   time.Time  `json:"start_date"`
-	EndDate     *time.Time `json:"end_date"`
+	ServiceName    string        `json:"service_name"`
+	Price          int32         `json:"price"`
+	UserID         uuid.UUID     `json:"user_id"`
+	StartDate      time.Time     `json:"start_date"`
+	EndDate        *time.Time    `json:"end_date"`
+	BillingPeriod  BillingPeriod `json:"billing_period"`
+	Currency       string        `json:"currency"`
+	ServiceID      int64         `json:"service_id"`
+	TrialMonths    int32         `json:"trial_months"`
+	IntroPrice     *int32        `json:"intro_price"`
+	IntroMonths    int32         `json:"intro_months"`
+	OrganizationID uuid.UUID     `json:"organization_id"`
 }
 
 func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
@@ -40,6 +140,13 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		arg.UserID,
 		arg.StartDate,
 		arg.EndDate,
+		arg.BillingPeriod,
+		arg.Currency,
+		arg.ServiceID,
+		arg.TrialMonths,
+		arg.IntroPrice,
+		arg.IntroMonths,
+		arg.OrganizationID,
 	)
 	var i Subscription
 	err := row.Scan(
@@ -50,31 +157,70 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		&i.StartDate,
 		&i.EndDate,
 		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
-const deleteSubscription = `-- name: DeleteSubscription :execrows
-DELETE FROM subscriptions
-WHERE id = $1
+const getSubscriptionByID = `-- name: GetSubscriptionByID :one
+SELECT id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+FROM subscriptions
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteSubscription(ctx context.Context, id int64) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteSubscription, id)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected(), nil
+type GetSubscriptionByIDParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
 }
 
-const getSubscriptionByID = `-- name: GetSubscriptionByID :one
-SELECT id, service_name, price, user_id, start_date, end_date, created_at
+func (q *Queries) GetSubscriptionByID(ctx context.Context, arg GetSubscriptionByIDParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscriptionByID, arg.ID, arg.OrganizationID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceName,
+		&i.Price,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getSubscriptionForUpdate = `-- name: GetSubscriptionForUpdate :one
+SELECT id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
 FROM subscriptions
-WHERE id = $1
+WHERE id = $1 AND organization_id = $2
+FOR UPDATE
 `
 
-func (q *Queries) GetSubscriptionByID(ctx context.Context, id int64) (Subscription, error) {
-	row := q.db.QueryRow(ctx, getSubscriptionByID, id)
+type GetSubscriptionForUpdateParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) GetSubscriptionForUpdate(ctx context.Context, arg GetSubscriptionForUpdateParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscriptionForUpdate, arg.ID, arg.OrganizationID)
 	var i Subscription
 	err := row.Scan(
 		&i.ID,
@@ -84,66 +230,52 @@ func (q *Queries) GetSubscriptionByID(ctx context.Context, id int64) (Subscripti
 		&i.StartDate,
 		&i.EndDate,
 		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
-const listSubscriptions = `-- name: ListSubscriptions :many
-SELECT id, service_name, price, user_id, start_date, end_date, created_at
+const getSubscriptionOwner = `-- name: GetSubscriptionOwner :one
+SELECT user_id
 FROM subscriptions
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+WHERE id = $1 AND organization_id = $2
 `
 
-type ListSubscriptionsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type GetSubscriptionOwnerParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
 }
 
-func (q *Queries) ListSubscriptions(ctx context.Context, arg ListSubscriptionsParams) ([]Subscription, error) {
-	rows, err := q.db.Query(ctx, listSubscriptions, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []Subscription{}
-	for rows.Next() {
-		var i Subscription
-		if err := rows.Scan(
-			&i.ID,
-			&i.ServiceName,
-			&i.Price,
-			&i.UserID,
-			&i.StartDate,
-			&i.EndDate,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) GetSubscriptionOwner(ctx context.Context, arg GetSubscriptionOwnerParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getSubscriptionOwner, arg.ID, arg.OrganizationID)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
 }
 
-const listSubscriptionsByUserID = `-- name: ListSubscriptionsByUserID :many
-SELECT id, service_name, price, user_id, start_date, end_date, created_at
-FROM subscriptions
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const purgeDeletedSubscriptions = `-- name: PurgeDeletedSubscriptions :many
+DELETE FROM subscriptions
+WHERE organization_id = $1 AND deleted_at < $2
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
 `
 
-type ListSubscriptionsByUserIDParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type PurgeDeletedSubscriptionsParams struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	DeletedBefore  *time.Time `json:"deleted_before"`
 }
 
-func (q *Queries) ListSubscriptionsByUserID(ctx context.Context, arg ListSubscriptionsByUserIDParams) ([]Subscription, error) {
-	rows, err := q.db.Query(ctx, listSubscriptionsByUserID, arg.UserID, arg.Limit, arg.Offset)
+// Очистка по сроку хранения выполняется фоновой задачей для всех организаций
+func (q *Queries) PurgeDeletedSubscriptions(ctx context.Context, arg PurgeDeletedSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, purgeDeletedSubscriptions, arg.OrganizationID, arg.DeletedBefore)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +291,16 @@ func (q *Queries) ListSubscriptionsByUserID(ctx context.Context, arg ListSubscri
 			&i.StartDate,
 			&i.EndDate,
 			&i.CreatedAt,
+			&i.BillingPeriod,
+			&i.Currency,
+			&i.ServiceID,
+			&i.Status,
+			&i.TrialMonths,
+			&i.IntroPrice,
+			&i.IntroMonths,
+			&i.DeletedAt,
+			&i.Version,
+			&i.OrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -169,3 +311,125 @@ func (q *Queries) ListSubscriptionsByUserID(ctx context.Context, arg ListSubscri
 	}
 	return items, nil
 }
+
+const restoreSubscription = `-- name: RestoreSubscription :one
+UPDATE subscriptions
+SET deleted_at = NULL,
+    version = version + 1
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+`
+
+type RestoreSubscriptionParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) RestoreSubscription(ctx context.Context, arg RestoreSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, restoreSubscription, arg.ID, arg.OrganizationID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceName,
+		&i.Price,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const softDeleteSubscription = `-- name: SoftDeleteSubscription :one
+UPDATE subscriptions
+SET deleted_at = NOW(),
+    version = version + 1
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+`
+
+type SoftDeleteSubscriptionParams struct {
+	ID             int64     `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+func (q *Queries) SoftDeleteSubscription(ctx context.Context, arg SoftDeleteSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, softDeleteSubscription, arg.ID, arg.OrganizationID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceName,
+		&i.Price,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const updateSubscriptionStatus = `-- name: UpdateSubscriptionStatus :one
+UPDATE subscriptions
+SET status = $1,
+    version = version + 1
+WHERE id = $2 AND organization_id = $3
+    AND status = $4 AND deleted_at IS NULL
+RETURNING id, service_name, price, user_id, start_date, end_date, created_at, billing_period, currency, service_id, status, trial_months, intro_price, intro_months, deleted_at, version, organization_id
+`
+
+type UpdateSubscriptionStatusParams struct {
+	Status         SubscriptionStatus `json:"status"`
+	ID             int64              `json:"id"`
+	OrganizationID uuid.UUID          `json:"organization_id"`
+	FromStatus     SubscriptionStatus `json:"from_status"`
+}
+
+func (q *Queries) UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscriptionStatusParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, updateSubscriptionStatus,
+		arg.Status,
+		arg.ID,
+		arg.OrganizationID,
+		arg.FromStatus,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceName,
+		&i.Price,
+		&i.UserID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.CreatedAt,
+		&i.BillingPeriod,
+		&i.Currency,
+		&i.ServiceID,
+		&i.Status,
+		&i.TrialMonths,
+		&i.IntroPrice,
+		&i.IntroMonths,
+		&i.DeletedAt,
+		&i.Version,
+		&i.OrganizationID,
+	)
+	return i, err
+}
@@ -3,37 +3,127 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE нарушений ограничений
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
 )
 
 type SubscriptionProvider interface {
 	CreateSubscription(ctx context.Context, input *domain.CreateSubscriptionInput) (*domain.Subscription, error)
+	CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error)
-	ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error)
+	GetSubscriptionOwner(ctx context.Context, id int64) (uuid.UUID, error)
+	ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) ([]domain.Subscription, error)
 	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error)
+	CountSubscriptions(ctx context.Context, filter domain.ListFilter) (int64, error)
+	ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error
 	UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error)
-	DeleteSubscription(ctx context.Context, id int64) error
+	PauseSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error
+	RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error)
+	PurgeDeletedSubscriptions(ctx context.Context, deletedBefore time.Time) (int64, error)
+	CountSubscriptionsByStatus(ctx context.Context, now time.Time) (map[domain.SubscriptionStatus]int64, error)
 	CalculateTotalCost(ctx context.Context, filter domain.CostFilter) (domain.TotalCost, error)
+	CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error)
+	CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error)
+	CalculateOrganizationCost(ctx context.Context, filter domain.CostFilter) (domain.OrganizationCost, error)
+	ListSubscriptionCosts(ctx context.Context, filter domain.CostFilter) ([]domain.SubscriptionCost, error)
+	ListMissingExchangeRates(ctx context.Context, filter domain.CostFilter) ([]domain.MissingExchangeRate, error)
+	ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error)
+}
+
+type ExchangeRateProvider interface {
+	CreateExchangeRate(ctx context.Context, input *domain.CreateExchangeRateInput) (*domain.ExchangeRate, error)
+	GetExchangeRateByID(ctx context.Context, id int64) (*domain.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, filter domain.ExchangeRateFilter, params domain.ListParams) ([]domain.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, id int64, rate float64) (*domain.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, id int64) error
 }
 
-var _ SubscriptionProvider = (*PostgresRepository)(nil)
+type ServiceProvider interface {
+	CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error)
+	GetServiceByID(ctx context.Context, id int64) (*domain.Service, error)
+	GetServiceByName(ctx context.Context, name string) (*domain.Service, error)
+	ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error)
+	UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error)
+	DeleteService(ctx context.Context, id int64) error
+}
+
+type AuditProvider interface {
+	ListSubscriptionEvents(ctx context.Context, filter domain.AuditFilter, params domain.ListParams) ([]domain.SubscriptionEvent, error)
+}
+
+type IdempotencyProvider interface {
+	ReserveIdempotencyKey(ctx context.Context, key, requestHash string, expiresAt time.Time) (*domain.IdempotencyKey, bool, error)
+	SaveIdempotentResponse(ctx context.Context, key string, response domain.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type APIKeyProvider interface {
+	CreateAPIKey(ctx context.Context, input domain.CreateAPIKeyInput) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context, params domain.ListParams) ([]domain.APIKey, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+var (
+	_ SubscriptionProvider = (*PostgresRepository)(nil)
+	_ ExchangeRateProvider = (*PostgresRepository)(nil)
+	_ ServiceProvider      = (*PostgresRepository)(nil)
+	_ AuditProvider        = (*PostgresRepository)(nil)
+	_ IdempotencyProvider  = (*PostgresRepository)(nil)
+	_ APIKeyProvider       = (*PostgresRepository)(nil)
+)
+
+// DB соединение с поддержкой транзакций (*pgxpool.Pool, pgx.Tx)
+type DB interface {
+	sqlc.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
 
 type PostgresRepository struct {
-	DB      sqlc.DBTX
+	DB      DB
 	Queries sqlc.Querier
 }
 
-func NewRepository(db sqlc.DBTX) *PostgresRepository {
+func NewRepository(db DB) *PostgresRepository {
 	return &PostgresRepository{
 		DB:      db,
 		Queries: sqlc.New(db),
 	}
 }
 
+// withTx выполняет fn в транзакции: репозиторий, переданный в fn, работает
+// через транзакцию, которая фиксируется, если fn не вернул ошибку
+func (r *PostgresRepository) withTx(ctx context.Context, fn func(tx *PostgresRepository) error) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// После Commit откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	if err := fn(NewRepository(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (r *PostgresRepository) handleError(err error) error {
 	if err == nil {
 		return nil
@@ -44,5 +134,17 @@ func (r *PostgresRepository) handleError(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return ErrNotFound
 	}
+	if errors.Is(err, ErrVersionMismatch) {
+		return ErrVersionMismatch
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolationCode:
+			return ErrAlreadyExists
+		case foreignKeyViolationCode:
+			return ErrInUse
+		}
+	}
 	return ErrInternal
 }
@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
+)
+
+// CreateService создаёт сервис каталога вместе с синонимами
+func (r *PostgresRepository) CreateService(ctx context.Context, input *domain.CreateServiceInput) (*domain.Service, error) {
+	const op = "repository.CreateService"
+	log := slog.With(slog.String("op", op), slog.String("name", input.Name))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to create service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	var result sqlc.Service
+	err = r.withTx(ctx, func(tx *PostgresRepository) error {
+		var err error
+		result, err = tx.Queries.CreateService(ctx, sqlc.CreateServiceParams{
+			Name:           input.Name,
+			Category:       input.Category,
+			DefaultPrice:   input.DefaultPrice,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.createServiceAliases(ctx, &result, input.Aliases)
+	})
+	if err != nil {
+		log.Error("failed to create service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.serviceToDomain(&result, serviceAliases(result.Name, input.Aliases)), nil
+}
+
+// GetServiceByID получает сервис по ID
+func (r *PostgresRepository) GetServiceByID(ctx context.Context, id int64) (*domain.Service, error) {
+	const op = "repository.GetServiceByID"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to get service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.GetServiceByID(ctx, sqlc.GetServiceByIDParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to get service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	services, err := r.withAliases(ctx, []sqlc.Service{result})
+	if err != nil {
+		log.Error("failed to list service aliases", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return &services[0], nil
+}
+
+// GetServiceByName находит сервис каталога организации по каноническому названию
+// или синониму без учёта регистра
+func (r *PostgresRepository) GetServiceByName(ctx context.Context, name string) (*domain.Service, error) {
+	const op = "repository.GetServiceByName"
+	log := slog.With(slog.String("op", op), slog.String("name", name))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to get service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.GetServiceByAlias(ctx, sqlc.GetServiceByAliasParams{
+		OrganizationID: orgID,
+		Alias:          name,
+	})
+	if err != nil {
+		log.Debug("service not resolved", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	services, err := r.withAliases(ctx, []sqlc.Service{result})
+	if err != nil {
+		log.Error("failed to list service aliases", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return &services[0], nil
+}
+
+// ListServices возвращает список сервисов каталога организации по алфавиту с пагинацией
+func (r *PostgresRepository) ListServices(ctx context.Context, filter domain.ServiceFilter, params domain.ListParams) ([]domain.Service, error) {
+	const op = "repository.ListServices"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list services", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	results, err := r.Queries.ListServices(ctx, sqlc.ListServicesParams{
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+		OrganizationID: orgID,
+		Category:       filter.Category,
+	})
+	if err != nil {
+		log.Error("failed to list services", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	services, err := r.withAliases(ctx, results)
+	if err != nil {
+		log.Error("failed to list service aliases", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return services, nil
+}
+
+// UpdateService обновляет сервис. При переименовании каноническое название
+// обновляется и в подписках сервиса, каждое такое изменение попадает в журнал.
+func (r *PostgresRepository) UpdateService(ctx context.Context, id int64, input domain.UpdateServiceInput) (*domain.Service, error) {
+	const op = "repository.UpdateService"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to update service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	var service *domain.Service
+	err = r.withTx(ctx, func(tx *PostgresRepository) error {
+		// Блокировка сервиса задерживает создание его подписок до конца переименования
+		current, err := tx.Queries.GetServiceForUpdate(ctx, sqlc.GetServiceForUpdateParams{
+			ID:             id,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+		currentAliases, err := tx.withAliases(ctx, []sqlc.Service{current})
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Queries.UpdateService(ctx, sqlc.UpdateServiceParams{
+			ID:             id,
+			OrganizationID: orgID,
+			Name:           input.Name,
+			Category:       input.Category,
+			DefaultPrice:   input.DefaultPrice,
+		})
+		if err != nil {
+			return err
+		}
+
+		aliases := currentAliases[0].Aliases
+		if input.Aliases != nil {
+			aliases = input.Aliases
+		}
+
+		if result.Name != current.Name {
+			if err := tx.renameServiceSubscriptions(ctx, &result); err != nil {
+				return err
+			}
+			// Старое название остаётся синонимом, чтобы фильтры по нему продолжали работать
+			if input.Aliases == nil {
+				aliases = append(aliases, current.Name)
+			}
+		}
+
+		if err := tx.Queries.DeleteServiceAliases(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.createServiceAliases(ctx, &result, aliases); err != nil {
+			return err
+		}
+
+		service = r.serviceToDomain(&result, serviceAliases(result.Name, aliases))
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to update service", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return service, nil
+}
+
+// DeleteService удаляет сервис, на который не ссылаются подписки
+func (r *PostgresRepository) DeleteService(ctx context.Context, id int64) error {
+	const op = "repository.DeleteService"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to delete service", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	rowsAffected, err := r.Queries.DeleteService(ctx, sqlc.DeleteServiceParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to delete service", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// renameServiceSubscriptions переносит новое название сервиса в его подписки
+// и записывает изменение каждой подписки в журнал
+func (r *PostgresRepository) renameServiceSubscriptions(ctx context.Context, service *sqlc.Service) error {
+	subs, err := r.Queries.ListServiceSubscriptionsForUpdate(ctx, sqlc.ListServiceSubscriptionsForUpdateParams{
+		ServiceID:      service.ID,
+		OrganizationID: service.OrganizationID,
+	})
+	if err != nil {
+		return err
+	}
+	before := make(map[int64]sqlc.Subscription, len(subs))
+	for _, sub := range subs {
+		before[sub.ID] = sub
+	}
+
+	renamed, err := r.Queries.RenameServiceSubscriptions(ctx, sqlc.RenameServiceSubscriptionsParams{
+		ServiceID:      service.ID,
+		ServiceName:    service.Name,
+		OrganizationID: service.OrganizationID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, after := range renamed {
+		prev := before[after.ID]
+		if err := r.recordSubscriptionEvent(ctx, after.ID, domain.AuditActionUpdated, &prev, &after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureService находит сервис по названию или синониму, а неизвестное название добавляет
+// в каталог. Вызывается в транзакции, сохраняющей подписку: её откат убирает и сервис.
+func (r *PostgresRepository) ensureService(ctx context.Context, name string) (*domain.Service, error) {
+	service, err := r.GetServiceByName(ctx, name)
+	if !errors.Is(err, ErrNotFound) {
+		return service, err
+	}
+
+	input := domain.NewCreateServiceInput(name, nil, nil, nil)
+	service, err = r.CreateService(ctx, &input)
+	if errors.Is(err, ErrAlreadyExists) {
+		// Сервис с таким названием добавлен параллельной транзакцией
+		return r.GetServiceByName(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("service added to catalog", slog.Int64("service_id", service.ID), slog.String("name", service.Name))
+	return service, nil
+}
+
+// createServiceAliases сохраняет каноническое название и синонимы сервиса
+func (r *PostgresRepository) createServiceAliases(ctx context.Context, service *sqlc.Service, aliases []string) error {
+	for _, alias := range append([]string{service.Name}, serviceAliases(service.Name, aliases)...) {
+		if err := r.Queries.CreateServiceAlias(ctx, sqlc.CreateServiceAliasParams{
+			ServiceID:      service.ID,
+			Alias:          alias,
+			OrganizationID: service.OrganizationID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withAliases загружает синонимы сервисов одним запросом
+func (r *PostgresRepository) withAliases(ctx context.Context, results []sqlc.Service) ([]domain.Service, error) {
+	ids := make([]int64, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+
+	rows, err := r.Queries.ListServiceAliases(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[int64][]string, len(results))
+	for _, row := range rows {
+		aliases[row.ServiceID] = append(aliases[row.ServiceID], row.Alias)
+	}
+
+	services := make([]domain.Service, len(results))
+	for i, result := range results {
+		services[i] = *r.serviceToDomain(&result, serviceAliases(result.Name, aliases[result.ID]))
+	}
+
+	return services, nil
+}
+
+// serviceAliases убирает из синонимов каноническое название и повторы без учёта регистра
+func serviceAliases(name string, aliases []string) []string {
+	seen := map[string]bool{strings.ToLower(name): true}
+	result := []string{}
+	for _, alias := range aliases {
+		key := strings.ToLower(alias)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, alias)
+	}
+	return result
+}
+
+// serviceToDomain конвертирует sqlc модель в domain
+func (r *PostgresRepository) serviceToDomain(s *sqlc.Service, aliases []string) *domain.Service {
+	return domain.NewService(s.ID, s.Name, aliases, s.Category, s.DefaultPrice, s.CreatedAt)
+}
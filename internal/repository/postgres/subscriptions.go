@@ -2,13 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	sqlc "github.com/Krokozabra213/effective_mobile/internal/repository/postgres/queries"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // CreateSubscription создаёт подписку
@@ -16,12 +19,52 @@ func (r *PostgresRepository) CreateSubscription(ctx context.Context, input *doma
 	const op = "repository.CreateSubscription"
 	log := slog.With(slog.String("op", op))
 
-	result, err := r.Queries.CreateSubscription(ctx, sqlc.CreateSubscriptionParams{
-		ServiceName: input.ServiceName,
-		Price:       input.Price,
-		UserID:      input.UserID,
-		StartDate:   input.StartDate,
-		EndDate:     input.EndDate,
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to create subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	var result sqlc.Subscription
+	err = r.withTx(ctx, func(tx *PostgresRepository) error {
+		serviceID, serviceName := input.ServiceID, input.ServiceName
+		if serviceID == 0 {
+			service, err := tx.ensureService(ctx, serviceName)
+			if err != nil {
+				return err
+			}
+			serviceID, serviceName = service.ID, service.Name
+		}
+
+		var err error
+		result, err = tx.Queries.CreateSubscription(ctx, sqlc.CreateSubscriptionParams{
+			ServiceName:    serviceName,
+			Price:          input.Price,
+			UserID:         input.UserID,
+			StartDate:      input.StartDate,
+			EndDate:        input.EndDate,
+			BillingPeriod:  sqlc.BillingPeriod(input.BillingPeriod),
+			Currency:       input.Currency,
+			ServiceID:      serviceID,
+			TrialMonths:    input.Intro.TrialMonths,
+			IntroPrice:     input.Intro.IntroPrice,
+			IntroMonths:    input.Intro.IntroMonths,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Начальная цена действует с месяца начала подписки
+		if err := tx.Queries.UpsertSubscriptionPrice(ctx, sqlc.UpsertSubscriptionPriceParams{
+			SubscriptionID: result.ID,
+			Price:          result.Price,
+			EffectiveFrom:  result.StartDate,
+		}); err != nil {
+			return err
+		}
+
+		return tx.recordSubscriptionEvent(ctx, result.ID, domain.AuditActionCreated, nil, &result)
 	})
 	if err != nil {
 		log.Error("failed to create subscription", slog.String("error", err.Error()))
@@ -31,12 +74,62 @@ func (r *PostgresRepository) CreateSubscription(ctx context.Context, input *doma
 	return r.toDomain(&result), nil
 }
 
+// errBatchAborted прерывает транзакцию атомарного пакета после ошибки подписки
+var errBatchAborted = errors.New("batch aborted")
+
+// CreateSubscriptions создаёт подписки пакетом в одной транзакции. Каждая подписка
+// создаётся в своей точке сохранения: в атомарном режиме первая ошибка откатывает
+// весь пакет, иначе откатывается только ошибочная подписка. Результаты соответствуют
+// inputs по индексу.
+func (r *PostgresRepository) CreateSubscriptions(ctx context.Context, inputs []*domain.CreateSubscriptionInput, atomic bool) ([]domain.BatchResult, error) {
+	const op = "repository.CreateSubscriptions"
+	log := slog.With(slog.String("op", op), slog.Int("size", len(inputs)), slog.Bool("atomic", atomic))
+
+	results := make([]domain.BatchResult, len(inputs))
+	err := r.withTx(ctx, func(tx *PostgresRepository) error {
+		for i, input := range inputs {
+			sub, err := tx.CreateSubscription(ctx, input)
+			if err != nil {
+				results[i].Err = err
+				if atomic {
+					return errBatchAborted
+				}
+				continue
+			}
+			results[i].Subscription = sub
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchAborted) {
+		// Созданные до ошибки подписки откачены вместе с транзакцией
+		for i := range results {
+			results[i].Subscription = nil
+		}
+		return results, nil
+	}
+	if err != nil {
+		log.Error("failed to create subscriptions", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return results, nil
+}
+
 // GetSubscriptionByID получает подписку по ID
 func (r *PostgresRepository) GetSubscriptionByID(ctx context.Context, id int64) (*domain.Subscription, error) {
 	const op = "repository.GetSubscriptionByID"
 	log := slog.With(slog.String("op", op), slog.Int64("id", id))
 
-	result, err := r.Queries.GetSubscriptionByID(ctx, id)
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to get subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	result, err := r.Queries.GetSubscriptionByID(ctx, sqlc.GetSubscriptionByIDParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
 	if err != nil {
 		log.Error("failed to get subscription", slog.String("error", err.Error()))
 		return nil, r.handleError(err)
@@ -45,55 +138,270 @@ func (r *PostgresRepository) GetSubscriptionByID(ctx context.Context, id int64)
 	return r.toDomain(&result), nil
 }
 
-// ListSubscriptions возвращает список подписок с пагинацией
-func (r *PostgresRepository) ListSubscriptions(ctx context.Context, params domain.ListParams) ([]domain.Subscription, error) {
+// GetSubscriptionOwner возвращает пользователя подписки, в том числе удалённой
+func (r *PostgresRepository) GetSubscriptionOwner(ctx context.Context, id int64) (uuid.UUID, error) {
+	const op = "repository.GetSubscriptionOwner"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to get subscription owner", slog.String("error", err.Error()))
+		return uuid.Nil, r.handleError(err)
+	}
+
+	userID, err := r.Queries.GetSubscriptionOwner(ctx, sqlc.GetSubscriptionOwnerParams{
+		ID:             id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to get subscription owner", slog.String("error", err.Error()))
+		return uuid.Nil, r.handleError(err)
+	}
+
+	return userID, nil
+}
+
+// ListSubscriptions возвращает подписки, подходящие под фильтр, с пагинацией
+// по смещению или курсору
+func (r *PostgresRepository) ListSubscriptions(ctx context.Context, filter domain.ListFilter, params domain.ListParams) ([]domain.Subscription, error) {
 	const op = "repository.ListSubscriptions"
 	log := slog.With(slog.String("op", op))
 
-	results, err := r.Queries.ListSubscriptions(ctx, sqlc.ListSubscriptionsParams{
-		Limit:  params.Limit,
-		Offset: params.Offset,
-	})
+	orgID, err := organizationID(ctx)
 	if err != nil {
 		log.Error("failed to list subscriptions", slog.String("error", err.Error()))
 		return nil, r.handleError(err)
 	}
 
-	subs := make([]domain.Subscription, len(results))
-	for i, result := range results {
-		subs[i] = *r.toDomain(&result)
+	query, args := r.listQuery(orgID, filter, params)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to list subscriptions", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+	defer rows.Close()
+
+	subs := []domain.Subscription{}
+	for rows.Next() {
+		var result sqlc.Subscription
+		if err := scanSubscription(rows, &result); err != nil {
+			log.Error("failed to scan subscription", slog.String("error", err.Error()))
+			return nil, r.handleError(err)
+		}
+		subs = append(subs, *r.toDomain(&result))
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate subscriptions", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
 	}
 
 	return subs, nil
 }
 
+// CountSubscriptions возвращает число подписок, подходящих под фильтр
+func (r *PostgresRepository) CountSubscriptions(ctx context.Context, filter domain.ListFilter) (int64, error) {
+	const op = "repository.CountSubscriptions"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to count subscriptions", slog.String("error", err.Error()))
+		return 0, r.handleError(err)
+	}
+
+	conditions, args := r.listConditions(orgID, filter)
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM subscriptions s
+		%s
+	`, whereSQL(conditions))
+
+	var total int64
+	if err := r.DB.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		log.Error("failed to count subscriptions", slog.String("error", err.Error()))
+		return 0, r.handleError(err)
+	}
+
+	return total, nil
+}
+
+// ExportSubscriptions передаёт в fn все подписки, подходящие под фильтр, по мере чтения
+// строк из курсора, не накапливая их в памяти. Ошибка fn прерывает выгрузку и возвращается как есть.
+func (r *PostgresRepository) ExportSubscriptions(ctx context.Context, filter domain.ListFilter, fn func(*domain.Subscription) error) error {
+	const op = "repository.ExportSubscriptions"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to export subscriptions", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	conditions, args := r.listConditions(orgID, filter)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM subscriptions s
+		%s
+		ORDER BY %s
+	`, subscriptionColumns, whereSQL(conditions), subscriptionOrderBy(filter.Sort))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to export subscriptions", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result sqlc.Subscription
+		if err := scanSubscription(rows, &result); err != nil {
+			log.Error("failed to scan subscription", slog.String("error", err.Error()))
+			return r.handleError(err)
+		}
+		if err := fn(r.toDomain(&result)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate subscriptions", slog.String("error", err.Error()))
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
 // ListSubscriptionsByUserID возвращает подписки пользователя
 func (r *PostgresRepository) ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID, params domain.ListParams) ([]domain.Subscription, error) {
-	const op = "repository.ListSubscriptionsByUserID"
-	log := slog.With(slog.String("op", op), slog.String("user_id", userID.String()))
+	return r.ListSubscriptions(ctx, domain.ListFilter{UserID: &userID}, params)
+}
 
-	results, err := r.Queries.ListSubscriptionsByUserID(ctx, sqlc.ListSubscriptionsByUserIDParams{
-		UserID: userID,
-		Limit:  params.Limit,
-		Offset: params.Offset,
-	})
-	if err != nil {
-		log.Error("failed to list user subscriptions", slog.String("error", err.Error()))
-		return nil, r.handleError(err)
+// subscriptionSortSQL сопоставляет сортировку с ORDER BY. Значение сортировки
+// никогда не попадает в запрос напрямую, только через эту таблицу.
+var subscriptionSortSQL = map[domain.SubscriptionSort]string{
+	domain.SubscriptionSortDefault:     "s.created_at DESC, s.id DESC",
+	domain.SubscriptionSortPrice:       "s.price, s.id",
+	domain.SubscriptionSortPriceDesc:   "s.price DESC, s.id DESC",
+	domain.SubscriptionSortStartDate:   "s.start_date, s.id",
+	domain.SubscriptionSortServiceName: "s.service_name, s.id",
+}
+
+// listQuery строит запрос страницы списка подписок с условиями фильтра
+func (r *PostgresRepository) listQuery(orgID uuid.UUID, filter domain.ListFilter, params domain.ListParams) (string, []interface{}) {
+	conditions, args := r.listConditions(orgID, filter)
+	argIndex := len(args) + 1
+
+	offset := params.Offset
+	if params.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(s.created_at, s.id) < ($%d, $%d)", argIndex, argIndex+1))
+		args = append(args, params.Cursor.CreatedAt, params.Cursor.ID)
+		argIndex += 2
+		offset = 0
 	}
 
-    if ctx.Err()!=nil{
-        return nil,ctx.Err()
-    }
+	args = append(args, params.Limit, offset)
 
-	subs := make([]domain.Subscription, len(results))
-	for i, result := range results {
-		subs[i] = *r.toDomain(&result)
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM subscriptions s
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, subscriptionColumns, whereSQL(conditions), subscriptionOrderBy(filter.Sort), argIndex, argIndex+1), args
+}
+
+// subscriptionOrderBy возвращает ORDER BY для сортировки, неизвестная заменяется сортировкой по умолчанию
+func subscriptionOrderBy(sort domain.SubscriptionSort) string {
+	orderBy, ok := subscriptionSortSQL[sort]
+	if !ok {
+		return subscriptionSortSQL[domain.SubscriptionSortDefault]
 	}
+	return orderBy
+}
 
-	return subs, nil
+// listConditions строит условия WHERE для фильтра списка подписок организации orgID
+func (r *PostgresRepository) listConditions(orgID uuid.UUID, filter domain.ListFilter) ([]string, []interface{}) {
+	conditions := []string{"s.organization_id = $1"}
+	args := []interface{}{orgID}
+	argIndex := 2
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "s.deleted_at IS NULL")
+	}
+
+	if filter.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", argIndex))
+		args = append(args, *filter.UserID)
+		argIndex++
+	}
+
+	if filter.ServiceName != nil {
+		// Название сопоставляется с каталогом с учётом синонимов
+		conditions = append(conditions, fmt.Sprintf(
+			"s.service_id IN (SELECT a.service_id FROM service_aliases a WHERE a.organization_id = s.organization_id AND LOWER(a.alias) = LOWER($%d))", argIndex))
+		args = append(args, *filter.ServiceName)
+		argIndex++
+	}
+
+	if filter.ServiceNamePrefix != nil {
+		conditions = append(conditions, fmt.Sprintf("s.service_name ILIKE $%d", argIndex))
+		args = append(args, likeEscaper.Replace(*filter.ServiceNamePrefix)+"%")
+		argIndex++
+	}
+
+	if filter.PriceMin != nil {
+		conditions = append(conditions, fmt.Sprintf("s.price >= $%d", argIndex))
+		args = append(args, *filter.PriceMin)
+		argIndex++
+	}
+
+	if filter.PriceMax != nil {
+		conditions = append(conditions, fmt.Sprintf("s.price <= $%d", argIndex))
+		args = append(args, *filter.PriceMax)
+		argIndex++
+	}
+
+	if filter.ActiveIn != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"s.start_date <= $%[1]d AND (s.end_date IS NULL OR s.end_date >= $%[1]d)", argIndex))
+		args = append(args, *filter.ActiveIn)
+		argIndex++
+	}
+
+	if filter.StartFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("s.start_date >= $%d", argIndex))
+		args = append(args, *filter.StartFrom)
+		argIndex++
+	}
+
+	if filter.StartTo != nil {
+		conditions = append(conditions, fmt.Sprintf("s.start_date <= $%d", argIndex))
+		args = append(args, *filter.StartTo)
+		argIndex++
+	}
+
+	if filter.HasEndDate != nil {
+		if *filter.HasEndDate {
+			conditions = append(conditions, "s.end_date IS NOT NULL")
+		} else {
+			conditions = append(conditions, "s.end_date IS NULL")
+		}
+	}
+
+	return conditions, args
+}
+
+// whereSQL объединяет условия в WHERE; без условий — пустая строка
+func whereSQL(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
 }
 
+// likeEscaper экранирует спецсимволы шаблона LIKE во вводе пользователя
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // UpdateSubscription обновляет подписку
 func (r *PostgresRepository) UpdateSubscription(ctx context.Context, id int64, input domain.UpdateSubscriptionInput) (*domain.Subscription, error) {
 	const op = "repository.UpdateSubscription"
@@ -103,10 +411,31 @@ func (r *PostgresRepository) UpdateSubscription(ctx context.Context, id int64, i
         return nil,ctx.Err()
     }
 
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to update subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
+	if input.ServiceID != nil {
+		setParts = append(setParts, fmt.Sprintf("service_id = $%d", argIndex))
+		args = append(args, *input.ServiceID)
+		argIndex++
+	}
+
+	// Название без ID — новый сервис: он добавляется в каталог в транзакции изменения,
+	// и его ID подставляется в аргументы запроса
+	newService := input.ServiceID == nil && input.ServiceName != nil
+	if newService {
+		setParts = append(setParts, fmt.Sprintf("service_id = $%d", argIndex))
+		args = append(args, int64(0))
+		argIndex++
+	}
+
 	if input.ServiceName != nil {
 		setParts = append(setParts, fmt.Sprintf("service_name = $%d", argIndex))
 		args = append(args, *input.ServiceName)
@@ -119,6 +448,18 @@ func (r *PostgresRepository) UpdateSubscription(ctx context.Context, id int64, i
 		argIndex++
 	}
 
+	if input.Currency != nil {
+		setParts = append(setParts, fmt.Sprintf("currency = $%d", argIndex))
+		args = append(args, *input.Currency)
+		argIndex++
+	}
+
+	if input.BillingPeriod != nil {
+		setParts = append(setParts, fmt.Sprintf("billing_period = $%d", argIndex))
+		args = append(args, string(*input.BillingPeriod))
+		argIndex++
+	}
+
 	if input.EndDate != nil {
 		setParts = append(setParts, fmt.Sprintf("end_date = $%d", argIndex))
 		args = append(args, *input.EndDate)
@@ -126,28 +467,46 @@ func (r *PostgresRepository) UpdateSubscription(ctx context.Context, id int64, i
 	}
 
 	if len(setParts) == 0 {
-		return r.GetSubscriptionByID(ctx, id)
+		sub, err := r.GetSubscriptionByID(ctx, id)
+		if err == nil && input.IfVersion != nil && sub.Version != *input.IfVersion {
+			return nil, ErrVersionMismatch
+		}
+		return sub, err
 	}
 
-	args = append(args, id)
+	setParts = append(setParts, "version = version + 1")
+	args = append(args, id, orgID)
 
 	query := fmt.Sprintf(`
-		UPDATE subscriptions
+		UPDATE subscriptions s
 		SET %s
-		WHERE id = $%d
-		RETURNING id, service_name, price, user_id, start_date, end_date, created_at
-	`, strings.Join(setParts, ", "), argIndex)
+		WHERE s.id = $%d AND s.organization_id = $%d AND s.deleted_at IS NULL
+		RETURNING %s
+	`, strings.Join(setParts, ", "), argIndex, argIndex+1, subscriptionColumns)
 
-	var result sqlc.Subscription
-	err := r.DB.QueryRow(ctx, query, args...).Scan(
-		&result.ID,
-		&result.ServiceName,
-		&result.Price,
-		&result.UserID,
-		&result.StartDate,
-		&result.EndDate,
-		&result.CreatedAt,
-	)
+	result, err := r.mutateSubscription(ctx, id, input.IfVersion, domain.AuditActionUpdated, func(tx *PostgresRepository, _ uuid.UUID) (sqlc.Subscription, error) {
+		var result sqlc.Subscription
+		if newService {
+			service, err := tx.ensureService(ctx, *input.ServiceName)
+			if err != nil {
+				return result, err
+			}
+			// service_id и service_name — первые аргументы запроса
+			args[0], args[1] = service.ID, service.Name
+		}
+
+		err := scanSubscription(tx.DB.QueryRow(ctx, query, args...), &result)
+		if err != nil || input.Price == nil {
+			return result, err
+		}
+
+		// Новая цена действует с текущего месяца, прошлые месяцы считаются по старой
+		return result, tx.Queries.UpsertSubscriptionPrice(ctx, sqlc.UpsertSubscriptionPriceParams{
+			SubscriptionID: result.ID,
+			Price:          result.Price,
+			EffectiveFrom:  priceEffectiveFrom(result.StartDate, time.Now()),
+		})
+	})
 	if err != nil {
 		log.Error("failed to update subscription", slog.String("error", err.Error()))
 		return nil, r.handleError(err)
@@ -156,22 +515,208 @@ func (r *PostgresRepository) UpdateSubscription(ctx context.Context, id int64, i
 	return r.toDomain(&result), nil
 }
 
-// DeleteSubscription удаляет подписку
-func (r *PostgresRepository) DeleteSubscription(ctx context.Context, id int64) error {
+// PauseSubscription приостанавливает активную подписку начиная с месяца month
+func (r *PostgresRepository) PauseSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error) {
+	const op = "repository.PauseSubscription"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	result, err := r.mutateSubscription(ctx, id, nil, domain.AuditActionUpdated, func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error) {
+		result, err := tx.Queries.UpdateSubscriptionStatus(ctx, sqlc.UpdateSubscriptionStatusParams{
+			ID:             id,
+			OrganizationID: orgID,
+			Status:         sqlc.SubscriptionStatusPaused,
+			FromStatus:     sqlc.SubscriptionStatusActive,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		return result, tx.Queries.CreateSubscriptionPause(ctx, sqlc.CreateSubscriptionPauseParams{
+			SubscriptionID: id,
+			StartMonth:     month,
+		})
+	})
+	if err != nil {
+		log.Error("failed to pause subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.toDomain(&result), nil
+}
+
+// ResumeSubscription возобновляет приостановленную подписку с месяца month
+func (r *PostgresRepository) ResumeSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error) {
+	const op = "repository.ResumeSubscription"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	result, err := r.mutateSubscription(ctx, id, nil, domain.AuditActionUpdated, func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error) {
+		result, err := tx.Queries.UpdateSubscriptionStatus(ctx, sqlc.UpdateSubscriptionStatusParams{
+			ID:             id,
+			OrganizationID: orgID,
+			Status:         sqlc.SubscriptionStatusActive,
+			FromStatus:     sqlc.SubscriptionStatusPaused,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		return result, tx.closePause(ctx, id, month.AddDate(0, -1, 0))
+	})
+	if err != nil {
+		log.Error("failed to resume subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.toDomain(&result), nil
+}
+
+// CancelSubscription отменяет подписку: она заканчивается не позже месяца month
+func (r *PostgresRepository) CancelSubscription(ctx context.Context, id int64, month time.Time) (*domain.Subscription, error) {
+	const op = "repository.CancelSubscription"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	result, err := r.mutateSubscription(ctx, id, nil, domain.AuditActionUpdated, func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error) {
+		result, err := tx.Queries.CancelSubscription(ctx, sqlc.CancelSubscriptionParams{
+			ID:             id,
+			OrganizationID: orgID,
+			Month:          &month,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		return result, tx.closePause(ctx, id, month)
+	})
+	if err != nil {
+		log.Error("failed to cancel subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return r.toDomain(&result), nil
+}
+
+// closePause завершает открытую паузу подписки месяцем endMonth
+func (r *PostgresRepository) closePause(ctx context.Context, id int64, endMonth time.Time) error {
+	// Пауза, снятая в месяце её начала, не исключает ни одного месяца
+	if err := r.Queries.DeleteEmptySubscriptionPauses(ctx, sqlc.DeleteEmptySubscriptionPausesParams{
+		SubscriptionID: id,
+		EndMonth:       endMonth,
+	}); err != nil {
+		return err
+	}
+
+	return r.Queries.CloseSubscriptionPause(ctx, sqlc.CloseSubscriptionPauseParams{
+		SubscriptionID: id,
+		EndMonth:       &endMonth,
+	})
+}
+
+// DeleteSubscription помечает подписку удалённой. Строка остаётся в таблице до
+// PurgeDeletedSubscriptions, но исчезает из выборок и расчётов стоимости.
+// Если задан ifVersion, подписка удаляется только в этой версии.
+func (r *PostgresRepository) DeleteSubscription(ctx context.Context, id int64, ifVersion *int32) error {
 	const op = "repository.DeleteSubscription"
 	log := slog.With(slog.String("op", op), slog.Int64("id", id))
 
-	rowsAffected, err := r.Queries.DeleteSubscription(ctx, id)
+	_, err := r.mutateSubscription(ctx, id, ifVersion, domain.AuditActionDeleted, func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error) {
+		return tx.Queries.SoftDeleteSubscription(ctx, sqlc.SoftDeleteSubscriptionParams{
+			ID:             id,
+			OrganizationID: orgID,
+		})
+	})
 	if err != nil {
 		log.Error("failed to delete subscription", slog.String("error", err.Error()))
 		return r.handleError(err)
 	}
 
-	if rowsAffected == 0 {
-		return ErrNotFound
+	return nil
+}
+
+// RestoreSubscription снимает пометку удаления; ErrNotFound, если удалённой подписки с таким id нет
+func (r *PostgresRepository) RestoreSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	const op = "repository.RestoreSubscription"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	result, err := r.mutateSubscription(ctx, id, nil, domain.AuditActionRestored, func(tx *PostgresRepository, orgID uuid.UUID) (sqlc.Subscription, error) {
+		return tx.Queries.RestoreSubscription(ctx, sqlc.RestoreSubscriptionParams{
+			ID:             id,
+			OrganizationID: orgID,
+		})
+	})
+	if err != nil {
+		log.Error("failed to restore subscription", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
 	}
 
-	return nil
+	return r.toDomain(&result), nil
+}
+
+// PurgeDeletedSubscriptions окончательно удаляет подписки, помеченные удалёнными раньше deletedBefore,
+// вместе с историей цен и паузами всех организаций. Журнал изменений сохраняется.
+// Организации очищаются по очереди, каждая в своей транзакции. Возвращает число удалённых подписок.
+func (r *PostgresRepository) PurgeDeletedSubscriptions(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	const op = "repository.PurgeDeletedSubscriptions"
+	log := slog.With(slog.String("op", op), slog.Time("deleted_before", deletedBefore))
+
+	var purged int64
+	err := r.forEachOrganization(ctx, func(ctx context.Context, orgID uuid.UUID) error {
+		return r.withTx(ctx, func(tx *PostgresRepository) error {
+			subs, err := tx.Queries.PurgeDeletedSubscriptions(ctx, sqlc.PurgeDeletedSubscriptionsParams{
+				OrganizationID: orgID,
+				DeletedBefore:  &deletedBefore,
+			})
+			if err != nil {
+				return err
+			}
+
+			for i := range subs {
+				if err := tx.recordSubscriptionEvent(ctx, subs[i].ID, domain.AuditActionPurged, &subs[i], nil); err != nil {
+					return err
+				}
+			}
+			purged += int64(len(subs))
+			return nil
+		})
+	})
+	if err != nil {
+		log.Error("failed to purge subscriptions", slog.String("error", err.Error()))
+		return 0, r.handleError(err)
+	}
+
+	return purged, nil
+}
+
+// CountSubscriptionsByStatus подсчитывает неудалённые подписки всех организаций по статусам на момент now
+func (r *PostgresRepository) CountSubscriptionsByStatus(ctx context.Context, now time.Time) (map[domain.SubscriptionStatus]int64, error) {
+	const op = "repository.CountSubscriptionsByStatus"
+	log := slog.With(slog.String("op", op))
+
+	monthStart := domain.MonthStart(now)
+	counts := make(map[domain.SubscriptionStatus]int64)
+	err := r.forEachOrganization(ctx, func(ctx context.Context, orgID uuid.UUID) error {
+		rows, err := r.Queries.CountSubscriptionsByStatus(ctx, sqlc.CountSubscriptionsByStatusParams{
+			OrganizationID: orgID,
+			MonthStart:     &monthStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			status := domain.SubscriptionStatus(row.Status)
+			if row.Expired {
+				status = domain.SubscriptionStatusExpired
+			}
+			counts[status] += row.Count
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to count subscriptions", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return counts, nil
 }
 
 // CalculateTotalCost подсчитывает суммарную стоимость подписок за период
@@ -183,40 +728,460 @@ func (r *PostgresRepository) CalculateTotalCost(ctx context.Context, filter doma
         return domain.TotalCost{},ctx.Err()
     }
 
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to calculate total cost", slog.String("error", err.Error()))
+		return domain.TotalCost{}, r.handleError(err)
+	}
+
+	cte, args := r.chargesCTE(orgID, filter)
+	query := cte + `
+		SELECT COALESCE(ROUND(SUM(amount)), 0)::BIGINT AS total_cost, COUNT(DISTINCT id)::BIGINT AS count
+		FROM charges
+	`
+
+	var result domain.TotalCost
+	if err := r.DB.QueryRow(ctx, query, args...).Scan(&result.TotalCost, &result.Count); err != nil {
+		log.Error("failed to calculate total cost", slog.String("error", err.Error()))
+		return domain.TotalCost{}, r.handleError(err)
+	}
+
+	return result, nil
+}
+
+// CalculateMonthlyCost подсчитывает стоимость подписок по каждому месяцу периода
+func (r *PostgresRepository) CalculateMonthlyCost(ctx context.Context, filter domain.CostFilter) ([]domain.MonthlyCost, error) {
+	const op = "repository.CalculateMonthlyCost"
+	log := slog.With(slog.String("op", op))
+
+	// Помесячная разбивка имеет смысл только в режиме prorated
+	filter.Mode = domain.CostModeProrated
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to calculate monthly cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	cte, args := r.chargesCTE(orgID, filter)
+	query := cte + `
+		SELECT m.month, COALESCE(ROUND(SUM(c.amount)), 0)::BIGINT AS total_cost, COUNT(DISTINCT c.id)::BIGINT AS count
+		FROM months m
+		LEFT JOIN charges c ON c.month = m.month
+		GROUP BY m.month
+		ORDER BY m.month
+	`
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to calculate monthly cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+	defer rows.Close()
+
+	months := []domain.MonthlyCost{}
+	for rows.Next() {
+		var m domain.MonthlyCost
+		if err := rows.Scan(&m.Month, &m.TotalCost, &m.Count); err != nil {
+			log.Error("failed to scan monthly cost", slog.String("error", err.Error()))
+			return nil, r.handleError(err)
+		}
+		months = append(months, m)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate monthly cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return months, nil
+}
+
+// costGroupColumns — допустимые выражения группировки, в запрос подставляются только они
+var costGroupColumns = map[domain.CostGroupBy]string{
+	domain.CostGroupByServiceName: "service_name",
+	domain.CostGroupByUserID:      "user_id::TEXT",
+}
+
+// CalculateGroupedCost подсчитывает стоимость подписок по группам, отсортированным по убыванию стоимости
+func (r *PostgresRepository) CalculateGroupedCost(ctx context.Context, filter domain.CostFilter, params domain.CostGroupParams) ([]domain.CostGroup, error) {
+	const op = "repository.CalculateGroupedCost"
+	log := slog.With(slog.String("op", op), slog.String("group_by", string(params.GroupBy)))
+
+	column, ok := costGroupColumns[params.GroupBy]
+	if !ok {
+		log.Error("unknown group_by")
+		return nil, ErrInternal
+	}
+
+	// LIMIT NULL снимает ограничение
+	var limit *int32
+	if params.Limit > 0 {
+		limit = &params.Limit
+	}
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to calculate grouped cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	cte, args := r.chargesCTE(orgID, filter)
+	args = append(args, limit)
+	query := cte + fmt.Sprintf(`
+		SELECT %s AS key, COALESCE(ROUND(SUM(amount)), 0)::BIGINT AS total_cost, COUNT(DISTINCT id)::BIGINT AS count
+		FROM charges
+		GROUP BY key
+		ORDER BY total_cost DESC, key
+		LIMIT $%d
+	`, column, len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to calculate grouped cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+	defer rows.Close()
+
+	groups := []domain.CostGroup{}
+	for rows.Next() {
+		var g domain.CostGroup
+		if err := rows.Scan(&g.Key, &g.TotalCost, &g.Count); err != nil {
+			log.Error("failed to scan grouped cost", slog.String("error", err.Error()))
+			return nil, r.handleError(err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate grouped cost", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return groups, nil
+}
+
+// renewalChargeSQL — списание полной цены в месяцы продления подписки.
+// Недельная подписка списывается столько раз, сколько дат продления попало в месяц.
+const renewalChargeSQL = `CASE billing_period
+				WHEN 'weekly' THEN price::NUMERIC
+					* (((month + INTERVAL '1 month')::DATE - start_date - 1) / 7 - (month - start_date + 6) / 7 + 1)
+				WHEN 'quarterly' THEN CASE WHEN month_index % 3 = 0 THEN price::NUMERIC ELSE 0 END
+				WHEN 'yearly' THEN CASE WHEN month_index % 12 = 0 THEN price::NUMERIC ELSE 0 END
+				ELSE price::NUMERIC
+			END`
+
+// amortizedChargeSQL — цена, равномерно распределённая по месяцам
+const amortizedChargeSQL = `CASE billing_period
+				WHEN 'weekly' THEN price::NUMERIC * 52 / 12
+				WHEN 'quarterly' THEN price::NUMERIC / 3
+				WHEN 'yearly' THEN price::NUMERIC / 12
+				ELSE price::NUMERIC
+			END`
+
+// ListSubscriptionCosts возвращает подписки, начисления по которым попали в период,
+// со стоимостью за период в валюте фильтра
+func (r *PostgresRepository) ListSubscriptionCosts(ctx context.Context, filter domain.CostFilter) ([]domain.SubscriptionCost, error) {
+	const op = "repository.ListSubscriptionCosts"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list subscription costs", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	cte, args := r.chargesCTE(orgID, filter)
+	query := cte + fmt.Sprintf(`
+		SELECT %s, COALESCE(ROUND(c.total_cost), 0)::BIGINT AS total_cost
+		FROM subscriptions s
+		JOIN (SELECT id, SUM(amount) AS total_cost FROM charges GROUP BY id) c ON c.id = s.id
+		ORDER BY s.service_name, s.id
+	`, subscriptionColumns)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to list subscription costs", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+	defer rows.Close()
+
+	costs := []domain.SubscriptionCost{}
+	for rows.Next() {
+		var result sqlc.Subscription
+		var totalCost int64
+		if err := scanSubscription(rows, &result, &totalCost); err != nil {
+			log.Error("failed to scan subscription cost", slog.String("error", err.Error()))
+			return nil, r.handleError(err)
+		}
+		costs = append(costs, domain.SubscriptionCost{Subscription: *r.toDomain(&result), TotalCost: totalCost})
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate subscription costs", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return costs, nil
+}
+
+// ListMissingExchangeRates возвращает курсы, которых не хватает для пересчёта
+// ненулевых начислений периода в валюту фильтра
+func (r *PostgresRepository) ListMissingExchangeRates(ctx context.Context, filter domain.CostFilter) ([]domain.MissingExchangeRate, error) {
+	const op = "repository.ListMissingExchangeRates"
+	log := slog.With(slog.String("op", op))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list missing exchange rates", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	cte, args := r.rawChargesCTE(orgID, filter)
+	args = append(args, domain.BaseCurrency, r.costCurrency(filter))
+	query := cte + fmt.Sprintf(`,
+		required AS (
+			SELECT currency, month FROM raw_charges WHERE amount <> 0
+			UNION
+			SELECT $%[2]d::CHAR(3), month FROM raw_charges WHERE amount <> 0
+		)
+		SELECT q.currency, q.month
+		FROM required q
+		LEFT JOIN exchange_rates er ON er.organization_id = $3 AND er.currency = q.currency AND er.month = q.month
+		WHERE q.currency <> $%[1]d::CHAR(3) AND er.id IS NULL
+		ORDER BY q.month, q.currency
+	`, len(args)-1, len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Error("failed to list missing exchange rates", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+	defer rows.Close()
+
+	missing := []domain.MissingExchangeRate{}
+	for rows.Next() {
+		var m domain.MissingExchangeRate
+		if err := rows.Scan(&m.Currency, &m.Month); err != nil {
+			log.Error("failed to scan missing exchange rate", slog.String("error", err.Error()))
+			return nil, r.handleError(err)
+		}
+		missing = append(missing, m)
+	}
+	if err := rows.Err(); err != nil {
+		log.Error("failed to iterate missing exchange rates", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	return missing, nil
+}
+
+// ListSubscriptionPrices возвращает историю цен подписки по возрастанию месяца
+func (r *PostgresRepository) ListSubscriptionPrices(ctx context.Context, id int64) ([]domain.SubscriptionPrice, error) {
+	const op = "repository.ListSubscriptionPrices"
+	log := slog.With(slog.String("op", op), slog.Int64("id", id))
+
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		log.Error("failed to list subscription prices", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	results, err := r.Queries.ListSubscriptionPrices(ctx, sqlc.ListSubscriptionPricesParams{
+		SubscriptionID: id,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		log.Error("failed to list subscription prices", slog.String("error", err.Error()))
+		return nil, r.handleError(err)
+	}
+
+	prices := make([]domain.SubscriptionPrice, len(results))
+	for i, result := range results {
+		prices[i] = *domain.NewSubscriptionPrice(result.Price, result.EffectiveFrom, result.CreatedAt)
+	}
+
+	return prices, nil
+}
+
+// priceEffectiveFrom возвращает месяц, с которого действует изменённая цена:
+// текущий месяц, но не раньше начала подписки
+func priceEffectiveFrom(startDate time.Time, now time.Time) time.Time {
+	month := domain.MonthStart(now)
+	if month.Before(startDate) {
+		return startDate
+	}
+	return month
+}
+
+// priceSQL подзапрос цены подписки s, действующей в месяце month
+const priceSQL = `COALESCE((
+				SELECT h.price FROM subscription_price_history h
+				WHERE h.subscription_id = s.id AND h.effective_from <= %s
+				ORDER BY h.effective_from DESC
+				LIMIT 1
+			), s.price)`
+
+// monthIndexSQL порядковый номер месяца month от начала подписки s (с нуля)
+const monthIndexSQL = `((EXTRACT(YEAR FROM %[1]s) - EXTRACT(YEAR FROM s.start_date)) * 12
+					+ EXTRACT(MONTH FROM %[1]s) - EXTRACT(MONTH FROM s.start_date))::INT`
+
+// chargePriceSQL цена подписки s в месяце month: 0 в пробный период, вводная
+// цена в следующие intro_months месяцев, далее — цена из истории
+func chargePriceSQL(month string) string {
+	index := fmt.Sprintf(monthIndexSQL, month)
+	return fmt.Sprintf(`CASE
+				WHEN %[1]s < s.trial_months THEN 0
+				WHEN s.intro_price IS NOT NULL AND %[1]s < s.trial_months + s.intro_months THEN s.intro_price
+				ELSE %[2]s
+			END`, index, fmt.Sprintf(priceSQL, month))
+}
+
+// chargesCTE строит CTE charges с начислениями подписок организации orgID за период
+// фильтра, пересчитанными в валюту фильтра по курсу организации за месяц начисления
+func (r *PostgresRepository) chargesCTE(orgID uuid.UUID, filter domain.CostFilter) (string, []interface{}) {
+	cte, args := r.rawChargesCTE(orgID, filter)
+
+	args = append(args, domain.BaseCurrency)
+	base := len(args)
+
+	// Курс базовой валюты всегда 1, в exchange_rates его нет.
+	// Организация передана в rawChargesCTE третьим аргументом
+	divisor, targetJoin := "1", ""
+	if currency := r.costCurrency(filter); currency != domain.BaseCurrency {
+		args = append(args, currency)
+		divisor = "dst.rate"
+		targetJoin = fmt.Sprintf("LEFT JOIN exchange_rates dst ON dst.organization_id = $3 AND dst.currency = $%d::CHAR(3) AND dst.month = c.month", len(args))
+	}
+
+	return cte + fmt.Sprintf(`,
+		charges AS (
+			SELECT c.id, c.user_id, c.service_name, c.month,
+				c.amount * CASE WHEN c.currency = $%d::CHAR(3) THEN 1 ELSE src.rate END / %s AS amount
+			FROM raw_charges c
+			LEFT JOIN exchange_rates src ON src.organization_id = $3 AND src.currency = c.currency AND src.month = c.month
+			%s
+		)`, base, divisor, targetJoin), args
+}
+
+// costCurrency возвращает валюту результата расчёта стоимости
+func (r *PostgresRepository) costCurrency(filter domain.CostFilter) string {
+	if filter.Currency == "" {
+		return domain.BaseCurrency
+	}
+	return filter.Currency
+}
+
+// rawChargesCTE строит CTE raw_charges с начислениями подписок организации orgID
+// за период фильтра в валюте подписки. В режиме prorated — строка на каждый месяц подписки внутри
+// периода, кроме месяцев приостановки, с суммой, приведённой к месяцу по
+// периодичности списания; в режиме
+// overlap — одна строка с ценой на подписку, пересекающую период.
+// Цена месяца учитывает пробный период и вводную цену (см. chargePriceSQL).
+func (r *PostgresRepository) rawChargesCTE(orgID uuid.UUID, filter domain.CostFilter) (string, []interface{}) {
 	// Конец периода = последний день месяца
 	endPeriod := filter.EndPeriod.AddDate(0, 1, -1)
 
-	// Динамический запрос с опциональными фильтрами
-	query := `
-		SELECT COALESCE(SUM(price), 0)::BIGINT AS total_cost, COUNT(*)::BIGINT AS count
-		FROM subscriptions
-		WHERE start_date <= $1
-		  AND (end_date IS NULL OR end_date >= $2)
-	`
-	args := []interface{}{endPeriod, filter.StartPeriod}
-	argIndex := 3
+	// Динамические условия с опциональными фильтрами
+	conditions := []string{
+		"s.start_date <= $1",
+		"(s.end_date IS NULL OR s.end_date >= $2)",
+		"s.organization_id = $3",
+		"s.deleted_at IS NULL",
+	}
+	args := []interface{}{endPeriod, filter.StartPeriod, orgID}
+	argIndex := 4
 
 	if filter.UserID != nil {
-		query += fmt.Sprintf(" AND user_id = $%d", argIndex)
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", argIndex))
 		args = append(args, *filter.UserID)
 		argIndex++
 	}
 
 	if filter.ServiceName != nil {
-		query += fmt.Sprintf(" AND service_name = $%d", argIndex)
+		// Название сопоставляется с каталогом с учётом синонимов и регистра
+		conditions = append(conditions, fmt.Sprintf(
+			"s.service_id IN (SELECT a.service_id FROM service_aliases a WHERE a.organization_id = s.organization_id AND LOWER(a.alias) = LOWER($%d))", argIndex))
 		args = append(args, *filter.ServiceName)
 	}
 
-	var result domain.TotalCost
-	if err := r.DB.QueryRow(ctx, query, args...).Scan(&result.TotalCost, &result.Count); err != nil {
-		log.Error("failed to calculate total cost", slog.String("error", err.Error()))
-		return domain.TotalCost{}, r.handleError(err)
+	where := strings.Join(conditions, " AND ")
+
+	if filter.Mode != domain.CostModeProrated {
+		return fmt.Sprintf(`
+		WITH raw_charges AS (
+			SELECT s.id, s.user_id, s.service_name, s.currency, GREATEST(s.start_date, $2::DATE) AS month,
+				%s::NUMERIC AS amount
+			FROM subscriptions s
+			WHERE %s
+		)`, chargePriceSQL("GREATEST(s.start_date, $2::DATE)"), where), args
 	}
 
-	return result, nil
+	charge := renewalChargeSQL
+	if filter.Amortize {
+		charge = amortizedChargeSQL
+	}
+
+	return fmt.Sprintf(`
+		WITH months AS (
+			SELECT generate_series($2::DATE, $1::DATE, INTERVAL '1 month')::DATE AS month
+		),
+		active AS (
+			SELECT s.id, s.user_id, s.service_name, s.currency, %s AS price, s.billing_period, s.start_date, m.month,
+				%s AS month_index
+			FROM subscriptions s
+			JOIN months m ON m.month >= s.start_date AND (s.end_date IS NULL OR m.month <= s.end_date)
+			WHERE %s
+				AND NOT EXISTS (
+					SELECT 1 FROM subscription_pauses p
+					WHERE p.subscription_id = s.id AND m.month >= p.start_month
+						AND (p.end_month IS NULL OR m.month <= p.end_month)
+				)
+		),
+		raw_charges AS (
+			SELECT id, user_id, service_name, currency, month, %s AS amount
+			FROM active
+		)`, chargePriceSQL("m.month"), fmt.Sprintf(monthIndexSQL, "m.month"), where, charge), args
+}
+
+// subscriptionColumns колонки подписки s в порядке scanSubscription
+const subscriptionColumns = `s.id, s.service_name, s.price, s.user_id, s.start_date, s.end_date, s.created_at,
+			s.billing_period, s.currency, s.service_id, s.status, s.trial_months, s.intro_price, s.intro_months, s.deleted_at, s.version,
+			s.organization_id`
+
+// scanSubscription сканирует строку динамического запроса с колонками subscriptionColumns;
+// extra получает колонки, выбранные после них
+func scanSubscription(row pgx.Row, s *sqlc.Subscription, extra ...any) error {
+	dest := []any{
+		&s.ID,
+		&s.ServiceName,
+		&s.Price,
+		&s.UserID,
+		&s.StartDate,
+		&s.EndDate,
+		&s.CreatedAt,
+		&s.BillingPeriod,
+		&s.Currency,
+		&s.ServiceID,
+		&s.Status,
+		&s.TrialMonths,
+		&s.IntroPrice,
+		&s.IntroMonths,
+		&s.DeletedAt,
+		&s.Version,
+		&s.OrganizationID,
+	}
+	return row.Scan(append(dest, extra...)...)
 }
 
 // toDomain конвертирует sqlc модель в domain
 func (r *PostgresRepository) toDomain(s *sqlc.Subscription) *domain.Subscription {
-	return domain.NewSubscription(s.ID, s.ServiceName, s.Price, s.UserID, s.StartDate, s.EndDate, s.CreatedAt)
+	status := domain.SubscriptionStatusAt(domain.SubscriptionStatus(s.Status), s.EndDate, time.Now())
+	intro := domain.IntroTerms{
+		TrialMonths: s.TrialMonths,
+		IntroPrice:  s.IntroPrice,
+		IntroMonths: s.IntroMonths,
+	}
+	sub := domain.NewSubscription(s.ID, s.ServiceID, s.ServiceName, s.Price, s.Currency, domain.BillingPeriod(s.BillingPeriod),
+		status, intro, s.UserID, s.StartDate, s.EndDate, s.CreatedAt)
+	sub.DeletedAt = s.DeletedAt
+	sub.Version = s.Version
+	return sub
 }
@@ -0,0 +1,119 @@
+//go:build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAPIKey(t *testing.T, name, hash string, expiresAt *time.Time) *domain.APIKey {
+	t.Helper()
+	userID := uuid.New()
+	key, err := testRepo.CreateAPIKey(testContext(), domain.CreateAPIKeyInput{
+		Name:      name,
+		Scopes:    []domain.Scope{domain.ScopeSubscriptionsRead, domain.ScopeSubscriptionsWrite},
+		UserID:    &userID,
+		ExpiresAt: expiresAt,
+		CreatedBy: "admin",
+		Prefix:    "em_" + name,
+		KeyHash:   hash,
+	})
+	require.NoError(t, err)
+	return key
+}
+
+func TestAPIKeys(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	key := createTestAPIKey(t, "billing", "hash-1", nil)
+
+	t.Run("created", func(t *testing.T) {
+		assert.NotZero(t, key.ID)
+		assert.Equal(t, "billing", key.Name)
+		assert.Equal(t, "em_billing", key.Prefix)
+		assert.Equal(t, []domain.Scope{domain.ScopeSubscriptionsRead, domain.ScopeSubscriptionsWrite}, key.Scopes)
+		assert.NotNil(t, key.UserID)
+		assert.Equal(t, "admin", key.CreatedBy)
+		assert.Nil(t, key.ExpiresAt)
+		assert.Nil(t, key.LastUsedAt)
+		assert.Nil(t, key.RevokedAt)
+	})
+
+	t.Run("duplicate hash", func(t *testing.T) {
+		_, err := testRepo.CreateAPIKey(ctx, domain.CreateAPIKeyInput{
+			Name:      "copy",
+			Scopes:    []domain.Scope{domain.ScopeAuditRead},
+			CreatedBy: "admin",
+			Prefix:    "em_copy",
+			KeyHash:   "hash-1",
+		})
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+
+	t.Run("get active by hash", func(t *testing.T) {
+		// Организация запроса ещё неизвестна: её определяет найденный ключ
+		found, err := testRepo.GetActiveAPIKeyByHash(context.Background(), "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, found.ID)
+		assert.Equal(t, domain.DefaultOrganizationID, found.OrganizationID)
+
+		_, err = testRepo.GetActiveAPIKeyByHash(context.Background(), "unknown")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("expired key is not active", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		createTestAPIKey(t, "expired", "hash-expired", &expiresAt)
+
+		_, err := testRepo.GetActiveAPIKeyByHash(ctx, "hash-expired")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		usedAt := time.Now().Truncate(time.Second)
+		require.NoError(t, testRepo.TouchAPIKey(ctx, key.ID, usedAt))
+
+		found, err := testRepo.GetActiveAPIKeyByHash(ctx, "hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, found.LastUsedAt)
+		assert.True(t, usedAt.Equal(*found.LastUsedAt))
+	})
+
+	t.Run("list newest first", func(t *testing.T) {
+		keys, err := testRepo.ListAPIKeys(ctx, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "expired", keys[0].Name)
+		assert.Equal(t, "billing", keys[1].Name)
+
+		keys, err = testRepo.ListAPIKeys(ctx, domain.ListParams{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "billing", keys[0].Name)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		revoked, err := testRepo.RevokeAPIKey(ctx, key.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, revoked.RevokedAt)
+
+		_, err = testRepo.GetActiveAPIKeyByHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.RevokeAPIKey(ctx, key.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		keys, err := testRepo.ListAPIKeys(ctx, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, keys, 2)
+	})
+}
@@ -0,0 +1,180 @@
+//go:build integration
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subscriptionHistory(t *testing.T, id int64) []domain.SubscriptionEvent {
+	t.Helper()
+	events, err := testRepo.ListSubscriptionEvents(testContext(),
+		domain.AuditFilter{SubscriptionID: &id}, domain.ListParams{Limit: 100})
+	require.NoError(t, err)
+	return events
+}
+
+func snapshotField(t *testing.T, snapshot json.RawMessage, field string) any {
+	t.Helper()
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(snapshot, &fields))
+	return fields[field]
+}
+
+func TestSubscriptionEvents(t *testing.T) {
+	ctx := domain.WithAuditMeta(testContext(), domain.AuditMeta{Actor: "admin", RequestID: "req-1"})
+	cleanup(t)
+
+	sub, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 100, uuid.New()))
+	require.NoError(t, err)
+
+	t.Run("create is recorded with after snapshot", func(t *testing.T) {
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 1)
+
+		assert.Equal(t, domain.AuditActionCreated, events[0].Action)
+		assert.Nil(t, events[0].Before)
+		assert.Equal(t, float64(100), snapshotField(t, events[0].After, "price"))
+		assert.Equal(t, "admin", events[0].Actor)
+		assert.Equal(t, "req-1", events[0].RequestID)
+	})
+
+	t.Run("update is recorded with before and after", func(t *testing.T) {
+		_, err := testRepo.UpdateSubscription(ctx, sub.ID, domain.UpdateSubscriptionInput{Price: ptr(150)})
+		require.NoError(t, err)
+
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 2)
+
+		assert.Equal(t, domain.AuditActionUpdated, events[0].Action)
+		assert.Equal(t, float64(100), snapshotField(t, events[0].Before, "price"))
+		assert.Equal(t, float64(150), snapshotField(t, events[0].After, "price"))
+	})
+
+	t.Run("empty update is not recorded", func(t *testing.T) {
+		_, err := testRepo.UpdateSubscription(ctx, sub.ID, domain.UpdateSubscriptionInput{})
+		require.NoError(t, err)
+
+		assert.Len(t, subscriptionHistory(t, sub.ID), 2)
+	})
+
+	t.Run("status changes are recorded", func(t *testing.T) {
+		_, err := testRepo.PauseSubscription(ctx, sub.ID, month(2025, time.August))
+		require.NoError(t, err)
+
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 3)
+		assert.Equal(t, domain.AuditActionUpdated, events[0].Action)
+		assert.Equal(t, "active", snapshotField(t, events[0].Before, "status"))
+		assert.Equal(t, "paused", snapshotField(t, events[0].After, "status"))
+	})
+
+	t.Run("delete and restore are recorded", func(t *testing.T) {
+		require.NoError(t, testRepo.DeleteSubscription(ctx, sub.ID, nil))
+		_, err := testRepo.RestoreSubscription(ctx, sub.ID)
+		require.NoError(t, err)
+
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 5)
+		assert.Equal(t, domain.AuditActionRestored, events[0].Action)
+		assert.Equal(t, domain.AuditActionDeleted, events[1].Action)
+		assert.Nil(t, snapshotField(t, events[1].Before, "deleted_at"))
+		assert.NotNil(t, snapshotField(t, events[1].After, "deleted_at"))
+	})
+
+	t.Run("failed mutation is not recorded", func(t *testing.T) {
+		_, err := testRepo.RestoreSubscription(ctx, sub.ID)
+		require.Error(t, err)
+
+		assert.Len(t, subscriptionHistory(t, sub.ID), 5)
+	})
+
+	t.Run("purge is recorded and history survives it", func(t *testing.T) {
+		require.NoError(t, testRepo.DeleteSubscription(ctx, sub.ID, nil))
+
+		purgeCtx := domain.WithAuditMeta(context.Background(), domain.AuditMeta{Actor: "system"})
+		purged, err := testRepo.PurgeDeletedSubscriptions(purgeCtx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 7)
+		assert.Equal(t, domain.AuditActionPurged, events[0].Action)
+		assert.Equal(t, "system", events[0].Actor)
+		assert.Empty(t, events[0].RequestID)
+		assert.NotNil(t, events[0].Before)
+		assert.Nil(t, events[0].After)
+	})
+}
+
+func TestListSubscriptionEvents(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	aliceCtx := domain.WithAuditMeta(ctx, domain.AuditMeta{Actor: "alice", RequestID: "req-alice"})
+	bobCtx := domain.WithAuditMeta(ctx, domain.AuditMeta{Actor: "bob", RequestID: "req-bob"})
+
+	first, _ := testRepo.CreateSubscription(aliceCtx, createTestInput("Netflix", 100, uuid.New()))
+	second, _ := testRepo.CreateSubscription(bobCtx, createTestInput("Spotify", 200, uuid.New()))
+	require.NoError(t, testRepo.DeleteSubscription(bobCtx, first.ID, nil))
+
+	list := func(t *testing.T, filter domain.AuditFilter) []domain.SubscriptionEvent {
+		t.Helper()
+		events, err := testRepo.ListSubscriptionEvents(ctx, filter, domain.ListParams{Limit: 100})
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("no filter returns newest first", func(t *testing.T) {
+		events := list(t, domain.AuditFilter{})
+		require.Len(t, events, 3)
+		assert.Equal(t, domain.AuditActionDeleted, events[0].Action)
+		assert.Equal(t, second.ID, events[1].SubscriptionID)
+		assert.Equal(t, first.ID, events[2].SubscriptionID)
+	})
+
+	t.Run("by subscription", func(t *testing.T) {
+		assert.Len(t, list(t, domain.AuditFilter{SubscriptionID: &second.ID}), 1)
+	})
+
+	t.Run("by action", func(t *testing.T) {
+		events := list(t, domain.AuditFilter{Action: ptr(domain.AuditActionCreated)})
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("by actor", func(t *testing.T) {
+		events := list(t, domain.AuditFilter{Actor: ptr("bob")})
+		require.Len(t, events, 2)
+		for _, event := range events {
+			assert.Equal(t, "bob", event.Actor)
+		}
+	})
+
+	t.Run("by request id", func(t *testing.T) {
+		events := list(t, domain.AuditFilter{RequestID: ptr("req-alice")})
+		require.Len(t, events, 1)
+		assert.Equal(t, first.ID, events[0].SubscriptionID)
+	})
+
+	t.Run("by period", func(t *testing.T) {
+		now := time.Now()
+		assert.Len(t, list(t, domain.AuditFilter{From: ptr(now.Add(-time.Hour)), To: ptr(now.Add(time.Hour))}), 3)
+		assert.Empty(t, list(t, domain.AuditFilter{From: ptr(now.Add(time.Hour))}))
+		assert.Empty(t, list(t, domain.AuditFilter{To: ptr(now.Add(-time.Hour))}))
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		events, err := testRepo.ListSubscriptionEvents(ctx, domain.AuditFilter{}, domain.ListParams{Limit: 2, Offset: 2})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, first.ID, events[0].SubscriptionID)
+	})
+}
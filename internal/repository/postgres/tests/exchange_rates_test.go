@@ -0,0 +1,181 @@
+//go:build integration
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func createTestRate(t *testing.T, currency string, m time.Time, rate float64) *domain.ExchangeRate {
+	t.Helper()
+	input := domain.NewCreateExchangeRateInput(currency, m, rate)
+	result, err := testRepo.CreateExchangeRate(testContext(), &input)
+	require.NoError(t, err)
+	return result
+}
+
+// ==================== ExchangeRates CRUD ====================
+
+func TestExchangeRatesCRUD(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("create and get", func(t *testing.T) {
+		cleanup(t)
+
+		created := createTestRate(t, "USD", month(2025, time.January), 92.5)
+		assert.NotZero(t, created.ID)
+		assert.Equal(t, "USD", created.Currency)
+		assert.Equal(t, month(2025, time.January), created.Month)
+		assert.InDelta(t, 92.5, created.Rate, 1e-9)
+
+		result, err := testRepo.GetExchangeRateByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, result.ID)
+		assert.InDelta(t, 92.5, result.Rate, 1e-9)
+	})
+
+	t.Run("duplicate currency and month", func(t *testing.T) {
+		cleanup(t)
+
+		createTestRate(t, "USD", month(2025, time.January), 92.5)
+
+		input := domain.NewCreateExchangeRateInput("USD", month(2025, time.January), 95)
+		result, err := testRepo.CreateExchangeRate(ctx, &input)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+
+	t.Run("list with currency filter", func(t *testing.T) {
+		cleanup(t)
+
+		createTestRate(t, "USD", month(2025, time.January), 92.5)
+		createTestRate(t, "USD", month(2025, time.February), 93)
+		createTestRate(t, "EUR", month(2025, time.January), 100)
+
+		all, err := testRepo.ListExchangeRates(ctx, domain.ExchangeRateFilter{}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		usd, err := testRepo.ListExchangeRates(ctx, domain.ExchangeRateFilter{Currency: ptr("USD")}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, usd, 2)
+		assert.Equal(t, month(2025, time.February), usd[0].Month)
+		assert.Equal(t, month(2025, time.January), usd[1].Month)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		cleanup(t)
+
+		created := createTestRate(t, "USD", month(2025, time.January), 92.5)
+
+		updated, err := testRepo.UpdateExchangeRate(ctx, created.ID, 90)
+		require.NoError(t, err)
+		assert.InDelta(t, 90, updated.Rate, 1e-9)
+
+		require.NoError(t, testRepo.DeleteExchangeRate(ctx, created.ID))
+
+		_, err = testRepo.GetExchangeRateByID(ctx, created.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.ErrorIs(t, testRepo.DeleteExchangeRate(ctx, created.ID), repository.ErrNotFound)
+	})
+
+	t.Run("update not found", func(t *testing.T) {
+		cleanup(t)
+
+		result, err := testRepo.UpdateExchangeRate(ctx, 99999, 90)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// ==================== Currency conversion ====================
+
+func TestCalculateTotalCost_Currency(t *testing.T) {
+	ctx := testContext()
+
+	filter := domain.CostFilter{
+		StartPeriod: month(2025, time.January),
+		EndPeriod:   month(2025, time.February),
+		Mode:        domain.CostModeProrated,
+	}
+
+	t.Run("converts foreign currency to base by monthly rate", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Netflix", 10, uuid.New())
+		input.Currency = "USD"
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+		_, err = testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+		require.NoError(t, err)
+
+		createTestRate(t, "USD", month(2025, time.January), 90)
+		createTestRate(t, "USD", month(2025, time.February), 100)
+
+		result, err := testRepo.CalculateTotalCost(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*90+10*100+400*2), result.TotalCost)
+		assert.Equal(t, int64(2), result.Count)
+	})
+
+	t.Run("converts base currency to target", func(t *testing.T) {
+		cleanup(t)
+
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 1000, uuid.New()))
+		require.NoError(t, err)
+
+		createTestRate(t, "USD", month(2025, time.January), 100)
+		createTestRate(t, "USD", month(2025, time.February), 50)
+
+		usd := filter
+		usd.Currency = "USD"
+		result, err := testRepo.CalculateTotalCost(ctx, usd)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1000/100+1000/50), result.TotalCost)
+	})
+
+	t.Run("lists missing rates", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Netflix", 10, uuid.New())
+		input.Currency = "USD"
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+
+		createTestRate(t, "USD", month(2025, time.January), 90)
+
+		missing, err := testRepo.ListMissingExchangeRates(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.MissingExchangeRate{
+			{Currency: "USD", Month: month(2025, time.February)},
+		}, missing)
+	})
+
+	t.Run("base currency needs no rates", func(t *testing.T) {
+		cleanup(t)
+
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+		require.NoError(t, err)
+
+		missing, err := testRepo.ListMissingExchangeRates(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+}
@@ -0,0 +1,133 @@
+//go:build integration
+
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("reserve new key", func(t *testing.T) {
+		record, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-1", expiresAt)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, "hash-1", record.RequestHash)
+		assert.Nil(t, record.Response)
+	})
+
+	t.Run("reserved key without response", func(t *testing.T) {
+		record, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-2", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, "hash-1", record.RequestHash)
+		assert.Nil(t, record.Response)
+	})
+
+	t.Run("saved response is returned", func(t *testing.T) {
+		require.NoError(t, testRepo.SaveIdempotentResponse(ctx, "key-1", domain.IdempotentResponse{
+			StatusCode:  http.StatusCreated,
+			Header:      map[string][]string{"Content-Type": {"application/json"}},
+			Body:        []byte(`{"id":1}`),
+			RequestHash: "hash-1",
+		}))
+
+		record, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-1", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, record.Response)
+		assert.Equal(t, http.StatusCreated, record.Response.StatusCode)
+		assert.Equal(t, []string{"application/json"}, record.Response.Header["Content-Type"])
+		assert.JSONEq(t, `{"id":1}`, string(record.Response.Body))
+		assert.Equal(t, "hash-1", record.Response.RequestHash)
+	})
+
+	t.Run("streamed request hash is saved with response", func(t *testing.T) {
+		_, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-5", "", expiresAt)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		require.NoError(t, testRepo.SaveIdempotentResponse(ctx, "key-5", domain.IdempotentResponse{
+			StatusCode:  http.StatusOK,
+			Body:        []byte(`{}`),
+			RequestHash: "hash-5",
+		}))
+
+		record, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-5", "", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, "hash-5", record.RequestHash)
+		require.NotNil(t, record.Response)
+		assert.Equal(t, "hash-5", record.Response.RequestHash)
+	})
+
+	t.Run("release keeps completed key", func(t *testing.T) {
+		require.NoError(t, testRepo.ReleaseIdempotencyKey(ctx, "key-1"))
+
+		_, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-1", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	t.Run("release frees pending key", func(t *testing.T) {
+		_, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-2", "hash-1", expiresAt)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		require.NoError(t, testRepo.ReleaseIdempotencyKey(ctx, "key-2"))
+
+		_, reserved, err = testRepo.ReserveIdempotencyKey(ctx, "key-2", "hash-2", expiresAt)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("expired key is reserved again", func(t *testing.T) {
+		_, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-3", "hash-1", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		record, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-3", "hash-2", expiresAt)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, "hash-2", record.RequestHash)
+	})
+
+	t.Run("keys are per organization", func(t *testing.T) {
+		otherCtx := createTestOrganization(t, "marketing")
+
+		record, reserved, err := testRepo.ReserveIdempotencyKey(otherCtx, "key-1", "hash-9", expiresAt)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, "hash-9", record.RequestHash)
+		assert.Nil(t, record.Response)
+
+		record, reserved, err = testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-1", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, record.Response)
+		assert.Equal(t, "hash-1", record.RequestHash)
+	})
+
+	t.Run("purge expired keys", func(t *testing.T) {
+		_, _, err := testRepo.ReserveIdempotencyKey(ctx, "key-4", "hash-1", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+
+		purged, err := testRepo.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		_, reserved, err := testRepo.ReserveIdempotencyKey(ctx, "key-1", "hash-1", expiresAt)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+	})
+}
@@ -0,0 +1,286 @@
+//go:build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestOrganization возвращает контекст запроса в организации name, создавая её при необходимости
+func createTestOrganization(t *testing.T, name string) context.Context {
+	t.Helper()
+	var id uuid.UUID
+	err := testDB.QueryRow(context.Background(), `
+		INSERT INTO organizations (id, name) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, uuid.New(), name).Scan(&id)
+	require.NoError(t, err)
+	return domain.WithOrganization(context.Background(), id)
+}
+
+// createOrganizationInput входные данные подписки другой организации: сервис
+// по названию добавляется в каталог этой организации
+func createOrganizationInput(serviceName string, price int32, userID uuid.UUID) *domain.CreateSubscriptionInput {
+	input := createTestInput(serviceName, price, userID)
+	input.ServiceID = 0
+	return input
+}
+
+func TestOrganizationIsolation(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	otherCtx := createTestOrganization(t, "marketing")
+	userID := uuid.New()
+
+	own, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 500, userID))
+	require.NoError(t, err)
+	other, err := testRepo.CreateSubscription(otherCtx, createOrganizationInput("Spotify", 300, userID))
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		_, err := testRepo.GetSubscriptionByID(ctx, other.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.GetSubscriptionOwner(ctx, other.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		prices, err := testRepo.ListSubscriptionPrices(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, prices)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		subs, err := testRepo.ListSubscriptionsByUserID(ctx, userID, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, own.ID, subs[0].ID)
+
+		total, err := testRepo.CountSubscriptions(otherCtx, domain.ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("mutations", func(t *testing.T) {
+		_, err := testRepo.UpdateSubscription(ctx, other.ID, domain.UpdateSubscriptionInput{Price: ptr(100)})
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.PauseSubscription(ctx, other.ID, domain.MonthStart(time.Now()))
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		err = testRepo.DeleteSubscription(ctx, other.ID, nil)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		sub, err := testRepo.GetSubscriptionByID(otherCtx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(300), sub.Price)
+	})
+
+	t.Run("cost", func(t *testing.T) {
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			UserID:      &userID,
+		}
+
+		total, err := testRepo.CalculateTotalCost(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TotalCost{TotalCost: 500, Count: 1}, total)
+
+		total, err = testRepo.CalculateTotalCost(otherCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TotalCost{TotalCost: 300, Count: 1}, total)
+	})
+
+	t.Run("services", func(t *testing.T) {
+		_, err := testRepo.GetServiceByName(ctx, "Spotify")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.GetServiceByID(ctx, other.ServiceID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.UpdateService(ctx, other.ServiceID, domain.UpdateServiceInput{Name: ptr("Spotify Premium")})
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		assert.ErrorIs(t, testRepo.DeleteService(ctx, other.ServiceID), repository.ErrNotFound)
+
+		services, err := testRepo.ListServices(otherCtx, domain.ServiceFilter{}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+		assert.Equal(t, "Spotify", services[0].Name)
+
+		// Такое же название в другой организации — отдельный сервис
+		input := domain.NewCreateServiceInput("Netflix", nil, nil, nil)
+		service, err := testRepo.CreateService(otherCtx, &input)
+		require.NoError(t, err)
+		assert.NotEqual(t, own.ServiceID, service.ID)
+
+		// Чужой сервис нельзя указать в подписке
+		foreign := createTestInput("Netflix", 500, userID)
+		foreign.ServiceID = service.ID
+		_, err = testRepo.CreateSubscription(ctx, foreign)
+		assert.ErrorIs(t, err, repository.ErrInUse)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		events, err := testRepo.ListSubscriptionEvents(otherCtx, domain.AuditFilter{}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, other.ID, events[0].SubscriptionID)
+	})
+
+	t.Run("exchange rates", func(t *testing.T) {
+		january := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		createTestRate(t, "USD", january, 100)
+		// Курс на тот же месяц в другой организации не конфликтует с нашим
+		input := domain.NewCreateExchangeRateInput("USD", january, 50)
+		rate, err := testRepo.CreateExchangeRate(otherCtx, &input)
+		require.NoError(t, err)
+
+		_, err = testRepo.GetExchangeRateByID(ctx, rate.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.UpdateExchangeRate(ctx, rate.ID, 1)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		assert.ErrorIs(t, testRepo.DeleteExchangeRate(ctx, rate.ID), repository.ErrNotFound)
+
+		rates, err := testRepo.ListExchangeRates(otherCtx, domain.ExchangeRateFilter{}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, 50.0, rates[0].Rate)
+
+		// Стоимость пересчитывается по курсам своей организации
+		filter := domain.CostFilter{StartPeriod: january, EndPeriod: january, UserID: &userID, Currency: "USD"}
+
+		total, err := testRepo.CalculateTotalCost(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(500/100), total.TotalCost)
+
+		total, err = testRepo.CalculateTotalCost(otherCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(300/50), total.TotalCost)
+	})
+
+	t.Run("no organization", func(t *testing.T) {
+		_, err := testRepo.GetSubscriptionByID(context.Background(), own.ID)
+		assert.ErrorIs(t, err, repository.ErrInternal)
+
+		_, err = testRepo.CreateSubscription(context.Background(), createTestInput("Netflix", 500, userID))
+		assert.ErrorIs(t, err, repository.ErrInternal)
+	})
+}
+
+func TestCalculateOrganizationCost(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	otherCtx := createTestOrganization(t, "marketing")
+
+	for _, userID := range []uuid.UUID{uuid.New(), uuid.New()} {
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 500, userID))
+		require.NoError(t, err)
+	}
+	_, err := testRepo.CreateSubscription(otherCtx, createOrganizationInput("Netflix", 700, uuid.New()))
+	require.NoError(t, err)
+
+	filter := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	result, err := testRepo.CalculateOrganizationCost(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultOrganizationID, result.Organization.ID)
+	assert.Equal(t, "default", result.Organization.Name)
+	assert.Equal(t, int64(3000), result.TotalCost)
+	assert.Equal(t, int64(2), result.Count)
+	assert.Equal(t, int64(2), result.UserCount)
+
+	t.Run("empty period", func(t *testing.T) {
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		result, err := testRepo.CalculateOrganizationCost(otherCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, "marketing", result.Organization.Name)
+		assert.Zero(t, result.TotalCost)
+		assert.Zero(t, result.UserCount)
+	})
+}
+
+// TestOrganizationRowLevelSecurity проверяет сами политики RLS, без условий запросов
+// репозитория: тестовый репозиторий, как и приложение, работает под ролью subscriptions_app
+func TestOrganizationRowLevelSecurity(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	otherCtx := createTestOrganization(t, "marketing")
+	otherID, _ := domain.OrganizationFrom(otherCtx)
+
+	_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 500, uuid.New()))
+	require.NoError(t, err)
+	other, err := testRepo.CreateSubscription(otherCtx, createOrganizationInput("Spotify", 300, uuid.New()))
+	require.NoError(t, err)
+	_, err = testRepo.PauseSubscription(otherCtx, other.ID, domain.MonthStart(time.Now()))
+	require.NoError(t, err)
+	rate := domain.NewCreateExchangeRateInput("USD", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 90)
+	_, err = testRepo.CreateExchangeRate(otherCtx, &rate)
+	require.NoError(t, err)
+
+	t.Run("application role", func(t *testing.T) {
+		var superuser, bypassRLS bool
+		err := testRepo.DB.QueryRow(ctx, "SELECT rolsuper, rolbypassrls FROM pg_roles WHERE rolname = current_user").
+			Scan(&superuser, &bypassRLS)
+		require.NoError(t, err)
+		assert.False(t, superuser)
+		assert.False(t, bypassRLS)
+	})
+
+	// Соединение без организации в контексте: настройку задаёт сам тест
+	tx, err := testRepo.DB.Begin(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback(context.Background())
+
+	count := func(organization, table string) int64 {
+		_, err := tx.Exec(context.Background(), "SELECT set_config('app.organization_id', $1, true)", organization)
+		require.NoError(t, err)
+
+		var n int64
+		require.NoError(t, tx.QueryRow(context.Background(), "SELECT COUNT(*) FROM "+table).Scan(&n))
+		return n
+	}
+
+	tests := []struct {
+		table      string
+		own, other int64
+	}{
+		{table: "subscriptions", own: 1, other: 1},
+		{table: "subscription_price_history", own: 1, other: 1},
+		{table: "subscription_pauses", own: 0, other: 1},
+		{table: "subscription_events", own: 1, other: 2},
+		{table: "services", own: 1, other: 1},
+		{table: "service_aliases", own: 1, other: 1},
+		{table: "exchange_rates", own: 0, other: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			assert.Equal(t, tt.other, count(otherID.String(), tt.table))
+			assert.Equal(t, tt.own, count(domain.DefaultOrganizationID.String(), tt.table))
+			// Без организации политики не пропускают ни одной строки
+			assert.Zero(t, count("", tt.table))
+		})
+	}
+}
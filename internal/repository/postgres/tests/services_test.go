@@ -0,0 +1,218 @@
+//go:build integration
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestService(t *testing.T, name string, aliases ...string) *domain.Service {
+	t.Helper()
+	input := domain.NewCreateServiceInput(name, aliases, nil, nil)
+	result, err := testRepo.CreateService(testContext(), &input)
+	require.NoError(t, err)
+	return result
+}
+
+// ==================== Services CRUD ====================
+
+func TestCreateService(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("success with aliases", func(t *testing.T) {
+		cleanup(t)
+
+		input := domain.NewCreateServiceInput("Yandex Plus", []string{"Яндекс Плюс", "yandex plus"}, ptr("music"), ptr(int32(400)))
+		result, err := testRepo.CreateService(ctx, &input)
+
+		require.NoError(t, err)
+		assert.NotZero(t, result.ID)
+		assert.Equal(t, "Yandex Plus", result.Name)
+		assert.Equal(t, []string{"Яндекс Плюс"}, result.Aliases) // повтор названия отброшен
+		assert.Equal(t, ptr("music"), result.Category)
+		assert.Equal(t, ptr(int32(400)), result.DefaultPrice)
+	})
+
+	t.Run("name taken by alias", func(t *testing.T) {
+		cleanup(t)
+
+		createTestService(t, "Yandex Plus", "Яндекс Плюс")
+
+		input := domain.NewCreateServiceInput("яндекс плюс", nil, nil, nil)
+		result, err := testRepo.CreateService(ctx, &input)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+}
+
+func TestGetServiceByName(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("resolves name and aliases case-insensitively", func(t *testing.T) {
+		cleanup(t)
+
+		created := createTestService(t, "Yandex Plus", "Яндекс Плюс")
+
+		for _, name := range []string{"Yandex Plus", "yandex plus", "ЯНДЕКС ПЛЮС"} {
+			result, err := testRepo.GetServiceByName(ctx, name)
+			require.NoError(t, err, name)
+			assert.Equal(t, created.ID, result.ID, name)
+			assert.Equal(t, []string{"Яндекс Плюс"}, result.Aliases, name)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		cleanup(t)
+
+		result, err := testRepo.GetServiceByName(ctx, "Unknown")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestListServices(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	music := domain.NewCreateServiceInput("Spotify", nil, ptr("music"), nil)
+	_, err := testRepo.CreateService(ctx, &music)
+	require.NoError(t, err)
+	createTestService(t, "Netflix", "Нетфликс")
+
+	all, err := testRepo.ListServices(ctx, domain.ServiceFilter{}, domain.ListParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Netflix", all[0].Name)
+	assert.Equal(t, []string{"Нетфликс"}, all[0].Aliases)
+
+	filtered, err := testRepo.ListServices(ctx, domain.ServiceFilter{Category: ptr("music")}, domain.ListParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Spotify", filtered[0].Name)
+}
+
+func TestUpdateService(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("rename updates subscriptions and keeps old name as alias", func(t *testing.T) {
+		cleanup(t)
+
+		service := createTestService(t, "Yandex Plus")
+		sub, err := testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+		require.NoError(t, err)
+
+		result, err := testRepo.UpdateService(ctx, service.ID, domain.UpdateServiceInput{Name: ptr("Яндекс Плюс")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Яндекс Плюс", result.Name)
+		assert.Equal(t, []string{"Yandex Plus"}, result.Aliases)
+
+		updated, err := testRepo.GetSubscriptionByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Яндекс Плюс", updated.ServiceName)
+		assert.Equal(t, sub.Version+1, updated.Version)
+
+		events := subscriptionHistory(t, sub.ID)
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.AuditActionUpdated, events[0].Action)
+		assert.Equal(t, "Yandex Plus", snapshotField(t, events[0].Before, "service_name"))
+		assert.Equal(t, "Яндекс Плюс", snapshotField(t, events[0].After, "service_name"))
+	})
+
+	t.Run("replace aliases", func(t *testing.T) {
+		cleanup(t)
+
+		service := createTestService(t, "Netflix", "Нетфликс")
+
+		result, err := testRepo.UpdateService(ctx, service.ID, domain.UpdateServiceInput{Aliases: []string{"NFLX"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"NFLX"}, result.Aliases)
+
+		_, err = testRepo.GetServiceByName(ctx, "Нетфликс")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("alias of another service", func(t *testing.T) {
+		cleanup(t)
+
+		createTestService(t, "Netflix")
+		service := createTestService(t, "Spotify")
+
+		_, err := testRepo.UpdateService(ctx, service.ID, domain.UpdateServiceInput{Aliases: []string{"netflix"}})
+
+		assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		cleanup(t)
+
+		_, err := testRepo.UpdateService(ctx, 99999, domain.UpdateServiceInput{Name: ptr("X")})
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestDeleteService(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("success", func(t *testing.T) {
+		cleanup(t)
+
+		service := createTestService(t, "Netflix", "Нетфликс")
+
+		require.NoError(t, testRepo.DeleteService(ctx, service.ID))
+
+		_, err := testRepo.GetServiceByName(ctx, "Нетфликс")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("service with subscriptions", func(t *testing.T) {
+		cleanup(t)
+
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		err = testRepo.DeleteService(ctx, testServiceID("Netflix"))
+
+		assert.ErrorIs(t, err, repository.ErrInUse)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		cleanup(t)
+
+		assert.ErrorIs(t, testRepo.DeleteService(ctx, 99999), repository.ErrNotFound)
+	})
+}
+
+func TestCalculateTotalCost_ServiceAlias(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	createTestService(t, "Yandex Plus", "Яндекс Плюс")
+	_, err := testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+	require.NoError(t, err)
+	_, err = testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+	require.NoError(t, err)
+
+	for _, name := range []string{"Yandex Plus", "yandex plus", "Яндекс Плюс"} {
+		result, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			ServiceName: ptr(name),
+		})
+
+		require.NoError(t, err, name)
+		assert.Equal(t, int64(400), result.TotalCost, name)
+		assert.Equal(t, int64(1), result.Count, name)
+	}
+}
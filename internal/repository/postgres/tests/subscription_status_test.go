@@ -0,0 +1,189 @@
+//go:build integration
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ==================== Lifecycle ====================
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("new subscription is active", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.SubscriptionStatusActive, created.Status)
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		paused, err := testRepo.PauseSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+		assert.Equal(t, domain.SubscriptionStatusPaused, paused.Status)
+
+		resumed, err := testRepo.ResumeSubscription(ctx, created.ID, month(2025, time.May))
+		require.NoError(t, err)
+		assert.Equal(t, domain.SubscriptionStatusActive, resumed.Status)
+
+		// Март и апрель приостановлены
+		result, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: month(2025, time.January),
+			EndPeriod:   month(2025, time.June),
+			Mode:        domain.CostModeProrated,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(800*4), result.TotalCost)
+	})
+
+	t.Run("resume in the same month skips nothing", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.PauseSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+		_, err = testRepo.ResumeSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+
+		result, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: month(2025, time.January),
+			EndPeriod:   month(2025, time.June),
+			Mode:        domain.CostModeProrated,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(800*6), result.TotalCost)
+	})
+
+	t.Run("open pause skips all later months", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.PauseSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+
+		months, err := testRepo.CalculateMonthlyCost(ctx, domain.CostFilter{
+			StartPeriod: month(2025, time.January),
+			EndPeriod:   month(2025, time.April),
+			Mode:        domain.CostModeProrated,
+		})
+		require.NoError(t, err)
+		require.Len(t, months, 4)
+		assert.Equal(t, int64(800), months[1].TotalCost)
+		assert.Equal(t, int64(0), months[2].TotalCost)
+		assert.Equal(t, int64(0), months[3].TotalCost)
+	})
+
+	t.Run("cancel sets end date and closes pause", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.PauseSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+
+		cancelled, err := testRepo.CancelSubscription(ctx, created.ID, month(2025, time.May))
+		require.NoError(t, err)
+		assert.Equal(t, domain.SubscriptionStatusCancelled, cancelled.Status)
+		require.NotNil(t, cancelled.EndDate)
+		assert.Equal(t, month(2025, time.May), *cancelled.EndDate)
+
+		result, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: month(2025, time.January),
+			EndPeriod:   month(2025, time.December),
+			Mode:        domain.CostModeProrated,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(800*2), result.TotalCost)
+	})
+
+	t.Run("cancel keeps earlier end date", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx,
+			createTestInputWithEndDate("Netflix", 800, uuid.New(), month(2025, time.March)))
+		require.NoError(t, err)
+
+		cancelled, err := testRepo.CancelSubscription(ctx, created.ID, month(2025, time.May))
+		require.NoError(t, err)
+		require.NotNil(t, cancelled.EndDate)
+		assert.Equal(t, month(2025, time.March), *cancelled.EndDate)
+	})
+
+	t.Run("status guard", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.ResumeSubscription(ctx, created.ID, month(2025, time.May))
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("past end date is expired", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx,
+			createTestInputWithEndDate("Netflix", 800, uuid.New(), month(2020, time.January)))
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.SubscriptionStatusExpired, created.Status)
+	})
+}
+
+// ==================== Count by status ====================
+
+func TestCountSubscriptionsByStatus(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	otherCtx := createTestOrganization(t, "marketing")
+	now := month(2025, time.June)
+
+	_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+	require.NoError(t, err)
+	_, err = testRepo.CreateSubscription(otherCtx, createOrganizationInput("Spotify", 300, uuid.New()))
+	require.NoError(t, err)
+
+	paused, err := testRepo.CreateSubscription(ctx, createTestInput("YouTube", 400, uuid.New()))
+	require.NoError(t, err)
+	_, err = testRepo.PauseSubscription(ctx, paused.ID, month(2025, time.March))
+	require.NoError(t, err)
+
+	_, err = testRepo.CreateSubscription(ctx, createTestInputWithEndDate("Okko", 200, uuid.New(), month(2025, time.May)))
+	require.NoError(t, err)
+
+	deleted, err := testRepo.CreateSubscription(ctx, createTestInput("Ivi", 100, uuid.New()))
+	require.NoError(t, err)
+	require.NoError(t, testRepo.DeleteSubscription(ctx, deleted.ID, nil))
+
+	// Счётчик складывает сводки всех организаций: фоновые метрики вызывают его без организации
+	counts, err := testRepo.CountSubscriptionsByStatus(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.SubscriptionStatus]int64{
+		domain.SubscriptionStatusActive:  2,
+		domain.SubscriptionStatusPaused:  1,
+		domain.SubscriptionStatusExpired: 1,
+	}, counts)
+}
@@ -3,7 +3,7 @@
 package tests
 
 import (
-	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -19,11 +19,14 @@ import (
 func createTestInput(serviceName string, price int32, userID uuid.UUID) *domain.CreateSubscriptionInput {
 	startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	return &domain.CreateSubscriptionInput{
-		ServiceName: serviceName,
-		Price:       price,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     nil,
+		ServiceID:     testServiceID(serviceName),
+		ServiceName:   serviceName,
+		Price:         price,
+		Currency:      domain.BaseCurrency,
+		BillingPeriod: domain.BillingPeriodMonthly,
+		UserID:        userID,
+		StartDate:     startDate,
+		EndDate:       nil,
 	}
 }
 
@@ -33,6 +36,21 @@ func createTestInputWithEndDate(serviceName string, price int32, userID uuid.UUI
 	return input
 }
 
+// testServiceID возвращает ID сервиса каталога с названием name, создавая его при необходимости
+func testServiceID(name string) int64 {
+	ctx := testContext()
+	if service, err := testRepo.GetServiceByName(ctx, name); err == nil {
+		return service.ID
+	}
+
+	input := domain.NewCreateServiceInput(name, nil, nil, nil)
+	service, err := testRepo.CreateService(ctx, &input)
+	if err != nil {
+		panic(err)
+	}
+	return service.ID
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
@@ -40,7 +58,7 @@ func ptr[T any](v T) *T {
 // ==================== CreateSubscription ====================
 
 func TestCreateSubscription(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("success without end_date", func(t *testing.T) {
 		cleanup(t)
@@ -54,6 +72,7 @@ func TestCreateSubscription(t *testing.T) {
 		assert.NotZero(t, result.ID)
 		assert.Equal(t, "Yandex Plus", result.ServiceName)
 		assert.Equal(t, int32(400), result.Price)
+		assert.Equal(t, domain.BillingPeriodMonthly, result.BillingPeriod)
 		assert.Equal(t, userID, result.UserID)
 		assert.Equal(t, input.StartDate, result.StartDate)
 		assert.Nil(t, result.EndDate)
@@ -77,6 +96,18 @@ func TestCreateSubscription(t *testing.T) {
 		assert.Equal(t, endDate, *result.EndDate)
 	})
 
+	t.Run("success with yearly billing period", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Yandex Plus", 3990, uuid.New())
+		input.BillingPeriod = domain.BillingPeriodYearly
+
+		result, err := testRepo.CreateSubscription(ctx, input)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.BillingPeriodYearly, result.BillingPeriod)
+	})
+
 	t.Run("multiple subscriptions same user", func(t *testing.T) {
 		cleanup(t)
 
@@ -95,8 +126,99 @@ func TestCreateSubscription(t *testing.T) {
 
 // ==================== GetSubscriptionByID ====================
 
+func TestCreateSubscriptions(t *testing.T) {
+	ctx := testContext()
+
+	batch := func() []*domain.CreateSubscriptionInput {
+		return []*domain.CreateSubscriptionInput{
+			createTestInput("Netflix", 800, uuid.New()),
+			createTestInput("Spotify", 0, uuid.New()), // нарушает CHECK (price > 0)
+			createTestInput("Yandex Plus", 400, uuid.New()),
+		}
+	}
+
+	t.Run("non-atomic keeps valid subscriptions", func(t *testing.T) {
+		cleanup(t)
+
+		results, err := testRepo.CreateSubscriptions(ctx, batch(), false)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.NotNil(t, results[0].Subscription)
+		assert.Equal(t, "Netflix", results[0].Subscription.ServiceName)
+		assert.Error(t, results[1].Err)
+		assert.Nil(t, results[1].Subscription)
+		require.NotNil(t, results[2].Subscription)
+
+		total, err := testRepo.CountSubscriptions(ctx, domain.ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+
+		prices, err := testRepo.ListSubscriptionPrices(ctx, results[2].Subscription.ID)
+		require.NoError(t, err)
+		assert.Len(t, prices, 1)
+	})
+
+	t.Run("atomic rolls back whole batch", func(t *testing.T) {
+		cleanup(t)
+
+		results, err := testRepo.CreateSubscriptions(ctx, batch(), true)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Nil(t, results[0].Subscription)
+		assert.Error(t, results[1].Err)
+		assert.Nil(t, results[2].Subscription)
+		assert.NoError(t, results[2].Err)
+
+		total, err := testRepo.CountSubscriptions(ctx, domain.ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+	})
+
+	t.Run("new service follows subscription", func(t *testing.T) {
+		cleanup(t)
+
+		// Сервис без ID добавляется в каталог в транзакции подписки
+		inputs := batch()
+		inputs[0].ServiceID = 0
+		inputs[0].ServiceName = "Kinopoisk"
+
+		results, err := testRepo.CreateSubscriptions(ctx, inputs, true)
+		require.NoError(t, err)
+		assert.Nil(t, results[0].Subscription)
+
+		_, err = testRepo.GetServiceByName(ctx, "Kinopoisk")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		results, err = testRepo.CreateSubscriptions(ctx, inputs, false)
+		require.NoError(t, err)
+		require.NotNil(t, results[0].Subscription)
+
+		service, err := testRepo.GetServiceByName(ctx, "kinopoisk")
+		require.NoError(t, err)
+		assert.Equal(t, service.ID, results[0].Subscription.ServiceID)
+		assert.Equal(t, "Kinopoisk", results[0].Subscription.ServiceName)
+	})
+
+	t.Run("atomic success", func(t *testing.T) {
+		cleanup(t)
+
+		inputs := batch()
+		inputs[1].Price = 200
+
+		results, err := testRepo.CreateSubscriptions(ctx, inputs, true)
+
+		require.NoError(t, err)
+		for i, result := range results {
+			assert.NoError(t, result.Err, i)
+			assert.NotNil(t, result.Subscription, i)
+		}
+	})
+}
+
 func TestGetSubscriptionByID(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("found", func(t *testing.T) {
 		cleanup(t)
@@ -124,15 +246,46 @@ func TestGetSubscriptionByID(t *testing.T) {
 	})
 }
 
+func TestGetSubscriptionOwner(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	userID := uuid.New()
+	created, err := testRepo.CreateSubscription(ctx, createTestInput("Spotify", 169, userID))
+	require.NoError(t, err)
+
+	t.Run("found", func(t *testing.T) {
+		owner, err := testRepo.GetSubscriptionOwner(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, owner)
+	})
+
+	t.Run("deleted subscription", func(t *testing.T) {
+		require.NoError(t, testRepo.DeleteSubscription(ctx, created.ID, nil))
+
+		owner, err := testRepo.GetSubscriptionOwner(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, owner)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := testRepo.GetSubscriptionOwner(ctx, 99999)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
 // ==================== ListSubscriptions ====================
 
 func TestListSubscriptions(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("empty list", func(t *testing.T) {
 		cleanup(t)
 
-		result, err := testRepo.ListSubscriptions(ctx, domain.ListParams{Limit: 10, Offset: 0})
+		result, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 10, Offset: 0})
 
 		require.NoError(t, err)
 		assert.Empty(t, result)
@@ -147,7 +300,7 @@ func TestListSubscriptions(t *testing.T) {
 			require.NoError(t, err)
 		}
 
-		result, err := testRepo.ListSubscriptions(ctx, domain.ListParams{Limit: 10, Offset: 0})
+		result, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 10, Offset: 0})
 
 		require.NoError(t, err)
 		assert.Len(t, result, 3)
@@ -162,7 +315,7 @@ func TestListSubscriptions(t *testing.T) {
 			require.NoError(t, err)
 		}
 
-		result, err := testRepo.ListSubscriptions(ctx, domain.ListParams{Limit: 2, Offset: 0})
+		result, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 2, Offset: 0})
 
 		require.NoError(t, err)
 		assert.Len(t, result, 2)
@@ -177,7 +330,7 @@ func TestListSubscriptions(t *testing.T) {
 			require.NoError(t, err)
 		}
 
-		result, err := testRepo.ListSubscriptions(ctx, domain.ListParams{Limit: 10, Offset: 3})
+		result, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 10, Offset: 3})
 
 		require.NoError(t, err)
 		assert.Len(t, result, 2) // 5 - 3 = 2
@@ -190,7 +343,7 @@ func TestListSubscriptions(t *testing.T) {
 		time.Sleep(10 * time.Millisecond) // небольшая задержка для разных timestamp
 		sub2, _ := testRepo.CreateSubscription(ctx, createTestInput("Second", 200, uuid.New()))
 
-		result, err := testRepo.ListSubscriptions(ctx, domain.ListParams{Limit: 10, Offset: 0})
+		result, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 10, Offset: 0})
 
 		require.NoError(t, err)
 		require.Len(t, result, 2)
@@ -198,12 +351,159 @@ func TestListSubscriptions(t *testing.T) {
 		assert.Equal(t, sub2.ID, result[0].ID)
 		assert.Equal(t, sub1.ID, result[1].ID)
 	})
+
+	t.Run("cursor pages cover all rows once", func(t *testing.T) {
+		cleanup(t)
+
+		for i := 0; i < 5; i++ {
+			_, err := testRepo.CreateSubscription(ctx, createTestInput("Service", 100, uuid.New()))
+			require.NoError(t, err)
+		}
+
+		params := domain.ListParams{Limit: 2}
+		seen := map[int64]bool{}
+		for {
+			page, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, params)
+			require.NoError(t, err)
+			for _, sub := range page {
+				assert.False(t, seen[sub.ID], "duplicate %d", sub.ID)
+				seen[sub.ID] = true
+			}
+
+			params.Cursor = domain.NextCursor(page, params.Limit)
+			if params.Cursor == nil {
+				break
+			}
+
+			// Вставка между страницами не должна сдвигать выдачу
+			_, err = testRepo.CreateSubscription(ctx, createTestInput("Inserted", 100, uuid.New()))
+			require.NoError(t, err)
+		}
+		assert.Len(t, seen, 5)
+	})
+}
+
+func TestListSubscriptions_Filters(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	userID := uuid.New()
+	month := func(m time.Month) time.Time { return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	netflix := createTestInputWithEndDate("Netflix", 800, userID, month(3))
+	_, err := testRepo.CreateSubscription(ctx, netflix)
+	require.NoError(t, err)
+
+	spotify := createTestInput("Spotify", 200, uuid.New())
+	spotify.StartDate = month(4)
+	_, err = testRepo.CreateSubscription(ctx, spotify)
+	require.NoError(t, err)
+
+	createTestService(t, "Yandex Plus", "Яндекс Плюс")
+	yandex := createTestInput("Yandex Plus", 400, userID)
+	yandex.StartDate = month(6)
+	_, err = testRepo.CreateSubscription(ctx, yandex)
+	require.NoError(t, err)
+
+	names := func(subs []domain.Subscription) []string {
+		result := make([]string, len(subs))
+		for i, sub := range subs {
+			result[i] = sub.ServiceName
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.ListFilter
+		want   []string
+	}{
+		{"user", domain.ListFilter{UserID: &userID, Sort: domain.SubscriptionSortPrice}, []string{"Yandex Plus", "Netflix"}},
+		{"service alias", domain.ListFilter{ServiceName: ptr("яндекс плюс")}, []string{"Yandex Plus"}},
+		{"service prefix", domain.ListFilter{ServiceNamePrefix: ptr("sp")}, []string{"Spotify"}},
+		{"prefix escapes wildcards", domain.ListFilter{ServiceNamePrefix: ptr("%")}, []string{}},
+		{"price range", domain.ListFilter{PriceMin: ptr(int32(300)), PriceMax: ptr(int32(800)), Sort: domain.SubscriptionSortPrice},
+			[]string{"Yandex Plus", "Netflix"}},
+		{"active in", domain.ListFilter{ActiveIn: ptr(month(5)), Sort: domain.SubscriptionSortServiceName}, []string{"Spotify"}},
+		{"start range", domain.ListFilter{StartFrom: ptr(month(2)), StartTo: ptr(month(5))}, []string{"Spotify"}},
+		{"has end date", domain.ListFilter{HasEndDate: ptr(true)}, []string{"Netflix"}},
+		{"without end date", domain.ListFilter{HasEndDate: ptr(false), Sort: domain.SubscriptionSortStartDate},
+			[]string{"Spotify", "Yandex Plus"}},
+		{"sort by price desc", domain.ListFilter{Sort: domain.SubscriptionSortPriceDesc}, []string{"Netflix", "Yandex Plus", "Spotify"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := testRepo.ListSubscriptions(ctx, tt.filter, domain.ListParams{Limit: 10})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(result))
+		})
+	}
+}
+
+func TestCountSubscriptions(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	userID := uuid.New()
+	for i := 0; i < 3; i++ {
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", int32(100*(i+1)), userID))
+		require.NoError(t, err)
+	}
+	_, err := testRepo.CreateSubscription(ctx, createTestInput("Spotify", 200, uuid.New()))
+	require.NoError(t, err)
+
+	total, err := testRepo.CountSubscriptions(ctx, domain.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+
+	total, err = testRepo.CountSubscriptions(ctx, domain.ListFilter{UserID: &userID, PriceMin: ptr(int32(200))})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+// ==================== ExportSubscriptions ====================
+
+func TestExportSubscriptions(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	userID := uuid.New()
+	for i := 0; i < 3; i++ {
+		_, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", int32(100*(i+1)), userID))
+		require.NoError(t, err)
+	}
+	_, err := testRepo.CreateSubscription(ctx, createTestInput("Spotify", 200, uuid.New()))
+	require.NoError(t, err)
+
+	t.Run("streams all matching rows in sort order", func(t *testing.T) {
+		var prices []int32
+		err := testRepo.ExportSubscriptions(ctx, domain.ListFilter{UserID: &userID, Sort: domain.SubscriptionSortPriceDesc},
+			func(sub *domain.Subscription) error {
+				prices = append(prices, sub.Price)
+				return nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, []int32{300, 200, 100}, prices)
+	})
+
+	t.Run("callback error stops export", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := testRepo.ExportSubscriptions(ctx, domain.ListFilter{}, func(sub *domain.Subscription) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
 }
 
 // ==================== ListSubscriptionsByUserID ====================
 
 func TestListSubscriptionsByUserID(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("returns only user subscriptions", func(t *testing.T) {
 		cleanup(t)
@@ -252,12 +552,36 @@ func TestListSubscriptionsByUserID(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, result, 2)
 	})
+
+	t.Run("cursor", func(t *testing.T) {
+		cleanup(t)
+
+		userID := uuid.New()
+		for i := 0; i < 3; i++ {
+			testRepo.CreateSubscription(ctx, createTestInput("Service", 100, userID))
+		}
+		testRepo.CreateSubscription(ctx, createTestInput("Service", 100, uuid.New()))
+
+		first, err := testRepo.ListSubscriptionsByUserID(ctx, userID, domain.ListParams{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+
+		second, err := testRepo.ListSubscriptionsByUserID(ctx, userID, domain.ListParams{
+			Limit:  2,
+			Cursor: domain.NextCursor(first, 2),
+		})
+
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, userID, second[0].UserID)
+		assert.NotContains(t, []int64{first[0].ID, first[1].ID}, second[0].ID)
+	})
 }
 
 // ==================== UpdateSubscription ====================
 
 func TestUpdateSubscription(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("update service_name", func(t *testing.T) {
 		cleanup(t)
@@ -303,6 +627,20 @@ func TestUpdateSubscription(t *testing.T) {
 		assert.Equal(t, endDate, *result.EndDate)
 	})
 
+	t.Run("update billing_period", func(t *testing.T) {
+		cleanup(t)
+
+		created, _ := testRepo.CreateSubscription(ctx, createTestInput("Service", 100, uuid.New()))
+
+		result, err := testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{
+			BillingPeriod: ptr(domain.BillingPeriodQuarterly),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.BillingPeriodQuarterly, result.BillingPeriod)
+		assert.Equal(t, int32(100), result.Price) // не изменился
+	})
+
 	t.Run("update multiple fields", func(t *testing.T) {
 		cleanup(t)
 
@@ -350,14 +688,14 @@ func TestUpdateSubscription(t *testing.T) {
 // ==================== DeleteSubscription ====================
 
 func TestDeleteSubscription(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("success", func(t *testing.T) {
 		cleanup(t)
 
 		created, _ := testRepo.CreateSubscription(ctx, createTestInput("ToDelete", 100, uuid.New()))
 
-		err := testRepo.DeleteSubscription(ctx, created.ID)
+		err := testRepo.DeleteSubscription(ctx, created.ID, nil)
 		require.NoError(t, err)
 
 		// Проверяем что удалено
@@ -368,7 +706,7 @@ func TestDeleteSubscription(t *testing.T) {
 	t.Run("not found", func(t *testing.T) {
 		cleanup(t)
 
-		err := testRepo.DeleteSubscription(ctx, 99999)
+		err := testRepo.DeleteSubscription(ctx, 99999, nil)
 
 		assert.ErrorIs(t, err, repository.ErrNotFound)
 	})
@@ -379,7 +717,7 @@ func TestDeleteSubscription(t *testing.T) {
 		sub1, _ := testRepo.CreateSubscription(ctx, createTestInput("Keep", 100, uuid.New()))
 		sub2, _ := testRepo.CreateSubscription(ctx, createTestInput("Delete", 200, uuid.New()))
 
-		err := testRepo.DeleteSubscription(ctx, sub2.ID)
+		err := testRepo.DeleteSubscription(ctx, sub2.ID, nil)
 		require.NoError(t, err)
 
 		// sub1 должен остаться
@@ -387,12 +725,181 @@ func TestDeleteSubscription(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "Keep", result.ServiceName)
 	})
+
+	t.Run("deleted subscription is hidden from lists and cost", func(t *testing.T) {
+		cleanup(t)
+
+		userID := uuid.New()
+		kept, _ := testRepo.CreateSubscription(ctx, createTestInput("Keep", 100, userID))
+		deleted, _ := testRepo.CreateSubscription(ctx, createTestInput("Delete", 200, userID))
+		require.NoError(t, testRepo.DeleteSubscription(ctx, deleted.ID, nil))
+
+		list, err := testRepo.ListSubscriptions(ctx, domain.ListFilter{}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, kept.ID, list[0].ID)
+
+		list, err = testRepo.ListSubscriptions(ctx, domain.ListFilter{IncludeDeleted: true}, domain.ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.NotNil(t, list[0].DeletedAt)
+
+		total, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			UserID:      &userID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.TotalCost{TotalCost: 100, Count: 1}, total)
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		cleanup(t)
+
+		created, _ := testRepo.CreateSubscription(ctx, createTestInput("ToDelete", 100, uuid.New()))
+		require.NoError(t, testRepo.DeleteSubscription(ctx, created.ID, nil))
+
+		err := testRepo.DeleteSubscription(ctx, created.ID, nil)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// ==================== RestoreSubscription ====================
+
+func TestRestoreSubscription(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("success", func(t *testing.T) {
+		cleanup(t)
+
+		created, _ := testRepo.CreateSubscription(ctx, createTestInput("ToRestore", 100, uuid.New()))
+		require.NoError(t, testRepo.DeleteSubscription(ctx, created.ID, nil))
+
+		restored, err := testRepo.RestoreSubscription(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		_, err = testRepo.GetSubscriptionByID(ctx, created.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		cleanup(t)
+
+		created, _ := testRepo.CreateSubscription(ctx, createTestInput("Active", 100, uuid.New()))
+
+		_, err := testRepo.RestoreSubscription(ctx, created.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// ==================== PurgeDeletedSubscriptions ====================
+
+func TestPurgeDeletedSubscriptions(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	active, _ := testRepo.CreateSubscription(ctx, createTestInput("Active", 100, uuid.New()))
+	deleted, _ := testRepo.CreateSubscription(ctx, createTestInput("Deleted", 200, uuid.New()))
+	require.NoError(t, testRepo.DeleteSubscription(ctx, deleted.ID, nil))
+
+	t.Run("keeps subscriptions deleted after the cutoff", func(t *testing.T) {
+		purged, err := testRepo.PurgeDeletedSubscriptions(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		_, err = testRepo.RestoreSubscription(ctx, deleted.ID)
+		require.NoError(t, err)
+		require.NoError(t, testRepo.DeleteSubscription(ctx, deleted.ID, nil))
+	})
+
+	t.Run("purges subscriptions deleted before the cutoff", func(t *testing.T) {
+		purged, err := testRepo.PurgeDeletedSubscriptions(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		_, err = testRepo.RestoreSubscription(ctx, deleted.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		_, err = testRepo.GetSubscriptionByID(ctx, active.ID)
+		assert.NoError(t, err)
+	})
 }
 
 // ==================== CalculateTotalCost ====================
 
+func TestSubscriptionVersion(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 100, uuid.New()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), created.Version)
+
+	t.Run("every change increments version", func(t *testing.T) {
+		updated, err := testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{Price: ptr(150)})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), updated.Version)
+
+		paused, err := testRepo.PauseSubscription(ctx, created.ID, month(2025, time.March))
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), paused.Version)
+
+		resumed, err := testRepo.ResumeSubscription(ctx, created.ID, month(2025, time.May))
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), resumed.Version)
+	})
+
+	t.Run("empty update keeps version", func(t *testing.T) {
+		result, err := testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), result.Version)
+	})
+
+	t.Run("update with matching version", func(t *testing.T) {
+		result, err := testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{
+			Price:     ptr(200),
+			IfVersion: ptr(int32(4)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), result.Version)
+		assert.Equal(t, int32(200), result.Price)
+	})
+
+	t.Run("update with stale version", func(t *testing.T) {
+		_, err := testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{
+			Price:     ptr(300),
+			IfVersion: ptr(int32(4)),
+		})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		_, err = testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{IfVersion: ptr(int32(4))})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		current, err := testRepo.GetSubscriptionByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), current.Version)
+		assert.Equal(t, int32(200), current.Price)
+	})
+
+	t.Run("delete with stale version", func(t *testing.T) {
+		err := testRepo.DeleteSubscription(ctx, created.ID, ptr(int32(4)))
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		_, err = testRepo.GetSubscriptionByID(ctx, created.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("delete with matching version", func(t *testing.T) {
+		require.NoError(t, testRepo.DeleteSubscription(ctx, created.ID, ptr(int32(5))))
+
+		err := testRepo.DeleteSubscription(ctx, created.ID, ptr(int32(5)))
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
 func TestCalculateTotalCost(t *testing.T) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	t.Run("empty database", func(t *testing.T) {
 		cleanup(t)
@@ -549,3 +1056,605 @@ func TestCalculateTotalCost(t *testing.T) {
 		assert.Equal(t, int64(1), result.Count)
 	})
 }
+
+func TestCalculateTotalCost_Prorated(t *testing.T) {
+	ctx := testContext()
+
+	period := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	t.Run("multiplies price by months in period", func(t *testing.T) {
+		cleanup(t)
+
+		// Активна весь год — 12 месяцев
+		testRepo.CreateSubscription(ctx, createTestInput("FullYear", 100, uuid.New()))
+
+		// С июня — 7 месяцев
+		input := createTestInput("FromJune", 200, uuid.New())
+		input.StartDate = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		testRepo.CreateSubscription(ctx, input)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100*12+200*7), result.TotalCost)
+		assert.Equal(t, int64(2), result.Count)
+	})
+
+	t.Run("clips subscription to period bounds", func(t *testing.T) {
+		cleanup(t)
+
+		// 10-2024 .. 03-2025 — в период попадают 3 месяца
+		input := createTestInputWithEndDate("Clipped", 300, uuid.New(), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+		input.StartDate = time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+		testRepo.CreateSubscription(ctx, input)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(900), result.TotalCost)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("single month period", func(t *testing.T) {
+		cleanup(t)
+
+		testRepo.CreateSubscription(ctx, createTestInput("Monthly", 400, uuid.New()))
+
+		filter := period
+		filter.StartPeriod = time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+		filter.EndPeriod = time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		result, err := testRepo.CalculateTotalCost(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(400), result.TotalCost)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("excludes subscriptions outside period", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Future", 100, uuid.New())
+		input.StartDate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		testRepo.CreateSubscription(ctx, input)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result.TotalCost)
+		assert.Equal(t, int64(0), result.Count)
+	})
+}
+
+func TestCalculateTotalCost_BillingPeriods(t *testing.T) {
+	ctx := testContext()
+
+	period := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	create := func(t *testing.T, billingPeriod domain.BillingPeriod, price int32, start time.Time) {
+		t.Helper()
+		input := createTestInput(string(billingPeriod), price, uuid.New())
+		input.BillingPeriod = billingPeriod
+		input.StartDate = start
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+	}
+
+	t.Run("yearly charged in renewal month", func(t *testing.T) {
+		cleanup(t)
+
+		// Продление в 03-2025 попадает в период
+		create(t, domain.BillingPeriodYearly, 1200, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1200), result.TotalCost)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("yearly without renewal in period", func(t *testing.T) {
+		cleanup(t)
+
+		create(t, domain.BillingPeriodYearly, 1200, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+		filter := period
+		filter.StartPeriod = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		result, err := testRepo.CalculateTotalCost(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result.TotalCost)
+		assert.Equal(t, int64(1), result.Count) // подписка активна, но не списывалась
+	})
+
+	t.Run("quarterly charged every third month", func(t *testing.T) {
+		cleanup(t)
+
+		// 02, 05, 08, 11 — четыре списания
+		create(t, domain.BillingPeriodQuarterly, 300, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1200), result.TotalCost)
+	})
+
+	t.Run("weekly charged on every renewal date", func(t *testing.T) {
+		cleanup(t)
+
+		// 01-01-2025 + 7k: 53 даты продления в 2025 году
+		create(t, domain.BillingPeriodWeekly, 10, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(530), result.TotalCost)
+	})
+
+	t.Run("amortized spreads price over months", func(t *testing.T) {
+		cleanup(t)
+
+		create(t, domain.BillingPeriodYearly, 1200, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+		create(t, domain.BillingPeriodQuarterly, 300, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+		create(t, domain.BillingPeriodWeekly, 12, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC))
+
+		filter := period
+		filter.Amortize = true
+
+		result, err := testRepo.CalculateTotalCost(ctx, filter)
+
+		require.NoError(t, err)
+		// 100 × 12 + 100 × 6 + 52 × 1
+		assert.Equal(t, int64(1200+600+52), result.TotalCost)
+		assert.Equal(t, int64(3), result.Count)
+	})
+
+	t.Run("monthly breakdown places yearly charge in renewal month", func(t *testing.T) {
+		cleanup(t)
+
+		create(t, domain.BillingPeriodYearly, 1200, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+		result, err := testRepo.CalculateMonthlyCost(ctx, period)
+
+		require.NoError(t, err)
+		require.Len(t, result, 12)
+		for _, m := range result {
+			if m.Month.Month() == time.March {
+				assert.Equal(t, int64(1200), m.TotalCost)
+			} else {
+				assert.Equal(t, int64(0), m.TotalCost)
+			}
+			assert.Equal(t, int64(1), m.Count)
+		}
+	})
+}
+
+// ==================== CalculateMonthlyCost ====================
+
+func TestCalculateTotalCost_IntroPricing(t *testing.T) {
+	ctx := testContext()
+
+	period := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	t.Run("trial is free and intro price applies to intro window", func(t *testing.T) {
+		cleanup(t)
+
+		// 1 месяц бесплатно, 3 месяца по 1, дальше по 400
+		input := createTestInput("Yandex Plus", 400, uuid.New())
+		input.Intro = domain.IntroTerms{TrialMonths: 1, IntroPrice: ptr(int32(1)), IntroMonths: 3}
+		created, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+		assert.Equal(t, input.Intro, created.Intro)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0+1*3+400*2), result.TotalCost)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("windows count from subscription start", func(t *testing.T) {
+		cleanup(t)
+
+		// Подписка с 11-2024: пробный месяц и вводная цена уже прошли к марту
+		input := createTestInput("Netflix", 800, uuid.New())
+		input.StartDate = time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+		input.Intro = domain.IntroTerms{TrialMonths: 2, IntroPrice: ptr(int32(100)), IntroMonths: 2}
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100*2+800*4), result.TotalCost)
+	})
+
+	t.Run("trial only", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Spotify", 300, uuid.New())
+		input.Intro = domain.IntroTerms{TrialMonths: 3}
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+
+		result, err := testRepo.CalculateTotalCost(ctx, period)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(300*3), result.TotalCost)
+	})
+
+	t.Run("overlap mode uses price of first month in period", func(t *testing.T) {
+		cleanup(t)
+
+		input := createTestInput("Yandex Plus", 400, uuid.New())
+		input.Intro = domain.IntroTerms{IntroPrice: ptr(int32(1)), IntroMonths: 3}
+		_, err := testRepo.CreateSubscription(ctx, input)
+		require.NoError(t, err)
+
+		filter := period
+		filter.Mode = domain.CostModeOverlap
+
+		result, err := testRepo.CalculateTotalCost(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.TotalCost)
+	})
+}
+
+func TestCalculateMonthlyCost(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("returns row for every month", func(t *testing.T) {
+		cleanup(t)
+
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		result, err := testRepo.CalculateMonthlyCost(ctx, filter)
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		for i, m := range result {
+			assert.Equal(t, time.Date(2025, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC), m.Month)
+			assert.Equal(t, int64(0), m.TotalCost)
+			assert.Equal(t, int64(0), m.Count)
+		}
+	})
+
+	t.Run("splits cost by month", func(t *testing.T) {
+		cleanup(t)
+
+		// 01-2025 .. 02-2025
+		input1 := createTestInputWithEndDate("Short", 100, uuid.New(), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+		testRepo.CreateSubscription(ctx, input1)
+
+		// С 02-2025 без окончания
+		input2 := createTestInput("Long", 300, uuid.New())
+		input2.StartDate = time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		testRepo.CreateSubscription(ctx, input2)
+
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		result, err := testRepo.CalculateMonthlyCost(ctx, filter)
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, domain.MonthlyCost{Month: filter.StartPeriod, TotalCost: 100, Count: 1}, result[0])
+		assert.Equal(t, int64(400), result[1].TotalCost)
+		assert.Equal(t, int64(2), result[1].Count)
+		assert.Equal(t, int64(300), result[2].TotalCost)
+		assert.Equal(t, int64(1), result[2].Count)
+	})
+
+	t.Run("filters by user_id", func(t *testing.T) {
+		cleanup(t)
+
+		user1 := uuid.New()
+		testRepo.CreateSubscription(ctx, createTestInput("User1Sub", 100, user1))
+		testRepo.CreateSubscription(ctx, createTestInput("User2Sub", 200, uuid.New()))
+
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			UserID:      &user1,
+		}
+
+		result, err := testRepo.CalculateMonthlyCost(ctx, filter)
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		for _, m := range result {
+			assert.Equal(t, int64(100), m.TotalCost)
+			assert.Equal(t, int64(1), m.Count)
+		}
+	})
+}
+
+// ==================== CalculateGroupedCost ====================
+
+func TestCalculateGroupedCost(t *testing.T) {
+	ctx := testContext()
+
+	period := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	t.Run("groups by service_name sorted by cost", func(t *testing.T) {
+		cleanup(t)
+
+		testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Netflix", 1000, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Spotify", 169, uuid.New()))
+
+		result, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByServiceName,
+			Limit:   10,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, domain.CostGroup{Key: "Netflix", TotalCost: 1000 * 12, Count: 1}, result[0])
+		assert.Equal(t, domain.CostGroup{Key: "Yandex Plus", TotalCost: 800 * 12, Count: 2}, result[1])
+		assert.Equal(t, domain.CostGroup{Key: "Spotify", TotalCost: 169 * 12, Count: 1}, result[2])
+	})
+
+	t.Run("groups by user_id", func(t *testing.T) {
+		cleanup(t)
+
+		user1 := uuid.New()
+		user2 := uuid.New()
+
+		testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, user1))
+		testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, user1))
+		testRepo.CreateSubscription(ctx, createTestInput("Spotify", 169, user2))
+
+		result, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByUserID,
+			Limit:   10,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, domain.CostGroup{Key: user1.String(), TotalCost: 1200 * 12, Count: 2}, result[0])
+		assert.Equal(t, domain.CostGroup{Key: user2.String(), TotalCost: 169 * 12, Count: 1}, result[1])
+	})
+
+	t.Run("limit returns top-N", func(t *testing.T) {
+		cleanup(t)
+
+		testRepo.CreateSubscription(ctx, createTestInput("Cheap", 100, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Medium", 200, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Expensive", 300, uuid.New()))
+
+		result, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByServiceName,
+			Limit:   2,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "Expensive", result[0].Key)
+		assert.Equal(t, "Medium", result[1].Key)
+	})
+
+	t.Run("zero limit returns all groups", func(t *testing.T) {
+		cleanup(t)
+
+		testRepo.CreateSubscription(ctx, createTestInput("Cheap", 100, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Medium", 200, uuid.New()))
+		testRepo.CreateSubscription(ctx, createTestInput("Expensive", 300, uuid.New()))
+
+		result, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByServiceName,
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("respects filters and overlap mode", func(t *testing.T) {
+		cleanup(t)
+
+		user1 := uuid.New()
+		testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, user1))
+		testRepo.CreateSubscription(ctx, createTestInput("Yandex Plus", 400, uuid.New()))
+
+		filter := period
+		filter.UserID = &user1
+		filter.Mode = domain.CostModeOverlap
+
+		result, err := testRepo.CalculateGroupedCost(ctx, filter, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByServiceName,
+			Limit:   10,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, domain.CostGroup{Key: "Yandex Plus", TotalCost: 400, Count: 1}, result[0])
+	})
+
+	t.Run("empty period", func(t *testing.T) {
+		cleanup(t)
+
+		result, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupByUserID,
+			Limit:   10,
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("unknown group_by", func(t *testing.T) {
+		cleanup(t)
+
+		_, err := testRepo.CalculateGroupedCost(ctx, period, domain.CostGroupParams{
+			GroupBy: domain.CostGroupBy("price; DROP TABLE subscriptions"),
+			Limit:   10,
+		})
+
+		assert.ErrorIs(t, err, repository.ErrInternal)
+	})
+}
+
+func TestListSubscriptionCosts(t *testing.T) {
+	ctx := testContext()
+	cleanup(t)
+
+	period := domain.CostFilter{
+		StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Mode:        domain.CostModeProrated,
+	}
+
+	userID := uuid.New()
+	netflix, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 1000, userID))
+	require.NoError(t, err)
+	spotify, err := testRepo.CreateSubscription(ctx, createTestInput("Spotify", 169, uuid.New()))
+	require.NoError(t, err)
+
+	// Подписка вне периода в отчёт не попадает
+	outside := createTestInput("Yandex Plus", 400, userID)
+	outside.StartDate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = testRepo.CreateSubscription(ctx, outside)
+	require.NoError(t, err)
+
+	t.Run("returns subscriptions with period cost", func(t *testing.T) {
+		result, err := testRepo.ListSubscriptionCosts(ctx, period)
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, netflix.ID, result[0].Subscription.ID)
+		assert.Equal(t, int64(1000*12), result[0].TotalCost)
+		assert.Equal(t, spotify.ID, result[1].Subscription.ID)
+		assert.Equal(t, int64(169*12), result[1].TotalCost)
+	})
+
+	t.Run("respects filters", func(t *testing.T) {
+		filter := period
+		filter.UserID = &userID
+
+		result, err := testRepo.ListSubscriptionCosts(ctx, filter)
+
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "Netflix", result[0].Subscription.ServiceName)
+	})
+}
+
+// ==================== Price history ====================
+
+func TestSubscriptionPriceHistory(t *testing.T) {
+	ctx := testContext()
+
+	t.Run("create writes initial price", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		prices, err := testRepo.ListSubscriptionPrices(ctx, created.ID)
+
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		assert.Equal(t, int32(800), prices[0].Price)
+		assert.Equal(t, created.StartDate, prices[0].EffectiveFrom)
+	})
+
+	t.Run("price update is effective from current month", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{Price: ptr(1000)})
+		require.NoError(t, err)
+
+		prices, err := testRepo.ListSubscriptionPrices(ctx, created.ID)
+
+		now := time.Now().UTC()
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		assert.Equal(t, int32(800), prices[0].Price)
+		assert.Equal(t, int32(1000), prices[1].Price)
+		assert.Equal(t, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), prices[1].EffectiveFrom)
+	})
+
+	t.Run("update without price keeps history", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{ServiceName: ptr("Netflix HD")})
+		require.NoError(t, err)
+
+		prices, err := testRepo.ListSubscriptionPrices(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Len(t, prices, 1)
+	})
+
+	t.Run("cost uses price in effect each month", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		_, err = testRepo.DB.Exec(ctx,
+			"INSERT INTO subscription_price_history (subscription_id, price, effective_from) VALUES ($1, $2, $3)",
+			created.ID, 1000, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+
+		result, err := testRepo.CalculateTotalCost(ctx, domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			Mode:        domain.CostModeProrated,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(800*2+1000*2), result.TotalCost)
+	})
+
+	t.Run("later price update does not change past cost", func(t *testing.T) {
+		cleanup(t)
+
+		created, err := testRepo.CreateSubscription(ctx, createTestInput("Netflix", 800, uuid.New()))
+		require.NoError(t, err)
+
+		filter := domain.CostFilter{
+			StartPeriod: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndPeriod:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			Mode:        domain.CostModeProrated,
+		}
+		before, err := testRepo.CalculateTotalCost(ctx, filter)
+		require.NoError(t, err)
+
+		_, err = testRepo.UpdateSubscription(ctx, created.ID, domain.UpdateSubscriptionInput{Price: ptr(5000)})
+		require.NoError(t, err)
+
+		after, err := testRepo.CalculateTotalCost(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, before.TotalCost, after.TotalCost)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	repository "github.com/Krokozabra213/effective_mobile/internal/repository/postgres"
 	pgxclient "github.com/Krokozabra213/effective_mobile/pkg/pgx-client"
 	migrations "github.com/Krokozabra213/effective_mobile/sql/goose"
@@ -20,16 +21,22 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-var testRepo *repository.PostgresRepository
+var (
+	testRepo *repository.PostgresRepository
+	// testDB подключение тестового пользователя без роли приложения: очищает таблицы
+	// и заводит организации
+	testDB *pgxclient.Client
+)
 
 const (
 	dbname   = "test_db"
 	username = "test"
 	password = "test"
+	appRole  = "subscriptions_app"
 )
 
 func TestMain(m *testing.M) {
-	ctx := context.Background()
+	ctx := testContext()
 
 	// Запускаем PostgreSQL
 	container, err := postgres.Run(ctx,
@@ -60,24 +67,34 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		fmt.Printf("failed to get connection string: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Запускаем goose миграции: они создают роль приложения
+	if err := runMigrations(ctx, connStr); err != nil {
+		fmt.Printf("failed to migrate: %v\n", err)
+		os.Exit(1)
+	}
+
 	cfg := pgxclient.NewPGXConfig(host, port.Port(), username, password, dbname, "disable", 5*time.Second,
 		1*time.Hour, 10*time.Minute, 10, 2)
 
-	client, err := pgxclient.New(context.Background(), cfg)
+	testDB, err = pgxclient.New(context.Background(), cfg)
 	if err != nil {
 		fmt.Printf("failed to connect pgx: %v\n", err)
 		os.Exit(1)
 	}
 
-	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
-	if err != nil {
-		fmt.Printf("failed to get connection string: %v\n", err)
-		os.Exit(1)
-	}
+	// Репозиторий работает как приложение: под ролью, к которой применяются политики RLS
+	cfg.PrepareConn = repository.PrepareConn
+	cfg.Role = appRole
 
-	// Запускаем goose миграции
-	if err := runMigrations(ctx, connStr); err != nil {
-		fmt.Printf("failed to migrate: %v\n", err)
+	client, err := pgxclient.New(context.Background(), cfg)
+	if err != nil {
+		fmt.Printf("failed to connect pgx: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -86,6 +103,7 @@ func TestMain(m *testing.M) {
 	code := m.Run()
 
 	client.Close()
+	testDB.Close()
 	container.Terminate(ctx)
 	os.Exit(code)
 }
@@ -112,9 +130,14 @@ func runMigrations(ctx context.Context, connStr string) error {
 	return nil
 }
 
+// testContext контекст запроса в организации по умолчанию
+func testContext() context.Context {
+	return domain.WithOrganization(context.Background(), domain.DefaultOrganizationID)
+}
+
 func cleanup(t *testing.T) {
 	t.Helper()
-	_, err := testRepo.DB.Exec(context.Background(), "TRUNCATE TABLE subscriptions RESTART IDENTITY CASCADE")
+	_, err := testDB.Exec(context.Background(), "TRUNCATE TABLE subscriptions, subscription_events, idempotency_keys, api_keys, exchange_rates, services RESTART IDENTITY CASCADE")
 	if err != nil {
 		t.Fatalf("cleanup failed: %v", err)
 	}
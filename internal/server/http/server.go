@@ -28,6 +28,19 @@ func NewServer(cfg *config.Config, handler http.Handler) *Server {
 	}
 }
 
+// NewAdminServer creates an HTTP server for the admin listener (metrics) with the given handler.
+func NewAdminServer(cfg *config.Config, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:           net.JoinHostPort(cfg.HTTP.AdminHost, cfg.HTTP.AdminPort),
+			Handler:        handler,
+			ReadTimeout:    cfg.HTTP.ReadTimeout,
+			WriteTimeout:   cfg.HTTP.WriteTimeout,
+			MaxHeaderBytes: cfg.HTTP.MaxHeaderMegabytes << 20,
+		},
+	}
+}
+
 // Run starts the HTTP server.
 func (s *Server) Run() error {
 	return s.httpServer.ListenAndServe()
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// IdempotencyKeyPurger удаляет просроченные ключи идемпотентности
+type IdempotencyKeyPurger interface {
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// IdempotencySweeper периодически удаляет просроченные ключи идемпотентности
+type IdempotencySweeper struct {
+	log      *slog.Logger
+	purger   IdempotencyKeyPurger
+	interval time.Duration
+}
+
+func NewIdempotencySweeper(log *slog.Logger, purger IdempotencyKeyPurger, interval time.Duration) *IdempotencySweeper {
+	return &IdempotencySweeper{
+		log:      log,
+		purger:   purger,
+		interval: interval,
+	}
+}
+
+// Run удаляет просроченные ключи сразу и затем каждые interval, пока ctx не отменён
+func (s *IdempotencySweeper) Run(ctx context.Context) {
+	s.log.Info("idempotency key sweeper started", slog.Duration("interval", s.interval))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		// Результат и ошибки логирует бизнес-слой, следующая попытка — на следующем тике
+		s.purger.PurgeExpiredIdempotencyKeys(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("idempotency key sweeper stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
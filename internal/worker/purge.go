@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
+)
+
+// purgeActor автор очистки в журнале изменений
+const purgeActor = "system"
+
+// SubscriptionPurger окончательно удаляет подписки, удалённые больше retention назад
+type SubscriptionPurger interface {
+	PurgeDeletedSubscriptions(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// Purger периодически очищает удалённые подписки по сроку хранения
+type Purger struct {
+	log       *slog.Logger
+	purger    SubscriptionPurger
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(log *slog.Logger, purger SubscriptionPurger, retention, interval time.Duration) *Purger {
+	return &Purger{
+		log:       log,
+		purger:    purger,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run очищает удалённые подписки сразу и затем каждые interval, пока ctx не отменён
+func (p *Purger) Run(ctx context.Context) {
+	p.log.Info("subscription purger started",
+		slog.Duration("retention", p.retention), slog.Duration("interval", p.interval))
+
+	ctx = domain.WithAuditMeta(ctx, domain.AuditMeta{Actor: purgeActor})
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		// Результат и ошибки логирует бизнес-слой, следующая попытка — на следующем тике
+		p.purger.PurgeDeletedSubscriptions(ctx, p.retention)
+
+		select {
+		case <-ctx.Done():
+			p.log.Info("subscription purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,6 +22,11 @@ type Config struct {
 	MinConns        int32
 	MaxConnLifeTime time.Duration
 	MaxConnIdleTime time.Duration
+	// PrepareConn вызывается перед выдачей соединения из пула (см. pgxpool.Config.PrepareConn)
+	PrepareConn func(ctx context.Context, conn *pgx.Conn) (bool, error)
+	// Role роль, которую соединение принимает сразу после подключения (SET ROLE);
+	// пустая — остаётся роль пользователя
+	Role string
 }
 
 func NewPGXConfig(host, port, user, password, database, sslMode string, connectTimeout, maxConnLifeTime, maxConnIdleTime time.Duration,
@@ -68,6 +74,14 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 	poolConfig.MinConns = cfg.MinConns
 	poolConfig.MaxConnLifetime = cfg.MaxConnLifeTime
 	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.PrepareConn = cfg.PrepareConn
+	if cfg.Role != "" {
+		setRole := "SET ROLE " + pgx.Identifier{cfg.Role}.Sanitize()
+		poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			_, err := conn.Exec(ctx, setRole)
+			return err
+		}
+	}
 
 	// Таймаут на подключение
 	connectCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
@@ -88,4 +102,3 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 		Pool: pool,
 	}, nil
 }
-
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TYPE billing_period AS ENUM ('weekly', 'monthly', 'quarterly', 'yearly');
+
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period billing_period NOT NULL DEFAULT 'monthly';
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS billing_period;
+
+DROP TYPE IF EXISTS billing_period;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$');
+
+-- rate — стоимость одной единицы currency в базовой валюте (RUB) в месяце month
+CREATE TABLE exchange_rates (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    currency CHAR(3) NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    month DATE NOT NULL,
+    rate NUMERIC(20, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (currency, month)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Цена подписки, действующая с месяца effective_from до следующей записи
+CREATE TABLE subscription_price_history (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, effective_from)
+);
+
+INSERT INTO subscription_price_history (subscription_id, price, effective_from)
+SELECT id, price, start_date
+FROM subscriptions;
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_price_history;
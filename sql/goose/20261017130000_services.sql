@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE services (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(100),
+    default_price INTEGER CHECK (default_price > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Названия, по которым находится сервис; каноническое название тоже хранится здесь
+CREATE TABLE service_aliases (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    service_id BIGINT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_service_aliases_alias ON service_aliases (LOWER(alias));
+CREATE INDEX idx_service_aliases_service_id ON service_aliases (service_id);
+
+-- Каталог из уже сохранённых названий: варианты, отличающиеся регистром
+-- и пробелами по краям, сводятся к самому частому написанию
+WITH variants AS (
+    SELECT TRIM(service_name) AS name, COUNT(*) AS cnt
+    FROM subscriptions
+    GROUP BY TRIM(service_name)
+)
+INSERT INTO services (name)
+SELECT DISTINCT ON (LOWER(name)) name
+FROM variants
+ORDER BY LOWER(name), cnt DESC, name;
+
+INSERT INTO service_aliases (service_id, alias)
+SELECT id, name
+FROM services;
+
+ALTER TABLE subscriptions
+    ADD COLUMN service_id BIGINT REFERENCES services (id);
+
+UPDATE subscriptions s
+SET service_id = sv.id,
+    service_name = sv.name
+FROM services sv
+WHERE LOWER(sv.name) = LOWER(TRIM(s.service_name));
+
+ALTER TABLE subscriptions
+    ALTER COLUMN service_id SET NOT NULL;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions (service_id);
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS service_id;
+
+DROP TABLE IF EXISTS service_aliases;
+
+DROP TABLE IF EXISTS services;
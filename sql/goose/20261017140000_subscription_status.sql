@@ -0,0 +1,25 @@
+-- +goose Up
+-- Статус expired не хранится: подписка считается истёкшей, когда end_date в прошлом
+CREATE TYPE subscription_status AS ENUM ('active', 'paused', 'cancelled');
+
+ALTER TABLE subscriptions
+    ADD COLUMN status subscription_status NOT NULL DEFAULT 'active';
+
+-- Интервалы приостановки по месяцам включительно; end_month IS NULL — пауза продолжается
+CREATE TABLE subscription_pauses (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    start_month DATE NOT NULL,
+    end_month DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_month IS NULL OR end_month >= start_month)
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_id ON subscription_pauses (subscription_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_pauses;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS subscription_status;
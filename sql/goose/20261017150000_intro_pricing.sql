@@ -0,0 +1,14 @@
+-- +goose Up
+-- Первые trial_months месяцев бесплатны, следующие intro_months оплачиваются по intro_price
+ALTER TABLE subscriptions
+    ADD COLUMN trial_months INTEGER NOT NULL DEFAULT 0 CHECK (trial_months >= 0),
+    ADD COLUMN intro_price INTEGER CHECK (intro_price >= 0),
+    ADD COLUMN intro_months INTEGER NOT NULL DEFAULT 0 CHECK (intro_months >= 0),
+    ADD CONSTRAINT subscriptions_intro_check CHECK ((intro_price IS NULL) = (intro_months = 0));
+
+-- +goose Down
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS subscriptions_intro_check,
+    DROP COLUMN IF EXISTS intro_months,
+    DROP COLUMN IF EXISTS intro_price,
+    DROP COLUMN IF EXISTS trial_months;
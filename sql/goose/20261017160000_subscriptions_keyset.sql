@@ -0,0 +1,8 @@
+-- +goose Up
+-- Индексы для курсорной пагинации списков подписок по (created_at, id)
+CREATE INDEX idx_subscriptions_created_at_id ON subscriptions (created_at DESC, id DESC);
+CREATE INDEX idx_subscriptions_user_id_created_at_id ON subscriptions (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscriptions_user_id_created_at_id;
+DROP INDEX IF EXISTS idx_subscriptions_created_at_id;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Удалённая подписка остаётся в таблице до очистки по сроку хранения; deleted_at IS NULL — не удалена
+ALTER TABLE subscriptions
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Для очистки удалённых подписок
+CREATE INDEX idx_subscriptions_deleted_at ON subscriptions (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TYPE subscription_event_action AS ENUM ('created', 'updated', 'deleted', 'restored', 'purged');
+
+-- Журнал изменений подписок. Внешнего ключа на subscriptions нет: история
+-- должна пережить окончательную очистку подписки.
+CREATE TABLE subscription_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    action subscription_event_action NOT NULL,
+    before JSONB,
+    after JSONB,
+    actor TEXT,
+    request_id TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_events_subscription_id ON subscription_events (subscription_id, id DESC);
+CREATE INDEX idx_subscription_events_created_at ON subscription_events (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_events;
+
+DROP TYPE IF EXISTS subscription_event_action;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Долгоживущие ключи машинных клиентов. Хранится только SHA-256 ключа;
+-- prefix — начало ключа, по которому его узнаёт владелец.
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    -- Пользователь, к подпискам которого ограничен ключ без права users:all
+    user_id UUID,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
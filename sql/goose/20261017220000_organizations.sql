@@ -0,0 +1,230 @@
+-- +goose Up
+-- Организации (подразделения). Подписки, журнал, ключи API, каталог сервисов,
+-- курсы валют и ответы на идемпотентные запросы организации недоступны остальным.
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Организация по умолчанию: ей принадлежат данные, созданные до разделения,
+-- и субъекты без организации
+INSERT INTO organizations (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default');
+
+-- Значение по умолчанию нужно только для заполнения существующих строк
+ALTER TABLE subscriptions
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE subscriptions
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE subscription_events
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE subscription_events
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE api_keys
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE api_keys
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE services
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE services
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE service_aliases
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE service_aliases
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+-- Синоним уникален в каталоге своей организации
+DROP INDEX idx_service_aliases_alias;
+CREATE UNIQUE INDEX idx_service_aliases_alias ON service_aliases (organization_id, LOWER(alias));
+
+-- Подписки и синонимы ссылаются только на сервис своей организации
+ALTER TABLE services
+    ADD CONSTRAINT services_id_organization_id_key UNIQUE (id, organization_id);
+ALTER TABLE service_aliases
+    ADD CONSTRAINT service_aliases_service_organization_fkey
+    FOREIGN KEY (service_id, organization_id) REFERENCES services (id, organization_id) ON DELETE CASCADE;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_service_organization_fkey
+    FOREIGN KEY (service_id, organization_id) REFERENCES services (id, organization_id);
+
+-- Ключ идемпотентности уникален в организации: один субъект в разных организациях
+-- не получит ответ, сохранённый для другой
+ALTER TABLE idempotency_keys
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE idempotency_keys
+    ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys
+    ADD PRIMARY KEY (organization_id, key);
+
+-- Курсы валют ведёт каждая организация: право catalog:write выдаётся в организации,
+-- и администратор подразделения не должен менять курсы, по которым считают другие.
+-- Существующие курсы остаются у организации по умолчанию
+ALTER TABLE exchange_rates
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE exchange_rates
+    ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE exchange_rates
+    DROP CONSTRAINT exchange_rates_currency_month_key;
+ALTER TABLE exchange_rates
+    ADD CONSTRAINT exchange_rates_organization_id_currency_month_key UNIQUE (organization_id, currency, month);
+
+CREATE INDEX idx_subscriptions_organization_id ON subscriptions (organization_id, user_id);
+CREATE INDEX idx_services_organization_id ON services (organization_id, name);
+CREATE INDEX idx_subscription_events_organization_id ON subscription_events (organization_id, id DESC);
+CREATE INDEX idx_api_keys_organization_id ON api_keys (organization_id, id DESC);
+
+-- Запросы приложения сами ограничены организацией, политики страхуют от запроса
+-- без такого условия. Организацию запроса приложение передаёт в app.organization_id
+-- при выдаче соединения из пула; без неё политики не пропускают ни одной строки,
+-- поэтому фоновые задачи обходят организации по очереди.
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON subscriptions
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+ALTER TABLE subscription_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON subscription_events
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON api_keys
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+-- Организация ключа определяется по самому ключу: при аутентификации его хэш
+-- передаётся в app.api_key_hash, и виден только ключ с этим хэшем
+CREATE POLICY api_key_lookup ON api_keys
+    FOR SELECT
+    USING (key_hash = NULLIF(current_setting('app.api_key_hash', true), ''));
+
+ALTER TABLE services ENABLE ROW LEVEL SECURITY;
+ALTER TABLE services FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON services
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+ALTER TABLE service_aliases ENABLE ROW LEVEL SECURITY;
+ALTER TABLE service_aliases FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON service_aliases
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+-- История цен и паузы видны вместе со своей подпиской: подзапрос к subscriptions
+-- сам ограничен её политикой
+ALTER TABLE subscription_price_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_price_history FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON subscription_price_history
+    USING (EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = subscription_id));
+
+ALTER TABLE subscription_pauses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_pauses FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON subscription_pauses
+    USING (EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = subscription_id));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON idempotency_keys
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+ALTER TABLE exchange_rates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates FORCE ROW LEVEL SECURITY;
+CREATE POLICY organization_isolation ON exchange_rates
+    USING (organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+-- Суперпользователь и роли с BYPASSRLS политики не проверяют, поэтому приложение
+-- работает под ролью subscriptions_app (SET ROLE после подключения, см. PG_ROLE).
+-- Пользователь, под которым приложение подключается, должен быть её членом.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'subscriptions_app') THEN
+        CREATE ROLE subscriptions_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+GRANT subscriptions_app TO CURRENT_USER;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO subscriptions_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO subscriptions_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO subscriptions_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO subscriptions_app;
+-- Организации заводятся вне приложения, миграциями управляет goose
+REVOKE INSERT, UPDATE, DELETE ON organizations FROM subscriptions_app;
+REVOKE ALL ON goose_db_version FROM subscriptions_app;
+
+-- +goose Down
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL ON SEQUENCES FROM subscriptions_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL ON TABLES FROM subscriptions_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM subscriptions_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM subscriptions_app;
+DROP ROLE IF EXISTS subscriptions_app;
+
+DROP POLICY IF EXISTS organization_isolation ON exchange_rates;
+ALTER TABLE exchange_rates NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON subscription_pauses;
+ALTER TABLE subscription_pauses NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscription_pauses DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON subscription_price_history;
+ALTER TABLE subscription_price_history NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscription_price_history DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON service_aliases;
+ALTER TABLE service_aliases NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE service_aliases DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON services;
+ALTER TABLE services NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE services DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS api_key_lookup ON api_keys;
+DROP POLICY IF EXISTS organization_isolation ON api_keys;
+ALTER TABLE api_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON subscription_events;
+ALTER TABLE subscription_events NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscription_events DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS organization_isolation ON subscriptions;
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_service_organization_fkey;
+ALTER TABLE service_aliases DROP CONSTRAINT IF EXISTS service_aliases_service_organization_fkey;
+ALTER TABLE services DROP CONSTRAINT IF EXISTS services_id_organization_id_key;
+
+DROP INDEX IF EXISTS idx_service_aliases_alias;
+CREATE UNIQUE INDEX idx_service_aliases_alias ON service_aliases (LOWER(alias));
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+ALTER TABLE exchange_rates DROP CONSTRAINT IF EXISTS exchange_rates_organization_id_currency_month_key;
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE exchange_rates ADD CONSTRAINT exchange_rates_currency_month_key UNIQUE (currency, month);
+
+ALTER TABLE service_aliases DROP COLUMN IF EXISTS organization_id;
+
+ALTER TABLE services DROP COLUMN IF EXISTS organization_id;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS organization_id;
+
+ALTER TABLE subscription_events DROP COLUMN IF EXISTS organization_id;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
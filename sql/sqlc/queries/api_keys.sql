@@ -0,0 +1,31 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, scopes, user_id, created_by, expires_at, organization_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListAPIKeys :many
+SELECT *
+FROM api_keys
+WHERE organization_id = sqlc.arg('organization_id')
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- Организация ключа ещё неизвестна: по ключу она и определяется. Политика RLS
+-- пропускает ключ, хэш которого передан в app.api_key_hash
+-- name: GetActiveAPIKeyByHash :one
+SELECT *
+FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg('used_at')
+WHERE id = $1 AND organization_id = sqlc.arg('organization_id');
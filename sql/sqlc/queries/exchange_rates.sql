@@ -0,0 +1,33 @@
+-- name: CreateExchangeRate :one
+INSERT INTO exchange_rates (
+    currency,
+    month,
+    rate,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetExchangeRateByID :one
+SELECT *
+FROM exchange_rates
+WHERE id = $1 AND organization_id = $2;
+
+-- name: ListExchangeRates :many
+SELECT *
+FROM exchange_rates
+WHERE organization_id = sqlc.arg('organization_id')
+    AND (sqlc.narg('currency')::TEXT IS NULL OR currency = sqlc.narg('currency'))
+ORDER BY month DESC, currency
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateExchangeRate :one
+UPDATE exchange_rates
+SET rate = $2
+WHERE id = $1 AND organization_id = $3
+RETURNING *;
+
+-- name: DeleteExchangeRate :execrows
+DELETE FROM exchange_rates
+WHERE id = $1 AND organization_id = $2;
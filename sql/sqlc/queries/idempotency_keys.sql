@@ -0,0 +1,35 @@
+-- name: ReserveIdempotencyKey :one
+-- Просроченный ключ, который ещё не очищен, занимается заново
+INSERT INTO idempotency_keys (key, request_hash, expires_at, organization_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (organization_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response_headers = NULL,
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_keys
+WHERE key = $1 AND organization_id = $2;
+
+-- name: SaveIdempotentResponse :exec
+-- Хэш запроса, тело которого читалось потоком, становится известен только к ответу
+UPDATE idempotency_keys
+SET request_hash = $5,
+    status_code = $2,
+    response_headers = $3,
+    response_body = $4
+WHERE key = $1 AND organization_id = $6;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND organization_id = $2 AND status_code IS NULL;
+
+-- name: PurgeExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE organization_id = sqlc.arg('organization_id') AND expires_at <= sqlc.arg('now');
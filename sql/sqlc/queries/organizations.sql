@@ -0,0 +1,4 @@
+-- name: ListOrganizationIDs :many
+SELECT id
+FROM organizations
+ORDER BY id;
@@ -0,0 +1,80 @@
+-- name: CreateService :one
+INSERT INTO services (
+    name,
+    category,
+    default_price,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetServiceByID :one
+SELECT *
+FROM services
+WHERE id = $1 AND organization_id = $2;
+
+-- name: GetServiceForUpdate :one
+SELECT *
+FROM services
+WHERE id = $1 AND organization_id = $2
+FOR UPDATE;
+
+-- name: GetServiceByAlias :one
+SELECT s.*
+FROM services s
+JOIN service_aliases a ON a.service_id = s.id
+WHERE a.organization_id = sqlc.arg('organization_id') AND LOWER(a.alias) = LOWER(sqlc.arg('alias'));
+
+-- name: ListServices :many
+SELECT *
+FROM services
+WHERE organization_id = sqlc.arg('organization_id')
+    AND (sqlc.narg('category')::TEXT IS NULL OR category = sqlc.narg('category'))
+ORDER BY name
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateService :one
+UPDATE services
+SET name = COALESCE(sqlc.narg('name'), name),
+    category = COALESCE(sqlc.narg('category'), category),
+    default_price = COALESCE(sqlc.narg('default_price'), default_price)
+WHERE id = sqlc.arg('id') AND organization_id = sqlc.arg('organization_id')
+RETURNING *;
+
+-- name: DeleteService :execrows
+DELETE FROM services
+WHERE id = $1 AND organization_id = $2;
+
+-- name: CreateServiceAlias :exec
+INSERT INTO service_aliases (
+    service_id,
+    alias,
+    organization_id
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: DeleteServiceAliases :exec
+DELETE FROM service_aliases
+WHERE service_id = $1;
+
+-- name: ListServiceAliases :many
+SELECT service_id, alias
+FROM service_aliases
+WHERE service_id = ANY(sqlc.arg('service_ids')::BIGINT[])
+ORDER BY service_id, alias;
+
+-- name: ListServiceSubscriptionsForUpdate :many
+SELECT *
+FROM subscriptions
+WHERE service_id = $1 AND organization_id = $2
+ORDER BY id
+FOR UPDATE;
+
+-- name: RenameServiceSubscriptions :many
+UPDATE subscriptions
+SET service_name = $2,
+    version = version + 1
+WHERE service_id = $1 AND organization_id = $3
+RETURNING *;
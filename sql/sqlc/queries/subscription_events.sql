@@ -0,0 +1,25 @@
+-- name: CreateSubscriptionEvent :exec
+INSERT INTO subscription_events (
+    subscription_id,
+    action,
+    before,
+    after,
+    actor,
+    request_id,
+    organization_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListSubscriptionEvents :many
+SELECT *
+FROM subscription_events
+WHERE organization_id = sqlc.arg('organization_id')
+    AND (sqlc.narg('subscription_id')::BIGINT IS NULL OR subscription_id = sqlc.narg('subscription_id'))
+    AND (sqlc.narg('action')::subscription_event_action IS NULL OR action = sqlc.narg('action'))
+    AND (sqlc.narg('actor')::TEXT IS NULL OR actor = sqlc.narg('actor'))
+    AND (sqlc.narg('request_id')::TEXT IS NULL OR request_id = sqlc.narg('request_id'))
+    AND (sqlc.narg('created_from')::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg('created_from'))
+    AND (sqlc.narg('created_to')::TIMESTAMPTZ IS NULL OR created_at < sqlc.narg('created_to'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,19 @@
+-- Паузы и история цен изменяются в транзакции изменения подписки после её
+-- блокировки запросом, ограниченным организацией (GetSubscriptionForUpdate)
+
+-- name: CreateSubscriptionPause :exec
+INSERT INTO subscription_pauses (
+    subscription_id,
+    start_month
+) VALUES (
+    $1, $2
+);
+
+-- name: CloseSubscriptionPause :exec
+UPDATE subscription_pauses
+SET end_month = sqlc.arg('end_month')
+WHERE subscription_id = sqlc.arg('subscription_id') AND end_month IS NULL;
+
+-- name: DeleteEmptySubscriptionPauses :exec
+DELETE FROM subscription_pauses
+WHERE subscription_id = sqlc.arg('subscription_id') AND end_month IS NULL AND start_month > sqlc.arg('end_month');
@@ -0,0 +1,17 @@
+-- name: UpsertSubscriptionPrice :exec
+INSERT INTO subscription_price_history (
+    subscription_id,
+    price,
+    effective_from
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (subscription_id, effective_from) DO UPDATE
+SET price = EXCLUDED.price;
+
+-- name: ListSubscriptionPrices :many
+SELECT *
+FROM subscription_price_history h
+WHERE h.subscription_id = $1
+    AND EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = h.subscription_id AND s.organization_id = $2)
+ORDER BY effective_from;
@@ -4,30 +4,81 @@ INSERT INTO subscriptions (
     price,
     user_id,
     start_date,
-    end_date
+    end_date,
+    billing_period,
+    currency,
+    service_id,
+    trial_months,
+    intro_price,
+    intro_months,
+    organization_id
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 )
 RETURNING *;
 
 -- name: GetSubscriptionByID :one
 SELECT *
 FROM subscriptions
-WHERE id = $1;
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL;
 
--- name: ListSubscriptions :many
-SELECT *
+-- name: GetSubscriptionOwner :one
+SELECT user_id
 FROM subscriptions
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+WHERE id = $1 AND organization_id = $2;
 
--- name: ListSubscriptionsByUserID :many
+-- name: GetSubscriptionForUpdate :one
 SELECT *
 FROM subscriptions
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE id = $1 AND organization_id = $2
+FOR UPDATE;
+
+-- name: SoftDeleteSubscription :one
+UPDATE subscriptions
+SET deleted_at = NOW(),
+    version = version + 1
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+RETURNING *;
 
--- name: DeleteSubscription :execrows
+-- name: RestoreSubscription :one
+UPDATE subscriptions
+SET deleted_at = NULL,
+    version = version + 1
+WHERE id = $1 AND organization_id = $2 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- Очистка по сроку хранения выполняется фоновой задачей для всех организаций
+-- name: PurgeDeletedSubscriptions :many
 DELETE FROM subscriptions
-WHERE id = $1;
+WHERE organization_id = sqlc.arg('organization_id') AND deleted_at < sqlc.arg('deleted_before')
+RETURNING *;
+
+-- Сводка для метрик складывается из сводок организаций. Истёкшие подписки
+-- отделяются так же, как в domain.SubscriptionStatusAt
+-- name: CountSubscriptionsByStatus :many
+SELECT status,
+    (status <> 'cancelled' AND end_date IS NOT NULL AND end_date < sqlc.arg('month_start'))::BOOLEAN AS expired,
+    COUNT(*)::BIGINT AS count
+FROM subscriptions
+WHERE organization_id = sqlc.arg('organization_id') AND deleted_at IS NULL
+GROUP BY 1, 2;
+
+-- name: UpdateSubscriptionStatus :one
+UPDATE subscriptions
+SET status = sqlc.arg('status'),
+    version = version + 1
+WHERE id = sqlc.arg('id') AND organization_id = sqlc.arg('organization_id')
+    AND status = sqlc.arg('from_status') AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'cancelled',
+    end_date = CASE
+        WHEN end_date IS NULL OR end_date > sqlc.arg('month') THEN sqlc.arg('month')
+        ELSE end_date
+    END,
+    version = version + 1
+WHERE id = sqlc.arg('id') AND organization_id = sqlc.arg('organization_id')
+    AND status <> 'cancelled' AND deleted_at IS NULL
+RETURNING *;
@@ -1,14 +1,21 @@
 package app_test
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 
+	"github.com/Krokozabra213/effective_mobile/internal/auth"
 	handler "github.com/Krokozabra213/effective_mobile/internal/delivery/http"
 	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	"github.com/Krokozabra213/effective_mobile/tests/app/suite"
@@ -561,6 +568,44 @@ func TestCalculateTotalCost_FilterByUserID(t *testing.T) {
 	assert.Equal(t, int64(500), result.TotalCost)
 }
 
+func TestCalculateTotalCost_ProratedMode(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Prorated",
+		"price":        500,
+		"user_id":      userID.String(),
+		"start_date":   "06-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	path := fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=12-2024&user_id=%s&mode=prorated", userID)
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		TotalCost int64 `json:"total_cost"`
+		Count     int64 `json:"count"`
+	}
+	err = resp.JSON(&result)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), result.Count)
+	assert.Equal(t, int64(500*7), result.TotalCost) // 06-2024..12-2024
+}
+
 func TestCalculateTotalCost_FilterByServiceName(t *testing.T) {
 	ctx, st := suite.New(t)
 	t.Cleanup(func() {
@@ -639,3 +684,2481 @@ func TestCalculateTotalCost_InvalidUserID(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
+
+func TestCalculateTotalCost_InvalidMode(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=12-2024&mode=weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCalculateMonthlyCost(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      userID.String(),
+		"start_date":   "02-2024",
+		"end_date":     "03-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	path := fmt.Sprintf("/subscriptions/cost/monthly?start_period=01-2024&end_period=04-2024&user_id=%s", userID)
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.MonthlyCostListResponse
+	err = resp.JSON(&result)
+	require.NoError(t, err)
+
+	require.Len(t, result.Months, 4)
+	assert.Equal(t, handler.NewMonthlyCostResponse("01-2024", 0, 0), result.Months[0])
+	assert.Equal(t, handler.NewMonthlyCostResponse("02-2024", 1000, 1), result.Months[1])
+	assert.Equal(t, handler.NewMonthlyCostResponse("03-2024", 1000, 1), result.Months[2])
+	assert.Equal(t, handler.NewMonthlyCostResponse("04-2024", 0, 0), result.Months[3])
+}
+
+func TestCalculateMonthlyCost_InvalidDateFormat(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost/monthly?start_period=2024-01&end_period=12-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCalculateGroupedCost(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	for _, sub := range []struct {
+		service string
+		price   int
+	}{{"Netflix", 1000}, {"Spotify", 200}, {"Spotify", 200}, {"Okko", 100}} {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": sub.service,
+			"price":        sub.price,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+			"end_date":     "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost/grouped?start_period=01-2024&end_period=12-2024&group_by=service_name&limit=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.CostGroupListResponse
+	err = resp.JSON(&result)
+	require.NoError(t, err)
+
+	require.Len(t, result.Groups, 2)
+	assert.Equal(t, handler.NewCostGroupResponse("Netflix", 1000, 1), result.Groups[0])
+	assert.Equal(t, handler.NewCostGroupResponse("Spotify", 400, 2), result.Groups[1])
+}
+
+func TestCalculateGroupedCost_InvalidGroupBy(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost/grouped?start_period=01-2024&end_period=12-2024&group_by=price")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCreateSubscription_BillingPeriod(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name":   "Yandex Plus",
+		"price":          3990,
+		"billing_period": "yearly",
+		"user_id":        uuid.New().String(),
+		"start_date":     "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var sub handler.SubscriptionResponse
+	err = resp.JSON(&sub)
+	require.NoError(t, err)
+
+	assert.Equal(t, "yearly", sub.BillingPeriod)
+}
+
+func TestCreateSubscription_InvalidBillingPeriod(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name":   "Test",
+		"price":          100,
+		"billing_period": "daily",
+		"user_id":        uuid.New().String(),
+		"start_date":     "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCalculateTotalCost_Amortize(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name":   "Yearly",
+		"price":          1200,
+		"billing_period": "yearly",
+		"user_id":        userID.String(),
+		"start_date":     "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result handler.TotalCostResponse
+
+	// Без амортизации годовая цена списывается целиком в месяце продления
+	path := fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=06-2024&user_id=%s&mode=prorated", userID)
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(1200), result.TotalCost)
+
+	// С амортизацией — 100 в месяц
+	resp, err = st.HTTPClient.GET(ctx, path+"&amortize=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(600), result.TotalCost)
+}
+
+func TestExchangeRates_CRUD(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/exchange-rates", map[string]any{
+		"currency": "usd",
+		"month":    "01-2024",
+		"rate":     90.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created handler.ExchangeRateResponse
+	require.NoError(t, resp.JSON(&created))
+	assert.Equal(t, "USD", created.Currency)
+	assert.Equal(t, "01-2024", created.Month)
+	assert.InDelta(t, 90.5, created.Rate, 1e-9)
+
+	// Повторный курс на тот же месяц
+	resp, err = st.HTTPClient.POST(ctx, "/exchange-rates", map[string]any{
+		"currency": "USD",
+		"month":    "01-2024",
+		"rate":     91,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = st.HTTPClient.PATCH(ctx, fmt.Sprintf("/exchange-rates/%d", created.ID), map[string]any{
+		"rate": 92,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, "/exchange-rates?currency=USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var list handler.ListExchangeRatesResponse
+	require.NoError(t, resp.JSON(&list))
+	require.Len(t, list.ExchangeRates, 1)
+	assert.InDelta(t, 92, list.ExchangeRates[0].Rate, 1e-9)
+
+	resp, err = st.HTTPClient.DELETE(ctx, fmt.Sprintf("/exchange-rates/%d", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/exchange-rates/%d", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCreateExchangeRate_InvalidRate(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/exchange-rates", map[string]any{
+		"currency": "USD",
+		"month":    "01-2024",
+		"rate":     0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestExchangeRates_InvalidID(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	resp, err := st.HTTPClient.PATCH(ctx, "/exchange-rates/0", map[string]any{"rate": 92})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETE(ctx, "/exchange-rates/-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCalculateTotalCost_Currency(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        10,
+		"currency":     "USD",
+		"user_id":      userID.String(),
+		"start_date":   "01-2024",
+		"end_date":     "02-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var sub handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&sub))
+	assert.Equal(t, "USD", sub.Currency)
+
+	path := fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=02-2024&user_id=%s&mode=prorated", userID)
+
+	// Курса за февраль нет — расчёт невозможен
+	resp, err = st.HTTPClient.POST(ctx, "/exchange-rates", map[string]any{
+		"currency": "USD",
+		"month":    "01-2024",
+		"rate":     90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var errResp ErrorResponse
+	require.NoError(t, resp.JSON(&errResp))
+	assert.Contains(t, errResp.Error, "USD 02-2024")
+
+	// Помесячная разбивка без mode тоже требует курс за каждый месяц подписки
+	for _, monthly := range []string{"/subscriptions/cost/monthly", "/subscriptions/cost/monthly/export"} {
+		resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("%s?start_period=01-2024&end_period=02-2024&user_id=%s", monthly, userID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, monthly)
+
+		require.NoError(t, resp.JSON(&errResp))
+		assert.Contains(t, errResp.Error, "USD 02-2024")
+	}
+
+	resp, err = st.HTTPClient.POST(ctx, "/exchange-rates", map[string]any{
+		"currency": "USD",
+		"month":    "02-2024",
+		"rate":     100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result handler.TotalCostResponse
+
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(10*90+10*100), result.TotalCost)
+
+	resp, err = st.HTTPClient.GET(ctx, path+"&currency=USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(20), result.TotalCost)
+}
+
+func TestCalculateTotalCost_InvalidCurrency(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=12-2024&currency=dollar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListSubscriptionPrices(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        800,
+		"user_id":      userID.String(),
+		"start_date":   "01-2024",
+		"end_date":     "12-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&created))
+
+	resp, err = st.HTTPClient.PATCH(ctx, fmt.Sprintf("/subscriptions/%d", created.ID), map[string]any{
+		"price": 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d/prices", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var prices handler.ListSubscriptionPricesResponse
+	require.NoError(t, resp.JSON(&prices))
+	require.Len(t, prices.Prices, 2)
+	assert.Equal(t, int32(800), prices.Prices[0].Price)
+	assert.Equal(t, "01-2024", prices.Prices[0].EffectiveFrom)
+	assert.Equal(t, int32(1000), prices.Prices[1].Price)
+
+	// Подписка закончилась до изменения цены — стоимость за 2024 не меняется
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=12-2024&user_id=%s&mode=prorated", userID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.TotalCostResponse
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(800*12), result.TotalCost)
+}
+
+func TestListSubscriptionPrices_NotFound(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/99999/prices")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServices_CRUD(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/services", map[string]any{
+		"name":          "Yandex Plus",
+		"aliases":       []string{"Яндекс Плюс"},
+		"category":      "music",
+		"default_price": 400,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created handler.ServiceResponse
+	require.NoError(t, resp.JSON(&created))
+	assert.Equal(t, "Yandex Plus", created.Name)
+	assert.Equal(t, []string{"Яндекс Плюс"}, created.Aliases)
+
+	// Синоним уже занят
+	resp, err = st.HTTPClient.POST(ctx, "/services", map[string]any{
+		"name": "яндекс плюс",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = st.HTTPClient.PATCH(ctx, fmt.Sprintf("/services/%d", created.ID), map[string]any{
+		"default_price": 450,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var updated handler.ServiceResponse
+	require.NoError(t, resp.JSON(&updated))
+	require.NotNil(t, updated.DefaultPrice)
+	assert.Equal(t, int32(450), *updated.DefaultPrice)
+
+	resp, err = st.HTTPClient.GET(ctx, "/services?category=music")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var list handler.ListServicesResponse
+	require.NoError(t, resp.JSON(&list))
+	require.Len(t, list.Services, 1)
+
+	resp, err = st.HTTPClient.DELETE(ctx, fmt.Sprintf("/services/%d", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestServices_InvalidID(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	resp, err := st.HTTPClient.PATCH(ctx, "/services/0", map[string]any{"default_price": 450})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = st.HTTPClient.DELETE(ctx, "/services/-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCreateSubscription_ResolvesServiceAlias(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/services", map[string]any{
+		"name":          "Yandex Plus",
+		"aliases":       []string{"Яндекс Плюс"},
+		"default_price": 400,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var service handler.ServiceResponse
+	require.NoError(t, resp.JSON(&service))
+
+	userID := uuid.New()
+
+	// Цена не указана — берётся цена сервиса по умолчанию
+	for _, name := range []string{"Яндекс Плюс", "yandex plus"} {
+		resp, err = st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": name,
+			"user_id":      userID.String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var sub handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&sub))
+		assert.Equal(t, service.ID, sub.ServiceID)
+		assert.Equal(t, "Yandex Plus", sub.ServiceName)
+		assert.Equal(t, int32(400), sub.Price)
+	}
+
+	resp, err = st.HTTPClient.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=01-2024&service_name=%D0%AF%D0%BD%D0%B4%D0%B5%D0%BA%D1%81%20%D0%9F%D0%BB%D1%8E%D1%81")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.TotalCostResponse
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(800), result.TotalCost)
+	assert.Equal(t, int64(2), result.Count)
+
+	// На сервис ссылаются подписки — удалить нельзя
+	resp, err = st.HTTPClient.DELETE(ctx, fmt.Sprintf("/services/%d", service.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestCreateSubscription_PriceRequired(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "No Default Price",
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        800,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var sub handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&sub))
+	assert.Equal(t, "active", sub.Status)
+
+	steps := []struct {
+		action string
+		status int
+		result string
+	}{
+		{"resume", http.StatusConflict, ""},
+		{"pause", http.StatusOK, "paused"},
+		{"pause", http.StatusConflict, ""},
+		{"resume", http.StatusOK, "active"},
+		{"cancel", http.StatusOK, "cancelled"},
+		{"resume", http.StatusConflict, ""},
+		{"cancel", http.StatusConflict, ""},
+	}
+
+	for _, step := range steps {
+		resp, err = st.HTTPClient.POST(ctx, fmt.Sprintf("/subscriptions/%d/%s", sub.ID, step.action), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, step.status, resp.StatusCode, step.action)
+
+		if step.result != "" {
+			var updated handler.SubscriptionResponse
+			require.NoError(t, resp.JSON(&updated))
+			assert.Equal(t, step.result, updated.Status, step.action)
+		}
+	}
+}
+
+func TestPauseSubscription_NotFound(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions/99999/pause", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCalculateTotalCost_IntroPricing(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Yandex Plus",
+		"price":        400,
+		"trial_months": 1,
+		"intro_price":  1,
+		"intro_months": 3,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var sub handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&sub))
+	assert.Equal(t, int32(1), sub.TrialMonths)
+	assert.Equal(t, int32(3), sub.IntroMonths)
+	require.NotNil(t, sub.IntroPrice)
+	assert.Equal(t, int32(1), *sub.IntroPrice)
+
+	resp, err = st.HTTPClient.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=06-2024&mode=prorated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.TotalCostResponse
+	require.NoError(t, resp.JSON(&result))
+	assert.Equal(t, int64(0+1*3+400*2), result.TotalCost)
+}
+
+func TestCreateSubscription_InvalidIntroPricing(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	cases := map[string]map[string]any{
+		"negative trial":           {"trial_months": -1},
+		"intro price without term": {"intro_price": 1},
+		"intro term without price": {"intro_months": 3},
+		"negative intro price":     {"intro_price": -1, "intro_months": 3},
+	}
+
+	for name, fields := range cases {
+		body := map[string]any{
+			"service_name": "Test",
+			"price":        100,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		}
+		for k, v := range fields {
+			body[k] = v
+		}
+
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+	}
+}
+
+func TestListSubscriptions_Cursor(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	for i := 0; i < 3; i++ {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        800,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions?limit=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var first handler.ListSubscriptionsResponse
+	require.NoError(t, resp.JSON(&first))
+	require.Len(t, first.Subscriptions, 2)
+	require.NotNil(t, first.NextCursor)
+
+	resp, err = st.HTTPClient.GET(ctx, "/subscriptions?limit=2&cursor="+*first.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var second handler.ListSubscriptionsResponse
+	require.NoError(t, resp.JSON(&second))
+	require.Len(t, second.Subscriptions, 1)
+	assert.Nil(t, second.NextCursor)
+	assert.NotEqual(t, first.Subscriptions[1].ID, second.Subscriptions[0].ID)
+}
+
+func TestListSubscriptions_InvalidCursor(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions?cursor=not-a-cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListSubscriptions_FilterAndSort(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	for _, sub := range []struct {
+		name  string
+		price int
+	}{{"Netflix", 800}, {"Spotify", 200}, {"Yandex Plus", 400}} {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": sub.name,
+			"price":        sub.price,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions?price_min=300&sort=-price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result handler.ListSubscriptionsResponse
+	require.NoError(t, resp.JSON(&result))
+	require.Len(t, result.Subscriptions, 2)
+	assert.Equal(t, "Netflix", result.Subscriptions[0].ServiceName)
+	assert.Equal(t, "Yandex Plus", result.Subscriptions[1].ServiceName)
+}
+
+func TestListSubscriptions_InvalidFilter(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	for _, query := range []string{
+		"sort=name",
+		"price_min=abc",
+		"price_min=500&price_max=100",
+		"active_in=2024-01",
+		"has_end_date=maybe",
+		"start_from=06-2024&start_to=01-2024",
+	} {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions?"+query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestListSubscriptions_PaginationMetadata(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	for i := 0; i < 5; i++ {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        800,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions?limit=2&offset=2&sort=price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page handler.ListSubscriptionsResponse
+	require.NoError(t, resp.JSON(&page))
+	assert.Len(t, page.Subscriptions, 2)
+	assert.Equal(t, int64(5), page.Total)
+	assert.Equal(t, int32(2), page.Limit)
+	assert.Equal(t, int32(2), page.Offset)
+	assert.True(t, page.HasMore)
+	assert.Nil(t, page.NextCursor)
+
+	link := resp.Headers.Get("Link")
+	assert.Contains(t, link, `</subscriptions?limit=2&offset=4&sort=price>; rel="next"`)
+	assert.Contains(t, link, `</subscriptions?limit=2&offset=0&sort=price>; rel="prev"`)
+
+	resp, err = st.HTTPClient.GET(ctx, "/subscriptions?limit=2&offset=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, resp.JSON(&page))
+	assert.Len(t, page.Subscriptions, 1)
+	assert.False(t, page.HasMore)
+	assert.NotContains(t, resp.Headers.Get("Link"), `rel="next"`)
+}
+
+func TestCreateSubscriptionsBatch(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	items := []map[string]any{
+		{"service_name": "Netflix", "price": 800, "user_id": uuid.New().String(), "start_date": "01-2024"},
+		{"service_name": "Spotify", "price": 200, "user_id": uuid.New().String(), "start_date": "2024-01"},
+		{"service_name": "Yandex Plus", "price": 400, "user_id": uuid.New().String(), "start_date": "03-2024"},
+	}
+
+	t.Run("atomic rejects whole batch", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions/batch", map[string]any{"items": items})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var result handler.BatchCreateSubscriptionsResponse
+		require.NoError(t, resp.JSON(&result))
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		require.Len(t, result.Results, 1)
+		assert.Equal(t, 1, result.Results[0].Index)
+
+		resp, err = st.HTTPClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		assert.Equal(t, int64(0), list.Total)
+	})
+
+	t.Run("non-atomic creates valid items", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions/batch?atomic=false", map[string]any{"items": items})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result handler.BatchCreateSubscriptionsResponse
+		require.NoError(t, resp.JSON(&result))
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		require.Len(t, result.Results, 3)
+		assert.NotNil(t, result.Results[0].Subscription)
+		assert.NotEmpty(t, result.Results[1].Error)
+		assert.Equal(t, "Yandex Plus", result.Results[2].Subscription.ServiceName)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions/batch", map[string]any{"items": []any{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New().String()
+	csvBody := "service_name,price,user_id,start_date,end_date\n" +
+		"Netflix,800," + userID + ",01-2024,\n" +
+		"Spotify,abc," + userID + ",01-2024,\n" +
+		"Yandex Plus,400," + userID + ",2024-13,\n" +
+		"Kinopoisk,300," + userID + ",02-2024,06-2024\n"
+
+	t.Run("dry run", func(t *testing.T) {
+		resp, err := st.HTTPClient.POSTRaw(ctx, "/subscriptions/import?dry_run=true", "text/csv", []byte(csvBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result handler.ImportSubscriptionsResponse
+		require.NoError(t, resp.JSON(&result))
+		assert.True(t, result.DryRun)
+		require.Len(t, result.Accepted, 2)
+		assert.Equal(t, 2, result.Accepted[0].Line)
+		assert.Nil(t, result.Accepted[0].ID)
+		require.Len(t, result.Rejected, 2)
+		assert.Equal(t, 3, result.Rejected[0].Line)
+		assert.Equal(t, 4, result.Rejected[1].Line)
+
+		resp, err = st.HTTPClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		assert.Equal(t, int64(0), list.Total)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		resp, err := st.HTTPClient.POSTRaw(ctx, "/subscriptions/import", "text/csv", []byte(csvBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result handler.ImportSubscriptionsResponse
+		require.NoError(t, resp.JSON(&result))
+		assert.False(t, result.DryRun)
+		require.Len(t, result.Accepted, 2)
+		assert.NotNil(t, result.Accepted[0].ID)
+		assert.Equal(t, 5, result.Accepted[1].Line)
+		require.Len(t, result.Rejected, 2)
+
+		resp, err = st.HTTPClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		assert.Equal(t, int64(2), list.Total)
+	})
+
+	t.Run("interrupted after committed batch", func(t *testing.T) {
+		// Первый пакет — подписки самого пользователя, во втором чужая подписка
+		owner := uuid.New()
+		user := st.ClientAs(domain.Principal{Subject: owner.String(), Kind: domain.PrincipalUser, Role: domain.RoleUser})
+
+		var body bytes.Buffer
+		body.WriteString("service_name,price,user_id,start_date\n")
+		for range handler.MaxBatchSize {
+			body.WriteString("Netflix,800," + owner.String() + ",01-2024\n")
+		}
+		body.WriteString("Netflix,800," + uuid.New().String() + ",01-2024\n")
+
+		resp, err := user.POSTRaw(ctx, "/subscriptions/import", "text/csv", body.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result handler.ImportSubscriptionsResponse
+		require.NoError(t, resp.JSON(&result))
+		assert.Len(t, result.Accepted, handler.MaxBatchSize)
+		require.NotNil(t, result.Interrupted)
+		assert.Equal(t, handler.MaxBatchSize+2, result.Interrupted.Line)
+		assert.Equal(t, "access denied", result.Interrupted.Error)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		resp, err := st.HTTPClient.POSTRaw(ctx, "/subscriptions/import", "text/csv", []byte("service_name,price\nNetflix,800\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("wrong content type", func(t *testing.T) {
+		resp, err := st.HTTPClient.POSTRaw(ctx, "/subscriptions/import", "application/json", []byte(csvBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+}
+
+func TestExportSubscriptions(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+	for _, price := range []int{100, 300, 200} {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        price,
+			"user_id":      userID.String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Spotify",
+		"price":        500,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	t.Run("csv", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/export?user_id=%s&sort=price", userID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Headers.Get("Content-Type"), "text/csv")
+
+		records, err := csv.NewReader(bytes.NewReader(resp.Body)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, "id", records[0][0])
+		assert.Equal(t, "price", records[0][3])
+		assert.Equal(t, []string{"100", "200", "300"}, []string{records[1][3], records[2][3], records[3][3]})
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/export?format=ndjson")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Headers.Get("Content-Type"))
+
+		var subs []handler.SubscriptionResponse
+		scanner := bufio.NewScanner(bytes.NewReader(resp.Body))
+		for scanner.Scan() {
+			var sub handler.SubscriptionResponse
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &sub))
+			subs = append(subs, sub)
+		}
+		require.Len(t, subs, 4)
+		assert.Equal(t, "Spotify", subs[0].ServiceName)
+	})
+
+	t.Run("empty csv has header", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/export?price_min=10000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		records, err := csv.NewReader(bytes.NewReader(resp.Body)).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/export?format=xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/export?price_min=300&price_max=100")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestExportMonthlyCost(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      userID.String(),
+		"start_date":   "02-2024",
+		"end_date":     "03-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	path := fmt.Sprintf("/subscriptions/cost/monthly/export?start_period=01-2024&end_period=04-2024&user_id=%s", userID)
+	resp, err = st.HTTPClient.GET(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	records, err := csv.NewReader(bytes.NewReader(resp.Body)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"month", "total_cost", "count"},
+		{"01-2024", "0", "0"},
+		{"02-2024", "1000", "1"},
+		{"03-2024", "1000", "1"},
+		{"04-2024", "0", "0"},
+	}, records)
+}
+
+func TestCostReport(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+	for _, item := range []map[string]any{
+		{"service_name": "Netflix", "price": 1000, "user_id": userID.String(), "start_date": "01-2024"},
+		{"service_name": "Spotify", "price": 200, "user_id": userID.String(), "start_date": "01-2024"},
+		{"service_name": "Spotify", "price": 200, "user_id": uuid.New().String(), "start_date": "01-2024"},
+	} {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions", item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost/report?start_period=01-2024&end_period=03-2024&mode=prorated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", resp.Headers.Get("Content-Type"))
+	assert.Contains(t, resp.Headers.Get("Content-Disposition"), "cost_report_01-2024_03-2024.xlsx")
+
+	book, err := excelize.OpenReader(bytes.NewReader(resp.Body))
+	require.NoError(t, err)
+	defer book.Close()
+
+	assert.Equal(t, []string{"Summary", "By service", "By user", "Subscriptions"}, book.GetSheetList())
+
+	totalCost, err := book.GetCellValue("Summary", "B6")
+	require.NoError(t, err)
+	assert.Equal(t, "4200", totalCost)
+
+	services, err := book.GetRows("By service")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"service_name", "total_cost", "count"},
+		{"Netflix", "3000", "1"},
+		{"Spotify", "1200", "2"},
+	}, services)
+
+	users, err := book.GetRows("By user")
+	require.NoError(t, err)
+	assert.Len(t, users, 3)
+
+	subscriptions, err := book.GetRows("Subscriptions")
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 4)
+
+	t.Run("missing period", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost/report")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestSubscriptionSoftDelete(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userID := uuid.New()
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      userID.String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&created))
+
+	t.Run("restore active subscription", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, fmt.Sprintf("/subscriptions/%d/restore", created.ID), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	resp, err = st.HTTPClient.DELETE(ctx, fmt.Sprintf("/subscriptions/%d", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	t.Run("deleted subscription is hidden", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d", created.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=01-2024&user_id=%s", userID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cost handler.TotalCostResponse
+		require.NoError(t, resp.JSON(&cost))
+		assert.Equal(t, handler.NewTotalCostResponse(0, 0), cost)
+
+		resp, err = st.HTTPClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		assert.Equal(t, int64(0), list.Total)
+	})
+
+	t.Run("include_deleted", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions?include_deleted=true")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		require.Len(t, list.Subscriptions, 1)
+		assert.NotNil(t, list.Subscriptions[0].DeletedAt)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, fmt.Sprintf("/subscriptions/%d/restore", created.ID), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var restored handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&restored))
+		assert.Nil(t, restored.DeletedAt)
+
+		resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d", created.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("restore unknown subscription", func(t *testing.T) {
+		resp, err := st.HTTPClient.POST(ctx, "/subscriptions/999999/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestSubscriptionAuditLog(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	alice := domain.Principal{Subject: "alice", Kind: domain.PrincipalUser, Role: domain.RoleAdmin}
+	client := st.ClientAs(alice).WithHeader("X-Request-ID", "req-audit-1")
+
+	resp, err := client.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "req-audit-1", resp.Headers.Get("X-Request-ID"))
+
+	var created handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&created))
+
+	resp, err = st.HTTPClient.PATCH(ctx, fmt.Sprintf("/subscriptions/%d", created.ID), map[string]any{
+		"price": 1200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	generatedRequestID := resp.Headers.Get("X-Request-ID")
+	assert.NotEmpty(t, generatedRequestID)
+
+	resp, err = client.DELETE(ctx, fmt.Sprintf("/subscriptions/%d", created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	t.Run("history", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d/history", created.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var history handler.ListSubscriptionEventsResponse
+		require.NoError(t, resp.JSON(&history))
+		require.Len(t, history.Events, 3)
+
+		assert.Equal(t, "deleted", history.Events[0].Action)
+		assert.Equal(t, "updated", history.Events[1].Action)
+		assert.Equal(t, "created", history.Events[2].Action)
+
+		assert.Equal(t, "alice", *history.Events[2].Actor)
+		assert.Equal(t, "req-audit-1", *history.Events[2].RequestID)
+		assert.Nil(t, history.Events[2].Before)
+
+		assert.Equal(t, suite.TestActor, *history.Events[1].Actor)
+		assert.Equal(t, generatedRequestID, *history.Events[1].RequestID)
+
+		var before, after map[string]any
+		require.NoError(t, json.Unmarshal(history.Events[1].Before, &before))
+		require.NoError(t, json.Unmarshal(history.Events[1].After, &after))
+		assert.Equal(t, float64(1000), before["price"])
+		assert.Equal(t, float64(1200), after["price"])
+	})
+
+	t.Run("history of unknown subscription", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/999999/history")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("audit filters", func(t *testing.T) {
+		tests := []struct {
+			query string
+			count int
+		}{
+			{"", 3},
+			{"?actor=alice", 2},
+			{"?action=updated", 1},
+			{"?request_id=req-audit-1", 2},
+			{fmt.Sprintf("?subscription_id=%d", created.ID), 3},
+			{"?subscription_id=999999", 0},
+			{"?from=2000-01-01T00:00:00Z&to=2000-02-01T00:00:00Z", 0},
+			{"?limit=1", 1},
+		}
+		for _, tt := range tests {
+			resp, err := st.HTTPClient.GET(ctx, "/audit"+tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(t, http.StatusOK, resp.StatusCode, tt.query)
+
+			var events handler.ListSubscriptionEventsResponse
+			require.NoError(t, resp.JSON(&events))
+			assert.Len(t, events.Events, tt.count, tt.query)
+		}
+	})
+
+	t.Run("invalid audit filters", func(t *testing.T) {
+		for _, query := range []string{
+			"?action=archived",
+			"?subscription_id=abc",
+			"?from=2024-01-01",
+			"?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+		} {
+			resp, err := st.HTTPClient.GET(ctx, "/audit"+query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+}
+
+func TestSubscriptionETag(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `"1"`, resp.Headers.Get("ETag"))
+
+	var created handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&created))
+	assert.Equal(t, int32(1), created.Version)
+	path := fmt.Sprintf("/subscriptions/%d", created.ID)
+
+	t.Run("get returns etag", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"1"`, resp.Headers.Get("ETag"))
+	})
+
+	t.Run("if-none-match", func(t *testing.T) {
+		resp, err := st.HTTPClient.WithHeader("If-None-Match", `"1"`).GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Empty(t, resp.Body)
+		assert.Equal(t, `"1"`, resp.Headers.Get("ETag"))
+
+		resp, err = st.HTTPClient.WithHeader("If-None-Match", `"7", W/"1"`).GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		resp, err = st.HTTPClient.WithHeader("If-None-Match", `"7"`).GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("patch with matching if-match", func(t *testing.T) {
+		resp, err := st.HTTPClient.WithHeader("If-Match", `"1"`).PATCH(ctx, path, map[string]any{"price": 1100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"2"`, resp.Headers.Get("ETag"))
+	})
+
+	t.Run("patch with stale if-match", func(t *testing.T) {
+		resp, err := st.HTTPClient.WithHeader("If-Match", `"1"`).PATCH(ctx, path, map[string]any{"price": 1200})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+		resp, err = st.HTTPClient.GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var current handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&current))
+		assert.Equal(t, int32(1100), current.Price)
+	})
+
+	t.Run("invalid if-match", func(t *testing.T) {
+		for _, value := range []string{`W/"2"`, `2`, `"2", "3"`} {
+			resp, err := st.HTTPClient.WithHeader("If-Match", value).PATCH(ctx, path, map[string]any{"price": 1200})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, value)
+		}
+	})
+
+	t.Run("delete with stale if-match", func(t *testing.T) {
+		resp, err := st.HTTPClient.WithHeader("If-Match", `"1"`).DELETE(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("delete with wildcard if-match", func(t *testing.T) {
+		resp, err := st.HTTPClient.WithHeader("If-Match", "*").DELETE(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	body := map[string]any{
+		"service_name": "Netflix",
+		"price":        1000,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	}
+	client := st.HTTPClient.WithHeader("Idempotency-Key", uuid.NewString())
+
+	resp, err := client.POST(ctx, "/subscriptions", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&created))
+
+	t.Run("retry replays first response", func(t *testing.T) {
+		resp, err := client.POST(ctx, "/subscriptions", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "true", resp.Headers.Get("Idempotent-Replayed"))
+
+		var replayed handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&replayed))
+		assert.Equal(t, created, replayed)
+
+		resp, err = st.HTTPClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		assert.Equal(t, int64(1), list.Total)
+	})
+
+	t.Run("same key with different body", func(t *testing.T) {
+		resp, err := client.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Spotify",
+			"price":        300,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("error responses are replayed", func(t *testing.T) {
+		client := st.HTTPClient.WithHeader("Idempotency-Key", uuid.NewString())
+		invalid := map[string]any{"service_name": "Netflix", "price": -1}
+
+		for range 2 {
+			resp, err := client.POST(ctx, "/subscriptions", invalid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+
+	t.Run("status change", func(t *testing.T) {
+		client := st.HTTPClient.WithHeader("Idempotency-Key", uuid.NewString())
+		path := fmt.Sprintf("/subscriptions/%d/pause", created.ID)
+
+		for range 2 {
+			resp, err := client.POST(ctx, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("body over limit", func(t *testing.T) {
+		client := st.HTTPClient.WithHeader("Idempotency-Key", uuid.NewString())
+		large := bytes.Repeat([]byte(" "), int(st.Config.Idempotency.MaxBodyBytes)+1)
+
+		resp, err := client.POSTRaw(ctx, "/subscriptions", "application/json", large)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("import is hashed while streaming", func(t *testing.T) {
+		client := st.HTTPClient.WithHeader("Idempotency-Key", uuid.NewString())
+		csvBody := "service_name,price,user_id,start_date\nNetflix,800," + uuid.NewString() + ",01-2024\n"
+
+		resp, err := client.POSTRaw(ctx, "/subscriptions/import", "text/csv", []byte(csvBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var first handler.ImportSubscriptionsResponse
+		require.NoError(t, resp.JSON(&first))
+
+		resp, err = client.POSTRaw(ctx, "/subscriptions/import", "text/csv", []byte(csvBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "true", resp.Headers.Get("Idempotent-Replayed"))
+		var replayed handler.ImportSubscriptionsResponse
+		require.NoError(t, resp.JSON(&replayed))
+		assert.Equal(t, first, replayed)
+
+		resp, err = client.POSTRaw(ctx, "/subscriptions/import", "text/csv", []byte(csvBody+"Spotify,300,"+uuid.NewString()+",01-2024\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+}
+
+func TestAuthentication(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		resp, err := st.AnonymousClient.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "Bearer", resp.Headers.Get("WWW-Authenticate"))
+
+		var errResp ErrorResponse
+		require.NoError(t, resp.JSON(&errResp))
+		assert.Equal(t, handler.ErrMissingToken, errResp.Error)
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		principal := domain.Principal{Subject: "mallory", Kind: domain.PrincipalUser}
+
+		otherSecret, err := auth.NewTokenManager("another-secret-that-is-at-least-32-bytes", time.Hour, nil)
+		require.NoError(t, err)
+		forged, err := otherSecret.Issue(principal)
+		require.NoError(t, err)
+
+		expiredTokens, err := auth.NewTokenManager(st.Config.App.AppSecretKey, -time.Minute, nil)
+		require.NoError(t, err)
+		expired, err := expiredTokens.Issue(principal)
+		require.NoError(t, err)
+
+		for name, header := range map[string]string{
+			"garbage":        "Bearer not-a-jwt",
+			"wrong scheme":   "Basic " + forged.AccessToken,
+			"forged":         "Bearer " + forged.AccessToken,
+			"expired":        "Bearer " + expired.AccessToken,
+			"empty bearer":   "Bearer ",
+			"missing scheme": forged.AccessToken,
+		} {
+			resp, err := st.AnonymousClient.WithHeader("Authorization", header).GET(ctx, "/subscriptions")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, name)
+		}
+	})
+
+	t.Run("swagger is public", func(t *testing.T) {
+		resp, err := st.AnonymousClient.GET(ctx, "/swagger/doc.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		resp, err := st.AnonymousClient.POST(ctx, "/auth/token", map[string]any{
+			"client_id":     "unknown",
+			"client_secret": "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, err = st.AnonymousClient.POST(ctx, "/auth/token", map[string]any{
+			"client_id": "unknown",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("service account token", func(t *testing.T) {
+		if len(st.Config.Auth.ServiceAccounts) == 0 {
+			t.Skip("AUTH_SERVICE_ACCOUNTS is not configured")
+		}
+
+		var clientID, clientSecret string
+		for clientID, clientSecret = range st.Config.Auth.ServiceAccounts {
+			break
+		}
+		// Без роли аккаунт получает user и не может создавать подписки других пользователей
+		if st.Config.Auth.ServiceAccountRoles[clientID] != string(domain.RoleAdmin) {
+			t.Skip("service account is not configured as admin in AUTH_SERVICE_ACCOUNT_ROLES")
+		}
+
+		resp, err := st.AnonymousClient.POST(ctx, "/auth/token", map[string]any{
+			"client_id":     clientID,
+			"client_secret": clientSecret,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var token handler.TokenResponse
+		require.NoError(t, resp.JSON(&token))
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Positive(t, token.ExpiresIn)
+
+		client := st.AnonymousClient.WithHeader("Authorization", "Bearer "+token.AccessToken)
+		resp, err = client.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        1000,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var created handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&created))
+
+		resp, err = client.GET(ctx, fmt.Sprintf("/subscriptions/%d/history", created.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var history handler.ListSubscriptionEventsResponse
+		require.NoError(t, resp.JSON(&history))
+		require.Len(t, history.Events, 1)
+		assert.Equal(t, clientID, *history.Events[0].Actor)
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	userA, userB := uuid.New(), uuid.New()
+	clientA := st.ClientAs(domain.Principal{Subject: userA.String(), Kind: domain.PrincipalUser, Role: domain.RoleUser})
+	finance := st.ClientAs(domain.Principal{Subject: "accounting", Kind: domain.PrincipalUser, Role: domain.RoleFinance})
+
+	create := func(client *suite.Client, userID uuid.UUID, price int) *suite.Response {
+		resp, err := client.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        price,
+			"user_id":      userID.String(),
+			"start_date":   "01-2024",
+			"end_date":     "12-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := create(st.HTTPClient, userA, 100)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var subA handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&subA))
+
+	resp = create(st.HTTPClient, userB, 1000)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var subB handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&subB))
+
+	t.Run("user reaches own subscriptions", func(t *testing.T) {
+		for _, path := range []string{
+			fmt.Sprintf("/subscriptions/%d", subA.ID),
+			fmt.Sprintf("/subscriptions/%d/history", subA.ID),
+			fmt.Sprintf("/users/%s/subscriptions", userA),
+		} {
+			resp, err := clientA.GET(ctx, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		}
+
+		resp, err := clientA.PATCH(ctx, fmt.Sprintf("/subscriptions/%d", subA.ID), map[string]any{"price": 150})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("user does not see other subscriptions", func(t *testing.T) {
+		path := fmt.Sprintf("/subscriptions/%d", subB.ID)
+
+		resp, err := clientA.GET(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = clientA.PATCH(ctx, path, map[string]any{"price": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = clientA.DELETE(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = clientA.POST(ctx, path+"/cancel", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = clientA.GET(ctx, fmt.Sprintf("/users/%s/subscriptions", userB))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = create(clientA, userB, 1)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("lists and costs are scoped to user", func(t *testing.T) {
+		resp, err := clientA.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		require.Len(t, list.Subscriptions, 1)
+		assert.Equal(t, subA.ID, list.Subscriptions[0].ID)
+
+		resp, err = clientA.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=12-2024")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var cost handler.TotalCostResponse
+		require.NoError(t, resp.JSON(&cost))
+		assert.Equal(t, int64(1), cost.Count)
+
+		resp, err = clientA.GET(ctx, fmt.Sprintf("/subscriptions/cost?start_period=01-2024&end_period=12-2024&user_id=%s", userB))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("finance reads everything and changes nothing", func(t *testing.T) {
+		resp, err := finance.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=12-2024")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var cost handler.TotalCostResponse
+		require.NoError(t, resp.JSON(&cost))
+		assert.Equal(t, int64(2), cost.Count)
+
+		for _, path := range []string{
+			fmt.Sprintf("/subscriptions/%d", subB.ID),
+			fmt.Sprintf("/users/%s/subscriptions", userB),
+			"/audit",
+		} {
+			resp, err := finance.GET(ctx, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		}
+
+		resp, err = finance.PATCH(ctx, fmt.Sprintf("/subscriptions/%d", subB.ID), map[string]any{"price": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = create(finance, userB, 1)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("admin only operations", func(t *testing.T) {
+		resp, err := clientA.GET(ctx, "/audit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err = clientA.POST(ctx, "/services", map[string]any{"name": "Spotify"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err = clientA.GET(ctx, "/services")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("user token requires user id subject", func(t *testing.T) {
+		client := st.ClientAs(domain.Principal{Subject: "bob", Kind: domain.PrincipalUser, Role: domain.RoleUser})
+		resp, err := client.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	createKey := func(client *suite.Client, body map[string]any) *suite.Response {
+		resp, err := client.POST(ctx, "/api-keys", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	keyClient := func(key string) *suite.Client {
+		return st.AnonymousClient.WithHeader("Authorization", "ApiKey "+key)
+	}
+
+	resp := createKey(st.HTTPClient, map[string]any{
+		"name":   "billing-export",
+		"scopes": []string{"subscriptions:read", "subscriptions:write", "users:all"},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Headers.Get("Cache-Control"))
+
+	var created handler.CreateAPIKeyResponse
+	require.NoError(t, resp.JSON(&created))
+	require.NotEmpty(t, created.Key)
+	assert.Equal(t, created.Key[:len(created.Prefix)], created.Prefix)
+	assert.Equal(t, suite.TestActor, created.CreatedBy)
+	assert.Nil(t, created.LastUsedAt)
+
+	billing := keyClient(created.Key)
+
+	// Ключ без catalog:write не добавляет сервисы в каталог
+	resp, err := st.HTTPClient.POST(ctx, "/services", map[string]any{"name": "Netflix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	t.Run("key authenticates requests", func(t *testing.T) {
+		resp, err := billing.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        1000,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var sub handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&sub))
+
+		resp, err = billing.GET(ctx, fmt.Sprintf("/subscriptions/%d/history", sub.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var history handler.ListSubscriptionEventsResponse
+		require.NoError(t, resp.JSON(&history))
+		require.Len(t, history.Events, 1)
+		assert.Equal(t, fmt.Sprintf("api_key:%d", created.ID), *history.Events[0].Actor)
+	})
+
+	t.Run("key is limited to its scopes", func(t *testing.T) {
+		resp, err := billing.GET(ctx, "/audit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err = billing.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Unknown Streaming",
+			"price":        500,
+			"user_id":      uuid.New().String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		resp, err = st.HTTPClient.GET(ctx, "/services?limit=100")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotContains(t, resp.String(), "Unknown Streaming")
+
+		resp, err = billing.GET(ctx, "/api-keys")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("user scoped key", func(t *testing.T) {
+		userID := uuid.New()
+		resp := createKey(st.HTTPClient, map[string]any{
+			"name":    "user-script",
+			"scopes":  []string{"subscriptions:read"},
+			"user_id": userID.String(),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var key handler.CreateAPIKeyResponse
+		require.NoError(t, resp.JSON(&key))
+		client := keyClient(key.Key)
+
+		resp, err := client.GET(ctx, fmt.Sprintf("/users/%s/subscriptions", userID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = client.GET(ctx, fmt.Sprintf("/users/%s/subscriptions", uuid.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("list shows usage without keys", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/api-keys")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, resp.String(), created.Key)
+
+		var list handler.ListAPIKeysResponse
+		require.NoError(t, resp.JSON(&list))
+		require.Len(t, list.APIKeys, 2)
+		assert.Equal(t, "billing-export", list.APIKeys[1].Name)
+		assert.NotNil(t, list.APIKeys[1].LastUsedAt)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"scopes": []string{"subscriptions:read", "users:all"}},
+			{"name": "no-scopes"},
+			{"name": "unknown-scope", "scopes": []string{"everything"}},
+			{"name": "no-user", "scopes": []string{"subscriptions:read"}},
+			{"name": "expired", "scopes": []string{"audit:read"}, "expires_at": "2020-01-01T00:00:00Z"},
+			{"name": "bad-expiry", "scopes": []string{"audit:read"}, "expires_at": "tomorrow"},
+		} {
+			resp := createKey(st.HTTPClient, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body["name"])
+		}
+
+		user := st.ClientAs(domain.Principal{Subject: uuid.New().String(), Kind: domain.PrincipalUser, Role: domain.RoleUser})
+		resp := createKey(user, map[string]any{"name": "mine", "scopes": []string{"subscriptions:read", "users:all"}})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err := keyClient("em_unknown").GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		path := fmt.Sprintf("/api-keys/%d", created.ID)
+
+		resp, err := st.HTTPClient.DELETE(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, err = billing.GET(ctx, "/subscriptions")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		var errResp ErrorResponse
+		require.NoError(t, resp.JSON(&errResp))
+		assert.Equal(t, handler.ErrInvalidAPIKey, errResp.Error)
+
+		resp, err = st.HTTPClient.DELETE(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("catalog reads require subscriptions read scope", func(t *testing.T) {
+		resp := createKey(st.HTTPClient, map[string]any{"name": "auditor", "scopes": []string{"audit:read"}})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var key handler.CreateAPIKeyResponse
+		require.NoError(t, resp.JSON(&key))
+		client := keyClient(key.Key)
+
+		for _, path := range []string{"/services", "/services/1", "/exchange-rates", "/exchange-rates/1"} {
+			resp, err := client.GET(ctx, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode, path)
+		}
+
+		resp, err := client.GET(ctx, "/audit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestOrganizations(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	orgID := st.CreateOrganization("e2e-marketing")
+	marketing := st.ClientAs(domain.Principal{
+		Subject:        "e2e-marketing-admin",
+		Kind:           domain.PrincipalService,
+		Role:           domain.RoleAdmin,
+		OrganizationID: orgID,
+	})
+
+	userID := uuid.New()
+	create := func(client *suite.Client, price int) handler.SubscriptionResponse {
+		resp, err := client.POST(ctx, "/subscriptions", map[string]any{
+			"service_name": "Netflix",
+			"price":        price,
+			"user_id":      userID.String(),
+			"start_date":   "01-2024",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var sub handler.SubscriptionResponse
+		require.NoError(t, resp.JSON(&sub))
+		return sub
+	}
+
+	own := create(st.HTTPClient, 1000)
+	other := create(marketing, 300)
+
+	t.Run("foreign subscriptions are invisible", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d", other.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = marketing.PATCH(ctx, fmt.Sprintf("/subscriptions/%d", own.ID), map[string]any{"price": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = marketing.GET(ctx, fmt.Sprintf("/users/%s/subscriptions", userID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var list handler.ListSubscriptionsResponse
+		require.NoError(t, resp.JSON(&list))
+		require.Len(t, list.Subscriptions, 1)
+		assert.Equal(t, other.ID, list.Subscriptions[0].ID)
+	})
+
+	t.Run("cost is per organization", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/subscriptions/cost?start_period=01-2024&end_period=01-2024")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var total handler.TotalCostResponse
+		require.NoError(t, resp.JSON(&total))
+		assert.Equal(t, int64(1000), total.TotalCost)
+
+		resp, err = marketing.GET(ctx, "/organization/cost?start_period=01-2024&end_period=03-2024&mode=prorated")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var cost handler.OrganizationCostResponse
+		require.NoError(t, resp.JSON(&cost))
+		assert.Equal(t, orgID, cost.OrganizationID)
+		assert.Equal(t, "e2e-marketing", cost.OrganizationName)
+		assert.Equal(t, int64(900), cost.TotalCost)
+		assert.Equal(t, int64(1), cost.Count)
+		assert.Equal(t, int64(1), cost.UserCount)
+	})
+
+	t.Run("organization cost requires access to all users", func(t *testing.T) {
+		user := st.ClientAs(domain.Principal{Subject: userID.String(), Kind: domain.PrincipalUser, Role: domain.RoleUser})
+		resp, err := user.GET(ctx, "/organization/cost?start_period=01-2024&end_period=03-2024")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("service catalog is per organization", func(t *testing.T) {
+		assert.NotEqual(t, own.ServiceID, other.ServiceID)
+
+		resp, err := marketing.GET(ctx, fmt.Sprintf("/services/%d", own.ServiceID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = marketing.PATCH(ctx, fmt.Sprintf("/services/%d", own.ServiceID), map[string]any{"name": "Netflix Premium"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = marketing.GET(ctx, "/services")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var list handler.ListServicesResponse
+		require.NoError(t, resp.JSON(&list))
+		require.Len(t, list.Services, 1)
+		assert.Equal(t, other.ServiceID, list.Services[0].ID)
+	})
+
+	t.Run("audit log is per organization", func(t *testing.T) {
+		resp, err := marketing.GET(ctx, "/audit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var audit handler.ListSubscriptionEventsResponse
+		require.NoError(t, resp.JSON(&audit))
+		require.Len(t, audit.Events, 1)
+		assert.Equal(t, other.ID, audit.Events[0].SubscriptionID)
+	})
+
+	t.Run("idempotency keys are per organization", func(t *testing.T) {
+		// Тот же субъект в двух организациях с одним ключом не получает чужой ответ
+		key := uuid.NewString()
+		sameActor := st.ClientAs(domain.Principal{
+			Subject:        suite.TestActor,
+			Kind:           domain.PrincipalService,
+			Role:           domain.RoleAdmin,
+			OrganizationID: orgID,
+		})
+
+		for _, client := range []*suite.Client{st.HTTPClient, sameActor} {
+			resp, err := client.WithHeader("Idempotency-Key", key).POST(ctx, "/subscriptions", map[string]any{
+				"service_name": "Netflix",
+				"price":        100,
+				"user_id":      uuid.NewString(),
+				"start_date":   "01-2024",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.Empty(t, resp.Headers.Get("Idempotent-Replayed"))
+		}
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	ctx, st := suite.New(t)
+	t.Cleanup(func() {
+		st.CleanupTestData()
+	})
+
+	resp, err := st.HTTPClient.POST(ctx, "/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        500,
+		"user_id":      uuid.New().String(),
+		"start_date":   "01-2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var sub handler.SubscriptionResponse
+	require.NoError(t, resp.JSON(&sub))
+
+	resp, err = st.HTTPClient.GET(ctx, fmt.Sprintf("/subscriptions/%d", sub.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	t.Run("admin listener serves metrics", func(t *testing.T) {
+		resp, err := st.AdminClient.GET(ctx, "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body := resp.String()
+		assert.Contains(t, body, `http_requests_total{route="GET /subscriptions/{id}",status="200"}`)
+		assert.Contains(t, body, `http_request_duration_seconds_bucket{route="POST /subscriptions",status="201"`)
+		assert.Contains(t, body, `business_operation_duration_seconds_count{op="business.GetSubscriptionByID"}`)
+		assert.Contains(t, body, "pgxpool_acquired_connections")
+		assert.Contains(t, body, "pgxpool_empty_acquire_wait_seconds_total")
+		assert.Contains(t, body, `subscriptions{status="active"}`)
+	})
+
+	t.Run("api listener does not serve metrics", func(t *testing.T) {
+		resp, err := st.HTTPClient.GET(ctx, "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
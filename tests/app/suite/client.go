@@ -28,6 +28,7 @@ func (r *Response) String() string {
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	headers    http.Header
 }
 
 func NewClient(baseURL string, httpClient *http.Client) *Client {
@@ -40,6 +41,17 @@ func NewClient(baseURL string, httpClient *http.Client) *Client {
 	}
 }
 
+// WithHeader возвращает копию клиента, добавляющую заголовок ко всем запросам
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := *c
+	clone.headers = c.headers.Clone()
+	if clone.headers == nil {
+		clone.headers = http.Header{}
+	}
+	clone.headers.Set(key, value)
+	return &clone
+}
+
 // GET запрос
 func (c *Client) GET(ctx context.Context, path string) (*Response, error) {
 	return c.do(ctx, http.MethodGet, path, nil)
@@ -59,23 +71,34 @@ func (c *Client) PATCH(ctx context.Context, path string, body any) (*Response, e
 	return c.do(ctx, http.MethodPatch, path, body)
 }
 
+// POSTRaw запрос с произвольным body и Content-Type
+func (c *Client) POSTRaw(ctx context.Context, path, contentType string, body []byte) (*Response, error) {
+	return c.send(ctx, http.MethodPost, path, contentType, bytes.NewReader(body))
+}
+
 func (c *Client) do(ctx context.Context, method, path string, body any) (*Response, error) {
-	var bodyReader io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("marshal body: %w", err)
-		}
-		bodyReader = bytes.NewReader(data)
+	if body == nil {
+		return c.send(ctx, method, path, "", nil)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal body: %w", err)
+	}
+	return c.send(ctx, method, path, "application/json", bytes.NewReader(data))
+}
+
+func (c *Client) send(ctx context.Context, method, path, contentType string, body io.Reader) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	resp, err := c.httpClient.Do(req)
@@ -7,19 +7,30 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Krokozabra213/effective_mobile/internal/auth"
 	"github.com/Krokozabra213/effective_mobile/internal/config"
+	"github.com/Krokozabra213/effective_mobile/internal/domain"
 	pgxclient "github.com/Krokozabra213/effective_mobile/pkg/pgx-client"
+	"github.com/google/uuid"
 )
 
 const (
 	ctxTimeout = 30 * time.Second
+
+	// TestActor сервисный аккаунт с ролью admin, от имени которого тесты обращаются к API
+	TestActor = "e2e-tests"
 )
 
 type APISuite struct {
 	*testing.T
-	Config     *config.Config
-	DB         *pgxclient.Client
-	HTTPClient *Client
+	Config *config.Config
+	DB     *pgxclient.Client
+	// HTTPClient аутентифицирован как TestActor, AnonymousClient — без токена
+	HTTPClient      *Client
+	AnonymousClient *Client
+	// AdminClient обращается к отдельному адресу с метриками
+	AdminClient *Client
+	Tokens      *auth.TokenManager
 }
 
 func New(t *testing.T) (context.Context, *APISuite) {
@@ -51,18 +62,54 @@ func New(t *testing.T) (context.Context, *APISuite) {
 		db.Close()
 	})
 
+	tokens, err := auth.NewTokenManager(cfg.App.AppSecretKey, cfg.Auth.TokenTTL, nil)
+	if err != nil {
+		t.Fatalf("token manager init err: %v", err)
+	}
+
 	httpAddress := fmt.Sprintf("http://%s:%s", cfg.HTTP.Host, cfg.HTTP.Port)
 	client := NewClient(httpAddress, nil)
+	adminAddress := fmt.Sprintf("http://%s:%s", cfg.HTTP.AdminHost, cfg.HTTP.AdminPort)
 
-	return ctx, &APISuite{
-		T:          t,
-		Config:     cfg,
-		DB:         db,
-		HTTPClient: client,
+	st := &APISuite{
+		T:               t,
+		Config:          cfg,
+		DB:              db,
+		AnonymousClient: client,
+		AdminClient:     NewClient(adminAddress, nil),
+		Tokens:          tokens,
+	}
+	st.HTTPClient = st.ClientAs(domain.Principal{Subject: TestActor, Kind: domain.PrincipalService, Role: domain.RoleAdmin})
+
+	return ctx, st
+}
+
+// ClientAs возвращает клиент с токеном, выданным principal
+func (s *APISuite) ClientAs(principal domain.Principal) *Client {
+	s.Helper()
+	token, err := s.Tokens.Issue(principal)
+	if err != nil {
+		s.Fatalf("issue token err: %v", err)
+	}
+	return s.AnonymousClient.WithHeader("Authorization", "Bearer "+token.AccessToken)
+}
+
+// CreateOrganization возвращает ID организации name, создавая её при необходимости
+func (s *APISuite) CreateOrganization(name string) uuid.UUID {
+	s.Helper()
+	var id uuid.UUID
+	err := s.DB.QueryRow(context.Background(), `
+		INSERT INTO organizations (id, name) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, uuid.New(), name).Scan(&id)
+	if err != nil {
+		s.Fatalf("create organization err: %v", err)
 	}
+	return id
 }
 
 func (s *APISuite) CleanupTestData() error {
-	_, err := s.DB.Exec(context.Background(), "TRUNCATE TABLE subscriptions RESTART IDENTITY CASCADE")
+	_, err := s.DB.Exec(context.Background(), "TRUNCATE TABLE subscriptions, subscription_events, idempotency_keys, api_keys, exchange_rates, services RESTART IDENTITY CASCADE")
 	return err
 }